@@ -2,19 +2,40 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"os"
+	_ "time/tzdata"
 
 	"github.com/VladKovDev/promo-bot/internal/app"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print the effective config with secrets redacted and exit")
+	resealTokens := flag.Bool("reseal-tokens", false, "re-encrypt stored bot tokens bound to their rows with the current key and exit")
+	flag.Parse()
+
 	ctx := context.Background()
 
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
 	}
 
+	if *printConfig {
+		if err := app.PrintConfig(ctx, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *resealTokens {
+		if err := app.ResealTokens(ctx); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if err := app.Run(ctx); err != nil {
 		log.Fatal(err)
 	}
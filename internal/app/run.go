@@ -2,17 +2,34 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/VladKovDev/promo-bot/internal/config"
+	"github.com/VladKovDev/promo-bot/internal/delivery/http/handler"
+	"github.com/VladKovDev/promo-bot/internal/domain/alert"
+	"github.com/VladKovDev/promo-bot/internal/domain/bundle"
+	"github.com/VladKovDev/promo-bot/internal/domain/group"
+	"github.com/VladKovDev/promo-bot/internal/domain/inbox"
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
 	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
 	"github.com/VladKovDev/promo-bot/internal/domain/user"
 	"github.com/VladKovDev/promo-bot/internal/infrastructure/crypto"
 	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/storage"
 	"github.com/VladKovDev/promo-bot/internal/infrastructure/telegram"
 	"github.com/VladKovDev/promo-bot/internal/registry"
+	"github.com/VladKovDev/promo-bot/internal/worker"
 	"github.com/VladKovDev/promo-bot/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -22,10 +39,40 @@ type App struct {
 	Logger              logger.Logger
 	DB                  *postgres.Pool
 	KeyStore            *crypto.KeyStore
+	Envelope            *crypto.Envelope
 	UserRepo            user.Repository
+	UserAttributeRepo   user.AttributeRepository
+	UserService         *user.Service
 	TelegramBotRepo     telegram_bot.Repository
 	TelegramBotService  *telegram_bot.Service
 	TelegramBotRegistry *registry.TelegramBotRegistry
+	MessageRepo         message.Repository
+	MessageService      *message.Service
+	ScriptRepo          script.Repository
+	ScriptDeepLinkRepo  script.DeepLinkRepository
+	ScriptVersionRepo   script.VersionRepository
+	ScriptProgressRepo  script.ProgressRepository
+	ScheduledStepRepo   script.ScheduleRepository
+	ScriptService       *script.Service
+	PrivateGroupRepo    group.Repository
+	GroupService        *group.Service
+	ScriptBundleRepo    bundle.Repository
+	BundleService       *bundle.Service
+	AlertService        *alert.Service
+	InboxService        *inbox.Service
+	Deliveries          *alert.Deliveries
+
+	// runCtx is the context bot handlers run on, so bots started from a
+	// request or an update outlive it. It is set by InitBots.
+	runCtx     context.Context
+	handlersMu sync.Mutex
+	handlers   map[uuid.UUID]runningBot
+}
+
+// runningBot is a bot whose updates are being received.
+type runningBot struct {
+	telegramID int64
+	cancel     context.CancelFunc
 }
 
 // NewApp constructs the application object and initializes repositories.
@@ -33,20 +80,78 @@ func NewApp(cfg *config.Config,
 	pool *postgres.Pool,
 	logger logger.Logger,
 	keyStore *crypto.KeyStore,
+	envelope *crypto.Envelope,
 	telegramBotRegistry *registry.TelegramBotRegistry) *App {
 
-	var userRepo user.Repository
+	var (
+		userRepo          user.Repository
+		userAttributeRepo user.AttributeRepository
+	)
 	if pool != nil && pool.Pool != nil {
 		userRepo = postgres.NewPostgresUserRepository(pool.Pool)
+		userAttributeRepo = postgres.NewPostgresUserAttributeRepository(pool.Pool)
 	}
 	var telegramBotRepo telegram_bot.Repository
 	if pool != nil && pool.Pool != nil {
-		telegramBotRepo = postgres.NewPostgresTelegramBotRepository(pool.Pool, keyStore)
+		telegramBotRepo = postgres.NewPostgresTelegramBotRepository(pool.Pool, keyStore, envelope, cfg.Crypto.AllowUnboundTokens, logger)
+	}
+	var (
+		messageRepo        message.Repository
+		scriptRepo         script.Repository
+		scriptDeepLinkRepo script.DeepLinkRepository
+		scriptVersionRepo  script.VersionRepository
+		scriptProgressRepo script.ProgressRepository
+		scheduledStepRepo  script.ScheduleRepository
+		privateGroupRepo   group.Repository
+		scriptBundleRepo   bundle.Repository
+		alertRepo          alert.Repository
+		inboxRepo          inbox.Repository
+	)
+	if pool != nil && pool.Pool != nil {
+		messageRepo = postgres.NewPostgresMessageRepository(pool.Pool)
+		scriptRepo = postgres.NewPostgresScriptRepository(pool.Pool)
+		scriptDeepLinkRepo = postgres.NewPostgresScriptDeepLinkRepository(pool.Pool)
+		scriptVersionRepo = postgres.NewPostgresScriptVersionRepository(pool.Pool)
+		scriptProgressRepo = postgres.NewPostgresScriptProgressRepository(pool.Pool)
+		scheduledStepRepo = postgres.NewPostgresScheduledStepRepository(pool.Pool)
+		privateGroupRepo = postgres.NewPostgresPrivateGroupRepository(pool.Pool)
+		scriptBundleRepo = postgres.NewPostgresScriptBundleRepository(pool.Pool)
+		alertRepo = postgres.NewPostgresTelegramBotAlertRepository(pool.Pool)
+		inboxRepo = postgres.NewPostgresInboxRepository(pool.Pool)
+	}
+	var redirects *message.Redirects
+	if cfg.HTTP.PublicURL != "" {
+		redirects = message.NewRedirects(cfg.HTTP.PublicURL, cfg.HTTP.RedirectSecret)
+	}
+	mediaStorage := storage.NewLocal(cfg.Media.Dir)
+	var messageService *message.Service
+	if messageRepo != nil {
+		messageService = message.NewService(messageRepo, mediaStorage, redirects)
+	}
+	var bundleService *bundle.Service
+	if scriptBundleRepo != nil {
+		bundleService = bundle.NewService(scriptBundleRepo, scriptRepo, scriptVersionRepo, messageRepo, mediaStorage)
+	}
+	var userService *user.Service
+	if userRepo != nil {
+		userService = user.NewService(userRepo, userAttributeRepo)
 	}
 	var telegramBotService *telegram_bot.Service
+	var scriptService *script.Service
+	var groupService *group.Service
+	var alertService *alert.Service
+	var inboxService *inbox.Service
+	deliveries := alert.NewDeliveries(cfg.Alerts.FailureWindow)
 	if telegramBotRepo != nil && telegramBotRegistry != nil {
 		botSender := telegram.NewSender(telegramBotRegistry)
 		telegramBotService = telegram_bot.NewService(telegramBotRepo, *botSender)
+		alertService = alert.NewService(alertRepo, botSender, cfg.Alerts.Cooldown, cfg.Alerts.MaxPerHour, logger)
+		chats := telegram.NewChats(telegramBotRegistry)
+		groupService = group.NewService(privateGroupRepo, userRepo, userAttributeRepo, chats, cfg.Groups.InviteTTL, logger)
+		inboxService = inbox.NewService(inboxRepo, botSender, chats, logger)
+		scriptService = script.NewService(scriptRepo, scriptDeepLinkRepo, scriptVersionRepo, scriptProgressRepo, scheduledStepRepo,
+			messageRepo, mediaStorage, redirects, telegramBotRepo, userRepo, userAttributeRepo, groupService, chats,
+			alert.TrackDeliveries(botSender, deliveries), logger)
 	}
 
 	return &App{
@@ -54,14 +159,36 @@ func NewApp(cfg *config.Config,
 		Logger:              logger,
 		DB:                  pool,
 		KeyStore:            keyStore,
+		Envelope:            envelope,
 		UserRepo:            userRepo,
+		UserAttributeRepo:   userAttributeRepo,
+		UserService:         userService,
 		TelegramBotRepo:     telegramBotRepo,
 		TelegramBotService:  telegramBotService,
 		TelegramBotRegistry: telegramBotRegistry,
+		MessageRepo:         messageRepo,
+		MessageService:      messageService,
+		ScriptRepo:          scriptRepo,
+		ScriptDeepLinkRepo:  scriptDeepLinkRepo,
+		ScriptVersionRepo:   scriptVersionRepo,
+		ScriptProgressRepo:  scriptProgressRepo,
+		ScheduledStepRepo:   scheduledStepRepo,
+		ScriptService:       scriptService,
+		PrivateGroupRepo:    privateGroupRepo,
+		GroupService:        groupService,
+		ScriptBundleRepo:    scriptBundleRepo,
+		BundleService:       bundleService,
+		AlertService:        alertService,
+		InboxService:        inboxService,
+		Deliveries:          deliveries,
+		handlers:            make(map[uuid.UUID]runningBot),
 	}
 }
 
 func Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	configPath := os.Getenv("PROMO_BOTS_CONFIG_PATH")
 	cfg, err := initConfig(configPath, ctx)
 	if err != nil {
@@ -84,17 +211,94 @@ func Run(ctx context.Context) error {
 		return fmt.Errorf("failed to init encryptor: %w", err)
 	}
 
+	if err := startKeyWatcher(ctx, cfg, keyStore, logger); err != nil {
+		return fmt.Errorf("failed to start key watcher: %w", err)
+	}
+
+	envelope, err := initEnvelope(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init key provider: %w", err)
+	}
+
 	telegram_bot_registry := registry.NewTelegramBotRegistry()
 
-	app := NewApp(cfg, pool, logger, keyStore, telegram_bot_registry)
+	app := NewApp(cfg, pool, logger, keyStore, envelope, telegram_bot_registry)
 
 	err = app.InitBots(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to init bots: %w", err)
 	}
 
-	gracefulShutdown(ctx, logger, pool)
+	scheduler := worker.NewScheduler(app.ScheduledStepRepo, app.ScriptService, cfg.Scheduler, logger)
+	go scheduler.Run(ctx)
+
+	inviteSweeper := worker.NewInviteSweeper(app.GroupService, cfg.Groups, logger)
+	go inviteSweeper.Run(ctx)
+
+	monitor := worker.NewMonitor(app.TelegramBotService, app, app.ScriptService, app.Deliveries, app.AlertService, cfg.Alerts, logger)
+	go monitor.Run(ctx)
+
+	app.startHTTPServer(ctx)
+
+	gracefulShutdown(ctx, cancel, logger, pool)
+
+	return nil
+}
+
+// PrintConfig loads the effective config and writes it to w with secrets redacted.
+func PrintConfig(ctx context.Context, w io.Writer) error {
+	configPath := os.Getenv("PROMO_BOTS_CONFIG_PATH")
+	cfg, err := initConfig(configPath, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to init config: %w", err)
+	}
+	return cfg.Print(w)
+}
+
+type tokenResealer interface {
+	ResealTokens(ctx context.Context) (int, error)
+}
+
+// ResealTokens binds stored bot tokens to their rows and re-encrypts them
+// with the current key version. It is safe to run repeatedly.
+func ResealTokens(ctx context.Context) error {
+	configPath := os.Getenv("PROMO_BOTS_CONFIG_PATH")
+	cfg, err := initConfig(configPath, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to init config: %w", err)
+	}
+
+	logger, err := initLogger(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init logger: %w", err)
+	}
+
+	pool, err := initPostgresDatabase(ctx, cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to init database: %w", err)
+	}
+	defer pool.Close()
+
+	keyStore, err := initEncryptor(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init encryptor: %w", err)
+	}
+
+	envelope, err := initEnvelope(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init key provider: %w", err)
+	}
+
+	repo, ok := postgres.NewPostgresTelegramBotRepository(pool.Pool, keyStore, envelope, cfg.Crypto.AllowUnboundTokens, logger).(tokenResealer)
+	if !ok {
+		return fmt.Errorf("telegram bot repository does not support resealing")
+	}
 
+	resealed, err := repo.ResealTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reseal tokens: %w", err)
+	}
+	logger.Info("telegram bot tokens resealed", zap.Int("count", resealed))
 	return nil
 }
 
@@ -115,10 +319,56 @@ func initPostgresDatabase(ctx context.Context, cfg *config.Config, logger logger
 }
 
 func initEncryptor(cfg *config.Config) (*crypto.KeyStore, error) {
-	return crypto.NewAESKeyStore(cfg.Crypto.CurrentVersion, cfg.Crypto.Keys)
+	return crypto.NewKeyStore(cfg.Crypto.Algorithm, cfg.Crypto.CurrentVersion, cfg.Crypto.Keys, cfg.Crypto.KeyAlgorithms)
+}
+
+// initEnvelope returns nil when no key provider is configured and tokens are
+// encrypted with versioned keys directly.
+func initEnvelope(cfg *config.Config) (*crypto.Envelope, error) {
+	provider := cfg.Crypto.KeyProvider
+	switch provider.Type {
+	case "":
+		return nil, nil
+	case "local":
+		kp, err := crypto.NewLocalKeyProvider(provider.LocalKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.NewEnvelope(kp, cfg.Crypto.Algorithm, provider.CacheTTL), nil
+	case "http":
+		kp := crypto.NewHTTPKeyProvider(provider.HTTPEndpoint, provider.HTTPKeyID, provider.HTTPToken, provider.HTTPTimeout)
+		return crypto.NewEnvelope(kp, cfg.Crypto.Algorithm, provider.CacheTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown key provider: %s", provider.Type)
+	}
+}
+
+// startKeyWatcher reloads encryption keys when key files change.
+// It is a no-op when keys come from environment variables only.
+func startKeyWatcher(ctx context.Context, cfg *config.Config, keyStore *crypto.KeyStore, logger logger.Logger) error {
+	source := cfg.Crypto.KeySource
+	if source == nil {
+		return nil
+	}
+	dirs := source.WatchPaths()
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	watcher, err := crypto.NewKeyWatcher(keyStore, source.Load, dirs, logger)
+	if err != nil {
+		return err
+	}
+	go watcher.Run(ctx)
+
+	logger.Info("watching encryption key files", zap.Strings("dirs", dirs))
+	return nil
 }
 
+// InitBots starts all active bots. Bots started now or later run until ctx
+// is done.
 func (a *App) InitBots(ctx context.Context) error {
+	a.runCtx = ctx
 	telegram_bots, err := a.TelegramBotRepo.ListAll(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load telegram bots: %w", err)
@@ -130,8 +380,7 @@ func (a *App) InitBots(ctx context.Context) error {
 			continue
 		}
 
-		_, err := a.TelegramBotRegistry.Add(bot.Token)
-		if err != nil {
+		if err := a.RunBot(ctx, bot); err != nil {
 			a.Logger.Error("Failed to initialize Telegram bot",
 				zap.String("bot_id", fmt.Sprint(bot.ID)),
 				zap.Error(err))
@@ -142,3 +391,136 @@ func (a *App) InitBots(ctx context.Context) error {
 	}
 	return nil
 }
+
+// handlerServices groups the services passed to bot and API handlers.
+func (a *App) handlerServices() handler.Services {
+	return handler.Services{
+		Users:        a.UserService,
+		Scripts:      a.ScriptService,
+		TelegramBots: a.TelegramBotService,
+		Groups:       a.GroupService,
+		Bundles:      a.BundleService,
+		Messages:     a.MessageService,
+		Alerts:       a.AlertService,
+		Inbox:        a.InboxService,
+		Runner:       a,
+	}
+}
+
+// RunBot connects to the bot, records the outcome on it and starts receiving
+// its updates until the app stops or the bot is stopped. ctx only bounds the
+// connection check. A handler already running for the bot is stopped first.
+func (a *App) RunBot(ctx context.Context, bot *telegram_bot.TelegramBot) error {
+	a.StopBot(bot)
+
+	api, err := a.TelegramBotRegistry.Add(bot.Token)
+	err = a.recordCheck(ctx, bot, err)
+	if err != nil {
+		return err
+	}
+	a.startHandler(ctx, api, bot)
+	return nil
+}
+
+// CheckBot asks Telegram about a running bot, or tries to start a bot that
+// is not running, and records the outcome on the bot.
+func (a *App) CheckBot(ctx context.Context, bot *telegram_bot.TelegramBot) error {
+	a.handlersMu.Lock()
+	running, ok := a.handlers[bot.ID]
+	a.handlersMu.Unlock()
+	if !ok {
+		return a.RunBot(ctx, bot)
+	}
+
+	api, err := a.TelegramBotRegistry.Get(running.telegramID)
+	if err != nil {
+		return err
+	}
+	_, err = api.GetMe()
+	return a.recordCheck(ctx, bot, err)
+}
+
+// recordCheck stores the outcome of connecting to the bot on it and returns
+// the error with the token cut out.
+func (a *App) recordCheck(ctx context.Context, bot *telegram_bot.TelegramBot, err error) error {
+	if err != nil && strings.Contains(err.Error(), bot.Token) {
+		// network errors carry the request URL, which contains the token
+		err = errors.New(strings.ReplaceAll(err.Error(), bot.Token, "<token>"))
+	}
+	if recErr := a.TelegramBotRepo.RecordCheck(ctx, bot.ID, err); recErr != nil {
+		a.Logger.Warn("failed to record telegram bot check",
+			zap.String("bot_id", fmt.Sprint(bot.ID)),
+			zap.Error(recErr))
+	}
+	now := time.Now().UTC()
+	bot.LastCheckedAt = &now
+	bot.LastError = ""
+	if err != nil {
+		bot.LastError = err.Error()
+	}
+	return err
+}
+
+// StopBot stops receiving the bot's updates and removes it from the
+// registry. It is a no-op for bots that are not running.
+func (a *App) StopBot(bot *telegram_bot.TelegramBot) {
+	a.handlersMu.Lock()
+	running, ok := a.handlers[bot.ID]
+	delete(a.handlers, bot.ID)
+	a.handlersMu.Unlock()
+	if !ok {
+		return
+	}
+	running.cancel()
+	a.TelegramBotRegistry.Remove(running.telegramID)
+}
+
+// startHandler starts receiving updates for the bot in the background on the
+// app context, falling back to ctx before InitBots has run.
+func (a *App) startHandler(ctx context.Context, api *tgbotapi.BotAPI, bot *telegram_bot.TelegramBot) {
+	if a.runCtx != nil {
+		ctx = a.runCtx
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	a.handlersMu.Lock()
+	a.handlers[bot.ID] = runningBot{telegramID: api.Self.ID, cancel: cancel}
+	a.handlersMu.Unlock()
+
+	services := a.handlerServices()
+	switch bot.Role {
+	case "admin":
+		go handler.NewAdminBotHandler(api, bot, services, *a.Config, a.Logger).Start(ctx)
+	default:
+		go handler.NewBotHandler(api, bot, services, *a.Config, a.Logger).Start(ctx)
+	}
+}
+
+// startHTTPServer serves the HTTP API in the background until ctx is done.
+// It is a no-op when no address is configured.
+func (a *App) startHTTPServer(ctx context.Context) {
+	cfg := a.Config.HTTP
+	if cfg.Addr == "" {
+		return
+	}
+
+	api := handler.NewAPIHandler(a.handlerServices(), cfg.APIToken, a.Logger)
+	server := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           api.Routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		a.Logger.Info("serving HTTP API", zap.String("addr", cfg.Addr))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.Logger.Error("HTTP server stopped", zap.Error(err))
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			a.Logger.Warn("failed to shut down HTTP server", zap.Error(err))
+		}
+	}()
+}
@@ -12,7 +12,9 @@ import (
 	"go.uber.org/zap"
 )
 
-func gracefulShutdown(ctx context.Context, logger logger.Logger, pool *postgres.Pool) error {
+// gracefulShutdown waits for a signal or ctx cancellation, stops background
+// workers via stop and closes the database pool.
+func gracefulShutdown(ctx context.Context, stop context.CancelFunc, logger logger.Logger, pool *postgres.Pool) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
@@ -26,6 +28,8 @@ func gracefulShutdown(ctx context.Context, logger logger.Logger, pool *postgres.
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	stop()
+
 	logger.Info("closing database connections")
 	pool.Close()
 
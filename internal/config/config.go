@@ -2,37 +2,123 @@ package config
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/url"
 	"os"
-	"regexp"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Env      string `yaml:"env"`
-	Database DatabaseConfig
-	Logger   LoggerConfig
-	Crypto   CryptoConfig
+	Env       string `yaml:"env"`
+	Database  DatabaseConfig
+	Logger    LoggerConfig
+	Crypto    CryptoConfig
+	Scheduler SchedulerConfig
+	Groups    GroupsConfig
+	Media     MediaConfig
+	HTTP      HTTPConfig
+	Alerts    AlertsConfig
+}
+
+// SchedulerConfig controls the worker that executes scheduled script steps.
+type SchedulerConfig struct {
+	PollInterval      time.Duration `mapstructure:"poll_interval"`
+	BatchSize         int           `mapstructure:"batch_size"`
+	MaxAttempts       int           `mapstructure:"max_attempts"`
+	RetryDelay        time.Duration `mapstructure:"retry_delay"`
+	ProcessingTimeout time.Duration `mapstructure:"processing_timeout"`
+}
+
+// GroupsConfig controls invite links to private groups of scripts.
+type GroupsConfig struct {
+	// InviteTTL is how long a personal invite link stays valid.
+	InviteTTL time.Duration `mapstructure:"invite_ttl"`
+	// SweepInterval is how often unused expired links are revoked and waiting
+	// join requests are reviewed.
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+}
+
+// AlertsConfig controls the operational alerts sent to bot owners through the
+// admin bot.
+type AlertsConfig struct {
+	// CheckInterval is how often bot tokens, delivery failures and overdue
+	// scheduled steps are checked.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// FailureRate is the share of failed deliveries within FailureWindow
+	// above which owners are alerted, once at least MinDeliveries were
+	// attempted.
+	FailureRate   float64       `mapstructure:"failure_rate"`
+	FailureWindow time.Duration `mapstructure:"failure_window"`
+	MinDeliveries int           `mapstructure:"min_deliveries"`
+	// SchedulerLag is how late due steps may be before owners are alerted.
+	SchedulerLag time.Duration `mapstructure:"scheduler_lag"`
+	// Cooldown is how long repeats of an alert about a bot are dropped.
+	Cooldown time.Duration `mapstructure:"cooldown"`
+	// MaxPerHour caps the alerts about one bot sent in an hour.
+	MaxPerHour int `mapstructure:"max_per_hour"`
+}
+
+// MediaConfig controls where the files of message media are stored.
+type MediaConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// HTTPConfig controls the HTTP API. The server is not started when Addr is
+// empty.
+type HTTPConfig struct {
+	Addr string `mapstructure:"addr"`
+	// APIToken is the bearer token API requests must carry.
+	APIToken string `mapstructure:"api_token" secret:"true"`
+	// PublicURL is where users reach the server, for example
+	// https://bots.example.com. URL buttons that track clicks are only
+	// rewritten when it is set.
+	PublicURL string `mapstructure:"public_url"`
+	// RedirectSecret signs the click tracking links of URL buttons.
+	RedirectSecret string `mapstructure:"redirect_secret" secret:"true"`
 }
 
 type CryptoConfig struct {
-	Keys           map[int][]byte
-	CurrentVersion int    `mapstructure:"current_key_version"`
-	Algorithm      string `mapstructure:"crypto_algorithm"`
+	Keys map[int][]byte `secret:"true"`
+	// KeyAlgorithms holds the algorithm recorded with a key version
+	// ("<algorithm>:<base64>"). Keys without one use Algorithm.
+	KeyAlgorithms  map[int]string    `mapstructure:"-"`
+	CurrentVersion int               `mapstructure:"current_key_version"`
+	Algorithm      string            `mapstructure:"crypto_algorithm"`
+	KeysDir        string            `mapstructure:"keys_dir"`
+	KeySource      *CryptoKeySource  `mapstructure:"-"`
+	KeyProvider    KeyProviderConfig `mapstructure:"key_provider"`
+	// AllowUnboundTokens lets the app read tokens sealed before they were
+	// bound to their rows. Keep it off once -reseal-tokens has run, so an
+	// old unbound ciphertext cannot be swapped into a row.
+	AllowUnboundTokens bool `mapstructure:"allow_unbound_tokens"`
+}
+
+// KeyProviderConfig selects the master key used for envelope encryption.
+// An empty type keeps tokens encrypted directly with the versioned keys.
+type KeyProviderConfig struct {
+	Type         string        `mapstructure:"type"`
+	LocalKeyFile string        `mapstructure:"local_key_file"`
+	HTTPEndpoint string        `mapstructure:"http_endpoint"`
+	HTTPKeyID    string        `mapstructure:"http_key_id"`
+	HTTPToken    string        `mapstructure:"http_token" secret:"true"`
+	HTTPTimeout  time.Duration `mapstructure:"http_timeout"`
+	// CacheTTL is how long unwrapped data keys are kept in memory, 0 unwraps
+	// on every read.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
 }
 
 type DatabaseConfig struct {
 	Host              string        `mapstructure:"host"`
 	Port              int           `mapstructure:"port"`
 	User              string        `mapstructure:"user"`
-	Password          string        `mapstructure:"password"`
+	Password          string        `mapstructure:"password" secret:"true"`
 	Name              string        `mapstructure:"name"`
 	SSLMode           string        `mapstructure:"sslmode"`
 	MaxOpenConns      int           `mapstructure:"max_open_conns"`
@@ -58,17 +144,86 @@ type Loader interface {
 	Load(ctx context.Context) (*Config, error)
 }
 
+// envKeys lists the config keys that can be overridden from .env and
+// environment variables (PROMO_BOTS_ prefix, dots replaced by underscores).
+var envKeys = []string{
+	// Database
+	"database.host",
+	"database.port",
+	"database.user",
+	"database.password",
+	"database.name",
+	"database.sslmode",
+	"database.max_open_conns",
+	"database.max_idle_conns",
+	"database.conn_max_lifetime",
+	"database.conn_max_idle_time",
+	"database.health_check_period",
+	// Logger
+	"logger.level",
+	"logger.format",
+	"logger.output",
+	"logger.enable_colors",
+	"logger.file_path",
+	"logger.max_size",
+	"logger.max_backups",
+	"logger.max_age",
+	"logger.compress",
+	// Crypto
+	"crypto.current_key_version",
+	"crypto.crypto_algorithm",
+	"crypto.keys_dir",
+	"crypto.allow_unbound_tokens",
+	"crypto.key_provider.type",
+	"crypto.key_provider.local_key_file",
+	"crypto.key_provider.http_endpoint",
+	"crypto.key_provider.http_key_id",
+	"crypto.key_provider.http_token",
+	"crypto.key_provider.http_timeout",
+	"crypto.key_provider.cache_ttl",
+	// Scheduler
+	"scheduler.poll_interval",
+	"scheduler.batch_size",
+	"scheduler.max_attempts",
+	"scheduler.retry_delay",
+	"scheduler.processing_timeout",
+	// Groups
+	"groups.invite_ttl",
+	"groups.sweep_interval",
+	// Media
+	"media.dir",
+	// HTTP
+	"http.addr",
+	"http.api_token",
+	"http.public_url",
+	"http.redirect_secret",
+	// Alerts
+	"alerts.check_interval",
+	"alerts.failure_rate",
+	"alerts.failure_window",
+	"alerts.min_deliveries",
+	"alerts.scheduler_lag",
+	"alerts.cooldown",
+	"alerts.max_per_hour",
+}
+
+const envPrefix = "PROMO_BOTS"
+
 type viperLoader struct {
 	configPath string
+	env        string
 	validator  Validator
 }
 
-func NewViperLoader(configPath string, validator Validator) Loader {
+// NewViperLoader creates a loader that layers config sources in order:
+// config.yaml, config.<env>.yaml, .env and environment variables.
+func NewViperLoader(configPath, env string, validator Validator) Loader {
 	if configPath == "" {
 		configPath = "."
 	}
 	return &viperLoader{
 		configPath: configPath,
+		env:        normalizeEnv(env),
 		validator:  validator,
 	}
 }
@@ -78,49 +233,57 @@ func (l *viperLoader) Load(ctx context.Context) (*Config, error) {
 
 	v := viper.New()
 
-	v.SetConfigName("config")
-	v.SetConfigType("yaml")
-	v.AddConfigPath(l.configPath)
-	v.AddConfigPath(".")
+	// base config
+	if err := l.mergeConfigFile(v, "config"); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
 
-	if err := v.ReadInConfig(); err != nil {
-		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if !errors.As(err, &configFileNotFoundError) {
-			return nil, fmt.Errorf("failed to read config: %w", err)
+	// env-specific overlay
+	if l.env != "" {
+		if err := l.mergeConfigFile(v, "config."+l.env); err != nil {
+			return nil, fmt.Errorf("failed to read %s config: %w", l.env, err)
 		}
 	}
 
-	// env config
-	v.SetConfigName(".env")
-	v.SetConfigType("env")
-	v.AddConfigPath(l.configPath)
-	v.AddConfigPath(".")
-	if err := v.MergeInConfig(); err != nil {
-		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if !errors.As(err, &configFileNotFoundError) {
-			return nil, fmt.Errorf("failed to read env: %w", err)
-		}
+	// .env config
+	dotEnv, err := l.readDotEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env: %w", err)
+	}
+	if err := v.MergeConfigMap(dotEnvConfig(dotEnv)); err != nil {
+		return nil, fmt.Errorf("failed to merge env: %w", err)
 	}
 
 	v.AutomaticEnv()
-	v.SetEnvPrefix("PROMO_BOTS")
+	v.SetEnvPrefix(envPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	l.BindEnvVariables(v)
 
+	if err := applySecretFiles(v, dotEnv); err != nil {
+		return nil, fmt.Errorf("failed to read secret files: %w", err)
+	}
+
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	keys, err := loadCryptoKeys(v)
+	if l.env != "" {
+		cfg.Env = l.env
+	}
+
+	cfg.Crypto.KeySource = &CryptoKeySource{
+		dotEnv:        dotEnv,
+		keysDir:       cfg.Crypto.KeysDir,
+		pinnedVersion: cfg.Crypto.CurrentVersion,
+	}
+	keys, algorithms, current, err := cfg.Crypto.KeySource.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load crypto keys: %w", err)
 	}
 	cfg.Crypto.Keys = keys
-
-	if cfg.Crypto.CurrentVersion == 0 {
-		cfg.Crypto.CurrentVersion = getLastCryptoKeyVersion(keys)
-	}
+	cfg.Crypto.KeyAlgorithms = algorithms
+	cfg.Crypto.CurrentVersion = current
 
 	if err := l.validator.Validate(cfg); err != nil {
 		return nil, fmt.Errorf("config failed validation: %w", err)
@@ -129,87 +292,88 @@ func (l *viperLoader) Load(ctx context.Context) (*Config, error) {
 	return cfg, nil
 }
 
-func (l *viperLoader) BindEnvVariables(v *viper.Viper) {
-	// Database
-	_ = v.BindEnv("database.host")
-	_ = v.BindEnv("database.port")
-	_ = v.BindEnv("database.user")
-	_ = v.BindEnv("database.password")
-	_ = v.BindEnv("database.name")
-	_ = v.BindEnv("database.sslmode")
-	_ = v.BindEnv("database.max_open_conns")
-	_ = v.BindEnv("database.max_idle_conns")
-	// Logger
-	_ = v.BindEnv("logger.level")
-	_ = v.BindEnv("logger.format")
-	_ = v.BindEnv("logger.output")
-	_ = v.BindEnv("logger.enable_colors")
-	_ = v.BindEnv("logger.file_path")
-	_ = v.BindEnv("logger.max_size")
-	_ = v.BindEnv("logger.max_backups")
-	_ = v.BindEnv("logger.max_age")
-	_ = v.BindEnv("logger.compress")
-	_ = v.BindEnv("logger.conn_max_lifetime")
-	_ = v.BindEnv("logger.conn_max_idle_time")
-	_ = v.BindEnv("logger.health_check_period")
-	// Crypto
-	_ = v.BindEnv("crypto.current_key_version")
-	_ = v.BindEnv("crypto.crypto_algorithm")
-}
-
-func loadCryptoKeys(v *viper.Viper) (map[int][]byte, error) {
-	// Look for environment variables with pattern PROMO_BOTS_TOKEN_ENCRYPTION_KEY_V{N}
-	// and parse each base64 value into a key bytes slice.
-	re := regexp.MustCompile(`^PROMO_BOTS_TOKEN_ENCRYPTION_KEY(?:_V(\d+))?$`)
+// mergeConfigFile merges the named yaml file from the config path into v.
+// A missing file is not an error.
+func (l *viperLoader) mergeConfigFile(v *viper.Viper, name string) error {
+	v.SetConfigName(name)
+	v.SetConfigType("yaml")
+	v.AddConfigPath(l.configPath)
+	v.AddConfigPath(".")
 
-	result := make(map[int][]byte)
+	if err := v.MergeInConfig(); err != nil {
+		var configFileNotFoundError viper.ConfigFileNotFoundError
+		if !errors.As(err, &configFileNotFoundError) {
+			return err
+		}
+	}
+	return nil
+}
 
-	for _, e := range os.Environ() {
-		// e is like "KEY=VALUE"
-		parts := strings.SplitN(e, "=", 2)
-		if len(parts) != 2 {
-			continue
+// readDotEnv reads the first .env file found in the config path or the
+// working directory. A missing file yields an empty map.
+func (l *viperLoader) readDotEnv() (map[string]string, error) {
+	for _, dir := range []string{l.configPath, "."} {
+		values, err := godotenv.Read(filepath.Join(dir, ".env"))
+		if err == nil {
+			return values, nil
 		}
-		name := parts[0]
-		val := parts[1]
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return map[string]string{}, nil
+}
+
+func (l *viperLoader) BindEnvVariables(v *viper.Viper) {
+	for _, key := range envKeys {
+		_ = v.BindEnv(key)
+	}
+}
 
-		m := re.FindStringSubmatch(name)
-		if m == nil {
+// dotEnvConfig converts known PROMO_BOTS_* entries of a .env file into a
+// nested config map so they can be merged below environment variables.
+func dotEnvConfig(dotEnv map[string]string) map[string]any {
+	result := make(map[string]any)
+	for _, key := range envKeys {
+		val, ok := dotEnv[envName(key)]
+		if !ok {
 			continue
 		}
 
-		ver := 1
-		if m[1] != "" {
-			n, err := strconv.Atoi(m[1])
-			if err != nil {
-				return nil, fmt.Errorf("invalid key version in env var %s: %w", name, err)
+		parts := strings.Split(key, ".")
+		node := result
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				node[part] = child
 			}
-			ver = n
-		}
-
-		decoded, err := base64.StdEncoding.DecodeString(val)
-		if err != nil {
-			return nil, fmt.Errorf("invalid base64 for %s: %w", name, err)
+			node = child
 		}
-
-		result[ver] = decoded
+		node[parts[len(parts)-1]] = val
 	}
+	return result
+}
 
-	return result, nil
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
 }
 
-func getLastCryptoKeyVersion(keys map[int][]byte) int {
-	maxVer := 0
-	for ver := range keys {
-		if ver > maxVer {
-			maxVer = ver
-		}
+// normalizeEnv maps APP_ENV values to the suffix of the overlay file.
+func normalizeEnv(env string) string {
+	env = strings.ToLower(strings.TrimSpace(env))
+	switch env {
+	case "production":
+		return "prod"
+	case "development":
+		return "dev"
+	default:
+		return env
 	}
-	return maxVer
 }
 
 func Load(configPath string, ctx context.Context) (*Config, error) {
-	loader := NewViperLoader(configPath, NewValidator())
+	loader := NewViperLoader(configPath, os.Getenv("APP_ENV"), NewValidator())
 	return loader.Load(ctx)
 }
 
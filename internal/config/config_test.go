@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestViperLoader_Layering(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "database:\n  host: base\n  port: 5000\n  user: base\n  sslmode: disable\nlogger:\n  level: debug\n")
+	writeFile(t, dir, "config.prod.yaml", "database:\n  host: prod\n  port: 6000\nlogger:\n  level: warn\n")
+	writeFile(t, dir, ".env", "PROMO_BOTS_DATABASE_PORT=7000\nPROMO_BOTS_DATABASE_USER=dotenv\n")
+
+	t.Setenv("PROMO_BOTS_DATABASE_USER", "env")
+	t.Setenv("PROMO_BOTS_TOKEN_ENCRYPTION_KEY_V1", base64.StdEncoding.EncodeToString(make([]byte, 32)))
+
+	cfg, err := NewViperLoader(dir, "production", NewValidator()).Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	if cfg.Env != "prod" {
+		t.Errorf("env: got %q want %q", cfg.Env, "prod")
+	}
+	if cfg.Database.Host != "prod" {
+		t.Errorf("overlay should override base host: got %q", cfg.Database.Host)
+	}
+	if cfg.Database.SSLMode != "disable" {
+		t.Errorf("base value should survive overlay: got %q", cfg.Database.SSLMode)
+	}
+	if cfg.Database.Port != 7000 {
+		t.Errorf(".env should override overlay port: got %d", cfg.Database.Port)
+	}
+	if cfg.Database.User != "env" {
+		t.Errorf("environment should override .env user: got %q", cfg.Database.User)
+	}
+	if cfg.Logger.Level != "warn" {
+		t.Errorf("overlay should override logger level: got %q", cfg.Logger.Level)
+	}
+}
+
+func TestConfig_PrintRedactsSecrets(t *testing.T) {
+	cfg := SetDefaultConfig()
+	cfg.Database.Password = "super-secret"
+	cfg.Crypto.Keys = map[int][]byte{1: []byte("key-material")}
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatalf("Print error: %v", err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, "super-secret") || strings.Contains(out, "key-material") {
+		t.Fatalf("secrets leaked into output:\n%s", out)
+	}
+	if !strings.Contains(out, "database.password: "+redactedValue) {
+		t.Errorf("password not redacted:\n%s", out)
+	}
+	if !strings.Contains(out, "database.host: localhost") {
+		t.Errorf("missing regular value:\n%s", out)
+	}
+}
+
+func TestViperLoader_SecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secrets := t.TempDir()
+	keysDir := t.TempDir()
+
+	key1 := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	key2 := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	writeFile(t, secrets, "db_password", "from-file\n")
+	writeFile(t, secrets, "key_v1", key1+"\n")
+	writeFile(t, keysDir, "v2", "xchacha20_poly1305:"+key2)
+	writeFile(t, keysDir, "README", "not a key")
+
+	t.Setenv("PROMO_BOTS_DATABASE_PASSWORD_FILE", filepath.Join(secrets, "db_password"))
+	t.Setenv("PROMO_BOTS_TOKEN_ENCRYPTION_KEY_V1_FILE", filepath.Join(secrets, "key_v1"))
+	t.Setenv("PROMO_BOTS_CRYPTO_KEYS_DIR", keysDir)
+
+	cfg, err := NewViperLoader(dir, "", NewValidator()).Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	if cfg.Database.Password != "from-file" {
+		t.Errorf("password: got %q want %q", cfg.Database.Password, "from-file")
+	}
+	if len(cfg.Crypto.Keys) != 2 {
+		t.Fatalf("keys: got %d versions want 2", len(cfg.Crypto.Keys))
+	}
+	if cfg.Crypto.CurrentVersion != 2 {
+		t.Errorf("current version: got %d want 2", cfg.Crypto.CurrentVersion)
+	}
+	if got := cfg.Crypto.KeyAlgorithms; len(got) != 1 || got[2] != "xchacha20_poly1305" {
+		t.Errorf("key algorithms: got %v want only v2 bound to xchacha20_poly1305", got)
+	}
+
+	paths := cfg.Crypto.KeySource.WatchPaths()
+	if len(paths) != 2 {
+		t.Errorf("watch paths: got %v", paths)
+	}
+}
+
+func TestViperLoader_SecretAndFileConflict(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "password", "from-file")
+
+	t.Setenv("PROMO_BOTS_DATABASE_PASSWORD", "from-env")
+	t.Setenv("PROMO_BOTS_DATABASE_PASSWORD_FILE", filepath.Join(dir, "password"))
+	t.Setenv("PROMO_BOTS_TOKEN_ENCRYPTION_KEY_V1", base64.StdEncoding.EncodeToString(make([]byte, 32)))
+
+	if _, err := NewViperLoader(dir, "", NewValidator()).Load(context.Background()); err == nil {
+		t.Fatalf("expected error when both value and _FILE are set")
+	}
+}
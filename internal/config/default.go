@@ -30,6 +30,33 @@ func SetDefaultConfig() *Config {
 		},
 		Crypto: CryptoConfig{
 			Algorithm: "aes_gcm",
+			KeyProvider: KeyProviderConfig{
+				HTTPTimeout: 5 * time.Second,
+				CacheTTL:    5 * time.Minute,
+			},
+		},
+		Scheduler: SchedulerConfig{
+			PollInterval:      2 * time.Second,
+			BatchSize:         50,
+			MaxAttempts:       5,
+			RetryDelay:        1 * time.Minute,
+			ProcessingTimeout: 5 * time.Minute,
+		},
+		Groups: GroupsConfig{
+			InviteTTL:     24 * time.Hour,
+			SweepInterval: 1 * time.Minute,
+		},
+		Media: MediaConfig{
+			Dir: "./media",
+		},
+		Alerts: AlertsConfig{
+			CheckInterval: 1 * time.Minute,
+			FailureRate:   0.5,
+			FailureWindow: 15 * time.Minute,
+			MinDeliveries: 20,
+			SchedulerLag:  10 * time.Minute,
+			Cooldown:      1 * time.Hour,
+			MaxPerHour:    5,
 		},
 	}
 }
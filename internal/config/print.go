@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const redactedValue = "******"
+
+// Redacted flattens the effective config into dotted keys (as used in yaml
+// files) with values of fields tagged `secret:"true"` masked.
+func (c *Config) Redacted() map[string]string {
+	result := make(map[string]string)
+	flatten(reflect.ValueOf(*c), "", result)
+	return result
+}
+
+// Print writes the effective config to w, one "key: value" per line,
+// with secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	values := c.Redacted()
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", k, values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flatten(v reflect.Value, prefix string, out map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := fieldKey(field)
+		if key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		fv := v.Field(i)
+		switch {
+		case field.Tag.Get("secret") == "true":
+			out[key] = redact(fv)
+		case fv.Kind() == reflect.Struct:
+			flatten(fv, key, out)
+		default:
+			out[key] = fmt.Sprint(fv.Interface())
+		}
+	}
+}
+
+func fieldKey(field reflect.StructField) string {
+	if tag, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ","); tag != "" {
+		return tag
+	}
+	if tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ","); tag != "" {
+		return tag
+	}
+	return strings.ToLower(field.Name)
+}
+
+func redact(v reflect.Value) string {
+	if v.IsZero() {
+		return ""
+	}
+	if v.Kind() == reflect.Map {
+		return fmt.Sprintf("%s (%d entries)", redactedValue, v.Len())
+	}
+	return redactedValue
+}
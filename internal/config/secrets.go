@@ -0,0 +1,278 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// secretKeys lists config keys that can also be read from a file named by
+// the <ENV_NAME>_FILE variable (Docker/Kubernetes secrets).
+var secretKeys = []string{
+	"database.password",
+	"crypto.key_provider.http_token",
+	"http.api_token",
+	"http.redirect_secret",
+}
+
+var (
+	cryptoKeyEnvRe  = regexp.MustCompile(`^PROMO_BOTS_TOKEN_ENCRYPTION_KEY(?:_V(\d+))?(_FILE)?$`)
+	cryptoKeyFileRe = regexp.MustCompile(`^[vV]?(\d+)(?:\.key)?$`)
+)
+
+// applySecretFiles sets secret values from *_FILE variables found in the
+// environment or .env. Setting both a value and its _FILE variant is an error.
+func applySecretFiles(v *viper.Viper, dotEnv map[string]string) error {
+	for _, key := range secretKeys {
+		name := envName(key)
+		path := lookupVar(dotEnv, name+"_FILE")
+		if path == "" {
+			continue
+		}
+		if lookupVar(dotEnv, name) != "" {
+			return fmt.Errorf("both %s and %s_FILE are set", name, name)
+		}
+
+		value, err := readSecretFile(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		v.Set(key, string(value))
+	}
+	return nil
+}
+
+// lookupVar returns the environment variable name, falling back to .env.
+func lookupVar(dotEnv map[string]string, name string) string {
+	if val, ok := os.LookupEnv(name); ok {
+		return val
+	}
+	return dotEnv[name]
+}
+
+func readSecretFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(string(data), "\r\n")), nil
+}
+
+// CryptoKeySource reads token encryption keys from every supported source:
+// PROMO_BOTS_TOKEN_ENCRYPTION_KEY_V{N} variables, their _FILE variants and
+// the keys directory, where each file (v1, v2, 3.key, ...) holds one version.
+// A key value is base64, optionally prefixed with the algorithm the key is
+// bound to ("xchacha20_poly1305:<base64>"). Load can be called again to pick
+// up rotated keys.
+type CryptoKeySource struct {
+	dotEnv        map[string]string
+	keysDir       string
+	pinnedVersion int
+}
+
+// Load returns all key versions, the algorithms recorded for them and the
+// current version: the configured current_key_version, or the latest one when
+// it is not set.
+func (s *CryptoKeySource) Load() (map[int][]byte, map[int]string, int, error) {
+	keys, algorithms, err := loadCryptoKeys(s.dotEnv)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if s.keysDir != "" {
+		dirKeys, dirAlgorithms, err := loadCryptoKeysDir(s.keysDir)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		for ver, key := range dirKeys {
+			if _, ok := keys[ver]; ok {
+				return nil, nil, 0, fmt.Errorf("key version %d is defined both in env and in %s", ver, s.keysDir)
+			}
+			keys[ver] = key
+			if algorithm, ok := dirAlgorithms[ver]; ok {
+				algorithms[ver] = algorithm
+			}
+		}
+	}
+
+	current := s.pinnedVersion
+	if current == 0 {
+		current = getLastCryptoKeyVersion(keys)
+	}
+	return keys, algorithms, current, nil
+}
+
+// WatchPaths returns directories whose changes may affect the loaded keys.
+// Directories are watched instead of files so atomic secret updates
+// (symlink swaps in Kubernetes) are not missed.
+func (s *CryptoKeySource) WatchPaths() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(dir string) {
+		if dir == "" || seen[dir] {
+			return
+		}
+		seen[dir] = true
+		paths = append(paths, dir)
+	}
+
+	add(s.keysDir)
+	for name, path := range s.cryptoKeyVars() {
+		if strings.HasSuffix(name, "_FILE") {
+			add(filepath.Dir(path))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// cryptoKeyVars collects key variables from .env and the environment.
+// Environment variables take precedence over .env entries.
+func (s *CryptoKeySource) cryptoKeyVars() map[string]string {
+	vars := make(map[string]string)
+	for name, val := range s.dotEnv {
+		if cryptoKeyEnvRe.MatchString(name) {
+			vars[name] = val
+		}
+	}
+	for _, e := range os.Environ() {
+		// e is like "KEY=VALUE"
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if cryptoKeyEnvRe.MatchString(parts[0]) {
+			vars[parts[0]] = parts[1]
+		}
+	}
+	return vars
+}
+
+func loadCryptoKeys(dotEnv map[string]string) (map[int][]byte, map[int]string, error) {
+	// Look for variables with pattern PROMO_BOTS_TOKEN_ENCRYPTION_KEY_V{N}
+	// (or PROMO_BOTS_TOKEN_ENCRYPTION_KEY_V{N}_FILE) and parse each base64
+	// value into a key bytes slice.
+	vars := (&CryptoKeySource{dotEnv: dotEnv}).cryptoKeyVars()
+
+	result := make(map[int][]byte)
+	algorithms := make(map[int]string)
+
+	for name, val := range vars {
+		m := cryptoKeyEnvRe.FindStringSubmatch(name)
+
+		ver := 1
+		if m[1] != "" {
+			n, err := strconv.Atoi(m[1])
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid key version in env var %s: %w", name, err)
+			}
+			ver = n
+		}
+
+		if m[2] != "" {
+			data, err := readSecretFile(val)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			val = string(data)
+		}
+
+		if _, ok := result[ver]; ok {
+			return nil, nil, fmt.Errorf("key version %d is defined more than once", ver)
+		}
+
+		algorithm, decoded, err := parseCryptoKey(val)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid key in %s: %w", name, err)
+		}
+
+		result[ver] = decoded
+		if algorithm != "" {
+			algorithms[ver] = algorithm
+		}
+	}
+
+	return result, algorithms, nil
+}
+
+// loadCryptoKeysDir reads one base64 key per file. Hidden entries (such as
+// the ..data links of a Kubernetes secret volume) and files whose name is
+// not a version are skipped.
+func loadCryptoKeysDir(dir string) (map[int][]byte, map[int]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("keys dir %s does not exist", dir)
+		}
+		return nil, nil, fmt.Errorf("failed to read keys dir: %w", err)
+	}
+
+	result := make(map[int][]byte)
+	algorithms := make(map[int]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || entry.IsDir() {
+			continue
+		}
+
+		m := cryptoKeyFileRe.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		ver, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid key version in file %s: %w", name, err)
+		}
+
+		path := filepath.Join(dir, name)
+		data, err := readSecretFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+		}
+		algorithm, decoded, err := parseCryptoKey(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid key in %s: %w", path, err)
+		}
+		result[ver] = decoded
+		if algorithm != "" {
+			algorithms[ver] = algorithm
+		}
+	}
+	return result, algorithms, nil
+}
+
+// parseCryptoKey splits an "<algorithm>:<base64>" key value. The algorithm
+// is empty when the value is plain base64, which never contains a colon.
+func parseCryptoKey(val string) (string, []byte, error) {
+	val = strings.TrimSpace(val)
+
+	var algorithm string
+	if i := strings.IndexByte(val, ':'); i >= 0 {
+		algorithm, val = val[:i], val[i+1:]
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(val)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return algorithm, decoded, nil
+}
+
+func getLastCryptoKeyVersion(keys map[int][]byte) int {
+	maxVer := 0
+	for ver := range keys {
+		if ver > maxVer {
+			maxVer = ver
+		}
+	}
+	return maxVer
+}
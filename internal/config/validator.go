@@ -1,6 +1,9 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 type Validator interface {
 	Validate(*Config) error
@@ -28,6 +31,26 @@ func (v validator) Validate(cfg *Config) error {
 		return fmt.Errorf("crypto config: %w", err)
 	}
 
+	if err := v.validateScheduler(cfg.Scheduler); err != nil {
+		return fmt.Errorf("scheduler config: %w", err)
+	}
+
+	if err := v.validateGroups(cfg.Groups); err != nil {
+		return fmt.Errorf("groups config: %w", err)
+	}
+
+	if err := v.validateMedia(cfg.Media); err != nil {
+		return fmt.Errorf("media config: %w", err)
+	}
+
+	if err := v.validateHTTP(cfg.HTTP); err != nil {
+		return fmt.Errorf("http config: %w", err)
+	}
+
+	if err := v.validateAlerts(cfg.Alerts); err != nil {
+		return fmt.Errorf("alerts config: %w", err)
+	}
+
 	return nil
 }
 
@@ -124,10 +147,20 @@ func (v validator) validateLogger(logger LoggerConfig) error {
 }
 
 func (v validator) validateCrypto(crypto CryptoConfig) error {
-	if crypto.Keys == nil {
+	if err := v.validateKeyProvider(crypto.KeyProvider); err != nil {
+		return fmt.Errorf("key provider: %w", err)
+	}
+
+	// With envelope encryption versioned keys are only needed to read
+	// rows that have not been resealed yet.
+	if len(crypto.Keys) == 0 && crypto.KeyProvider.Type == "" {
 		return fmt.Errorf("crypto keys is required")
 	}
 
+	if _, ok := crypto.Keys[crypto.CurrentVersion]; !ok && len(crypto.Keys) > 0 {
+		return fmt.Errorf("crypto key for current version %d is not defined", crypto.CurrentVersion)
+	}
+
 	for ver, key := range crypto.Keys {
 		if len(key) != 32 {
 			return fmt.Errorf("crypto key for version %d must be 32 bytes long, got: %d", ver, len(key))
@@ -135,11 +168,128 @@ func (v validator) validateCrypto(crypto CryptoConfig) error {
 	}
 
 	validAlgorithms := map[string]bool{
-		"aes_gcm": true,
+		"aes_gcm":            true,
+		"xchacha20_poly1305": true,
 	}
 	if !validAlgorithms[crypto.Algorithm] {
-		return fmt.Errorf("crypto algorithm must be (aes_gcm), got: %v", crypto.Algorithm)
+		return fmt.Errorf("crypto algorithm must be (aes_gcm, xchacha20_poly1305), got: %v", crypto.Algorithm)
+	}
+	for ver, algorithm := range crypto.KeyAlgorithms {
+		if !validAlgorithms[algorithm] {
+			return fmt.Errorf("crypto key algorithm for version %d must be (aes_gcm, xchacha20_poly1305), got: %v", ver, algorithm)
+		}
 	}
 
 	return nil
 }
+
+func (v validator) validateKeyProvider(provider KeyProviderConfig) error {
+	if provider.CacheTTL < 0 {
+		return fmt.Errorf("cache_ttl must not be negative, got %v", provider.CacheTTL)
+	}
+	switch provider.Type {
+	case "":
+		return nil
+	case "local":
+		if provider.LocalKeyFile == "" {
+			return fmt.Errorf("local_key_file is required for local provider")
+		}
+	case "http":
+		if provider.HTTPEndpoint == "" {
+			return fmt.Errorf("http_endpoint is required for http provider")
+		}
+		if provider.HTTPKeyID == "" {
+			return fmt.Errorf("http_key_id is required for http provider")
+		}
+		if provider.HTTPTimeout <= 0 {
+			return fmt.Errorf("http_timeout must be positive, got %v", provider.HTTPTimeout)
+		}
+	default:
+		return fmt.Errorf("type must be (local, http), got: %v", provider.Type)
+	}
+	return nil
+}
+
+func (v validator) validateScheduler(scheduler SchedulerConfig) error {
+	if scheduler.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive, got %v", scheduler.PollInterval)
+	}
+	if scheduler.BatchSize < 1 {
+		return fmt.Errorf("batch_size must be at least 1, got: %v", scheduler.BatchSize)
+	}
+	if scheduler.MaxAttempts < 1 {
+		return fmt.Errorf("max_attempts must be at least 1, got: %v", scheduler.MaxAttempts)
+	}
+	if scheduler.RetryDelay < 0 {
+		return fmt.Errorf("retry_delay must be positive, got %v", scheduler.RetryDelay)
+	}
+	if scheduler.ProcessingTimeout <= 0 {
+		return fmt.Errorf("processing_timeout must be positive, got %v", scheduler.ProcessingTimeout)
+	}
+	return nil
+}
+
+func (v validator) validateGroups(groups GroupsConfig) error {
+	if groups.InviteTTL <= 0 {
+		return fmt.Errorf("invite_ttl must be positive, got %v", groups.InviteTTL)
+	}
+	if groups.SweepInterval <= 0 {
+		return fmt.Errorf("sweep_interval must be positive, got %v", groups.SweepInterval)
+	}
+	return nil
+}
+
+func (v validator) validateAlerts(alerts AlertsConfig) error {
+	if alerts.CheckInterval <= 0 {
+		return fmt.Errorf("check_interval must be positive, got %v", alerts.CheckInterval)
+	}
+	if alerts.FailureRate <= 0 || alerts.FailureRate > 1 {
+		return fmt.Errorf("failure_rate must be in (0, 1], got: %v", alerts.FailureRate)
+	}
+	if alerts.FailureWindow <= 0 {
+		return fmt.Errorf("failure_window must be positive, got %v", alerts.FailureWindow)
+	}
+	if alerts.MinDeliveries < 1 {
+		return fmt.Errorf("min_deliveries must be at least 1, got: %v", alerts.MinDeliveries)
+	}
+	if alerts.SchedulerLag <= 0 {
+		return fmt.Errorf("scheduler_lag must be positive, got %v", alerts.SchedulerLag)
+	}
+	if alerts.Cooldown < 0 {
+		return fmt.Errorf("cooldown must not be negative, got %v", alerts.Cooldown)
+	}
+	if alerts.MaxPerHour < 1 {
+		return fmt.Errorf("max_per_hour must be at least 1, got: %v", alerts.MaxPerHour)
+	}
+	return nil
+}
+
+func (v validator) validateMedia(media MediaConfig) error {
+	if media.Dir == "" {
+		return fmt.Errorf("dir is empty")
+	}
+	return nil
+}
+
+// minRedirectSecret is the shortest secret accepted for signing redirects.
+const minRedirectSecret = 32
+
+func (v validator) validateHTTP(http HTTPConfig) error {
+	if http.Addr != "" && http.APIToken == "" {
+		return fmt.Errorf("api_token is empty")
+	}
+	if http.PublicURL == "" {
+		return nil
+	}
+	if http.Addr == "" {
+		return fmt.Errorf("public_url is set but addr is empty")
+	}
+	u, err := url.Parse(http.PublicURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("public_url must be an absolute http(s) URL, got: %q", http.PublicURL)
+	}
+	if len(http.RedirectSecret) < minRedirectSecret {
+		return fmt.Errorf("redirect_secret must be at least %d characters", minRedirectSecret)
+	}
+	return nil
+}
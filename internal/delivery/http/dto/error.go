@@ -0,0 +1,6 @@
+package dto
+
+// Error is the body of failed API responses.
+type Error struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+// Funnel is the step funnel of a script version.
+type Funnel struct {
+	Script  string       `json:"script"`
+	Version int          `json:"version"`
+	Steps   []FunnelStep `json:"steps"`
+}
+
+type FunnelStep struct {
+	Order       int     `json:"order"`
+	Reached     int     `json:"reached"`
+	Sent        int     `json:"sent"`
+	Clicked     int     `json:"clicked"`
+	Completed   int     `json:"completed"`
+	DropOff     int     `json:"drop_off"`
+	DropOffRate float64 `json:"drop_off_rate"`
+	// MedianSeconds is the median time to the next step.
+	MedianSeconds float64 `json:"median_seconds"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/google/uuid"
+
+// PreviewRequest starts a preview run of a script for a user. Version 0
+// previews the draft, or the published version when there is no draft.
+type PreviewRequest struct {
+	TelegramID int64 `json:"telegram_id"`
+	Version    int   `json:"version,omitempty"`
+}
+
+type PreviewResponse struct {
+	ProgressID uuid.UUID `json:"progress_id"`
+	Version    int       `json:"version"`
+}
@@ -2,23 +2,48 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/VladKovDev/promo-bot/internal/config"
+	"github.com/VladKovDev/promo-bot/internal/domain/bundle"
+	"github.com/VladKovDev/promo-bot/internal/domain/group"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
 	"github.com/VladKovDev/promo-bot/pkg/logger"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
 )
 
 type AdminBotHandler struct {
-	bot    *tgbotapi.BotAPI
-	cfg    config.Config
-	logger logger.Logger
+	bot      *tgbotapi.BotAPI
+	record   *telegram_bot.TelegramBot
+	services Services
+	cfg      config.Config
+	logger   logger.Logger
+	// authoring and stepMenus are keyed by chat. Updates are handled one at
+	// a time, so they need no lock.
+	authoring map[int64]*stepDraft
+	stepMenus map[int64]*stepMenu
 }
 
-func NewAdminBotHandler(bot *tgbotapi.BotAPI, cfg config.Config, logger logger.Logger) *BotHandler {
-	return &BotHandler{
-		bot:    bot,
-		cfg:    cfg,
-		logger: logger,
+func NewAdminBotHandler(bot *tgbotapi.BotAPI, record *telegram_bot.TelegramBot, services Services, cfg config.Config, logger logger.Logger) *AdminBotHandler {
+	return &AdminBotHandler{
+		bot:       bot,
+		record:    record,
+		services:  services,
+		cfg:       cfg,
+		logger:    logger,
+		authoring: make(map[int64]*stepDraft),
+		stepMenus: make(map[int64]*stepMenu),
 	}
 }
 
@@ -28,6 +53,7 @@ func (a *AdminBotHandler) Start(ctx context.Context) {
 	u.Timeout = 60
 
 	updates := a.bot.GetUpdatesChan(u)
+	defer a.bot.StopReceivingUpdates()
 
 	for {
 		select {
@@ -37,34 +63,1004 @@ func (a *AdminBotHandler) Start(ctx context.Context) {
 			if !ok {
 				return
 			}
+			a.handleUpdate(ctx, upd)
+		}
+	}
+}
 
-			if upd.Message == nil {
-				continue
-			}
+func (a *AdminBotHandler) handleUpdate(ctx context.Context, upd tgbotapi.Update) {
+	defer recoverUpdate(ctx, a.services, a.record, a.logger)
+
+	if upd.CallbackQuery != nil {
+		switch data := upd.CallbackQuery.Data; {
+		case strings.HasPrefix(data, stepCallbackPrefix):
+			a.handleStepCallback(ctx, upd.CallbackQuery)
+		case strings.HasPrefix(data, botCallbackPrefix):
+			a.handleBotCallback(ctx, upd.CallbackQuery)
+		}
+		return
+	}
+
+	if upd.Message == nil || upd.Message.From == nil {
+		return
+	}
 
-			if upd.Message.IsCommand() {
-				switch upd.Message.Command() {
-				case "start":
-					a.handleStart(upd.Message)
-				case "new_bot":
-					a.handleNewBot(upd.Message)
-				default:
-					// unhandled commands can be ignored for now
-				}
+	if upd.Message.IsCommand() {
+		switch upd.Message.Command() {
+		case "start":
+			a.handleStart(upd.Message)
+		case "new_bot":
+			a.handleNewBot(upd.Message)
+		case "bots":
+			a.handleBots(ctx, upd.Message)
+		case "disable", "enable", "delete":
+			a.handleBotAction(ctx, upd.Message, upd.Message.Command())
+		case "alerts":
+			a.handleAlerts(ctx, upd.Message)
+		case "inbox":
+			a.handleInbox(ctx, upd.Message)
+		case "quiet_hours":
+			a.handleQuietHours(ctx, upd.Message)
+		case "bot_timezone":
+			a.handleBotTimezone(ctx, upd.Message)
+		case "attr":
+			a.handleAttr(ctx, upd.Message)
+		case "tag":
+			a.handleTag(ctx, upd.Message)
+		case "segment":
+			a.handleSegment(ctx, upd.Message)
+		case "link":
+			a.handleLink(ctx, upd.Message)
+		case "links":
+			a.handleLinks(ctx, upd.Message)
+		case "group":
+			a.handleGroup(ctx, upd.Message)
+		case "group_policy":
+			a.handleGroupPolicy(ctx, upd.Message)
+		case "versions":
+			a.handleVersions(ctx, upd.Message)
+		case "draft":
+			a.handleDraft(ctx, upd.Message)
+		case "publish":
+			a.handlePublish(ctx, upd.Message)
+		case "rollback":
+			a.handleRollback(ctx, upd.Message)
+		case "preview":
+			a.handlePreview(ctx, upd.Message)
+		case "ab":
+			a.handleAB(ctx, upd.Message)
+		case "stats":
+			a.handleStats(ctx, upd.Message)
+		case "add_step":
+			a.handleAddStep(ctx, upd.Message)
+		case "steps":
+			a.handleSteps(ctx, upd.Message)
+		case "skip":
+			a.handleSkip(ctx, upd.Message)
+		case "cancel":
+			a.handleCancel(upd.Message)
+		case "export":
+			a.handleExport(ctx, upd.Message)
+		case "import":
+			var doc *tgbotapi.Document
+			if upd.Message.ReplyToMessage != nil {
+				doc = upd.Message.ReplyToMessage.Document
 			}
+			a.handleImport(ctx, upd.Message, upd.Message.CommandArguments(), doc)
+		default:
+			// unhandled commands can be ignored for now
 		}
+	} else if cmd, args := captionCommand(upd.Message.Caption, a.bot.Self.UserName); cmd == "import" && upd.Message.Document != nil {
+		a.handleImport(ctx, upd.Message, args, upd.Message.Document)
+	} else if !a.handleInboxReply(ctx, upd.Message) {
+		a.handleAuthoring(ctx, upd.Message)
 	}
 }
 
 func (a *AdminBotHandler) handleStart(msg *tgbotapi.Message) {
-	chatID := msg.Chat.ID
-	reply := "start command received by admin"
+	a.reply(msg.Chat.ID, "start command received by admin")
+}
 
-	m := tgbotapi.NewMessage(chatID, reply)
-	if _, err := a.bot.Send(m); err != nil {
-		a.logger.Error("failed to send /start reply")
+func (a *AdminBotHandler) handleNewBot(msg *tgbotapi.Message) {
+}
+
+// handleQuietHours shows or replaces a bot's quiet hours:
+// /quiet_hours @bot 22:00-08:00 13:00-14:00, or /quiet_hours @bot off.
+func (a *AdminBotHandler) handleQuietHours(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 {
+		a.reply(msg.Chat.ID, "Usage: /quiet_hours @bot 22:00-08:00 [13:00-14:00 ...] or /quiet_hours @bot off")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
 	}
+
+	if len(args) == 1 {
+		windows, err := a.services.TelegramBots.QuietHours(ctx, bot.ID)
+		if err != nil {
+			a.logger.Error("failed to get quiet hours", zap.Error(err))
+			return
+		}
+		a.reply(msg.Chat.ID, fmt.Sprintf("Quiet hours of @%s (%s): %s", bot.Username, bot.Location(), formatQuietWindows(windows)))
+		return
+	}
+
+	var windows []telegram_bot.QuietWindow
+	if !(len(args) == 2 && args[1] == "off") {
+		for _, arg := range args[1:] {
+			w, err := telegram_bot.ParseQuietWindow(arg)
+			if err != nil {
+				a.reply(msg.Chat.ID, err.Error())
+				return
+			}
+			windows = append(windows, w)
+		}
+	}
+
+	if err := a.services.TelegramBots.SetQuietHours(ctx, bot.ID, windows); err != nil {
+		a.logger.Error("failed to set quiet hours", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to save quiet hours")
+		return
+	}
+	a.reply(msg.Chat.ID, fmt.Sprintf("Quiet hours of @%s: %s", bot.Username, formatQuietWindows(windows)))
 }
 
-func (a *AdminBotHandler) handleNewBot(msg *tgbotapi.Message) {
-}
\ No newline at end of file
+// handleBotTimezone sets the timezone used for users of the bot whose own
+// timezone is unknown: /bot_timezone @bot Europe/Moscow.
+func (a *AdminBotHandler) handleBotTimezone(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		a.reply(msg.Chat.ID, "Usage: /bot_timezone @bot Europe/Moscow")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	if err := a.services.TelegramBots.SetTimezone(ctx, bot, args[1]); err != nil {
+		a.reply(msg.Chat.ID, err.Error())
+		return
+	}
+	a.reply(msg.Chat.ID, fmt.Sprintf("Timezone of @%s set to %s", bot.Username, bot.Timezone))
+}
+
+// handleAttr shows a user's attributes and tags on a bot or changes them:
+// /attr @bot <telegram id|@username> [key=value ...], where key= deletes the
+// attribute.
+func (a *AdminBotHandler) handleAttr(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 2 {
+		a.reply(msg.Chat.ID, "Usage: /attr @bot <telegram id|@username> [key=value ...]")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+	target, ok := a.targetUser(ctx, msg, args[1])
+	if !ok {
+		return
+	}
+
+	for _, arg := range args[2:] {
+		key, value, found := strings.Cut(arg, "=")
+		if !found {
+			a.reply(msg.Chat.ID, fmt.Sprintf("Expected key=value, got %q", arg))
+			return
+		}
+		var err error
+		if value == "" {
+			err = a.services.Users.DeleteAttribute(ctx, target.ID, bot.ID, key)
+		} else {
+			err = a.services.Users.SetAttribute(ctx, target.ID, bot.ID, key, value)
+		}
+		if err != nil {
+			a.reply(msg.Chat.ID, err.Error())
+			return
+		}
+	}
+	a.replyAttributes(ctx, msg.Chat.ID, bot, target)
+}
+
+// handleTag adds or, with a leading minus, removes tags of a user on a bot:
+// /tag @bot <telegram id|@username> vip -churned.
+func (a *AdminBotHandler) handleTag(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 3 {
+		a.reply(msg.Chat.ID, "Usage: /tag @bot <telegram id|@username> tag [-tag ...]")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+	target, ok := a.targetUser(ctx, msg, args[1])
+	if !ok {
+		return
+	}
+
+	for _, arg := range args[2:] {
+		var err error
+		if tag, found := strings.CutPrefix(arg, "-"); found {
+			err = a.services.Users.RemoveTag(ctx, target.ID, bot.ID, tag)
+		} else {
+			err = a.services.Users.AddTag(ctx, target.ID, bot.ID, arg)
+		}
+		if err != nil {
+			a.reply(msg.Chat.ID, err.Error())
+			return
+		}
+	}
+	a.replyAttributes(ctx, msg.Chat.ID, bot, target)
+}
+
+// segmentPreviewSize is how many users /segment lists.
+const segmentPreviewSize = 20
+
+// handleSegment counts and lists the users of a bot in a segment:
+// /segment @bot tag:vip -tag:churned interested_in=course_b.
+func (a *AdminBotHandler) handleSegment(ctx context.Context, msg *tgbotapi.Message) {
+	username, filter, _ := strings.Cut(strings.TrimSpace(msg.CommandArguments()), " ")
+	if username == "" {
+		a.reply(msg.Chat.ID, "Usage: /segment @bot [tag:name] [-tag:name] [key=value] ...")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, username)
+	if !ok {
+		return
+	}
+	segment, err := user.ParseSegment(filter)
+	if err != nil {
+		a.reply(msg.Chat.ID, err.Error())
+		return
+	}
+
+	users, total, err := a.services.Users.Segment(ctx, bot.ID, segment, segmentPreviewSize, 0)
+	if err != nil {
+		a.logger.Error("failed to list segment", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to list users")
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Users of @%s matching %s: %d", bot.Username, segment, total)
+	for _, u := range users {
+		fmt.Fprintf(&b, "\n%s", formatUser(u))
+	}
+	if total > int64(len(users)) {
+		fmt.Fprintf(&b, "\n…and %d more", total-int64(len(users)))
+	}
+	a.reply(msg.Chat.ID, b.String())
+}
+
+// handleLink maps a deep link payload to a script and an acquisition source:
+// /link @bot <payload> <script name> [source], or /link @bot <payload> off.
+func (a *AdminBotHandler) handleLink(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 3 || len(args) > 4 {
+		a.reply(msg.Chat.ID, "Usage: /link @bot <payload> <script name> [source] or /link @bot <payload> off")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	payload := args[1]
+	if len(args) == 3 && args[2] == "off" {
+		err := a.services.Scripts.DeleteDeepLink(ctx, bot.ID, payload)
+		switch {
+		case err == nil:
+			a.reply(msg.Chat.ID, fmt.Sprintf("Deep link %s of @%s removed", payload, bot.Username))
+		case errors.Is(err, app_errors.ErrNotFound):
+			a.reply(msg.Chat.ID, "Deep link not found")
+		default:
+			a.logger.Error("failed to delete deep link", zap.Error(err))
+			a.reply(msg.Chat.ID, "Failed to remove deep link")
+		}
+		return
+	}
+
+	var source string
+	if len(args) == 4 {
+		source = args[3]
+	}
+	link, err := a.services.Scripts.SaveDeepLink(ctx, bot, payload, args[2], source)
+	switch {
+	case err == nil:
+		a.reply(msg.Chat.ID, fmt.Sprintf("%s\n%s", link.URL(bot.Username), formatDeepLink(link, args[2])))
+	case errors.Is(err, script.ErrInvalidPayload):
+		a.reply(msg.Chat.ID, err.Error())
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script not found")
+	default:
+		a.logger.Error("failed to save deep link", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to save deep link")
+	}
+}
+
+// handleLinks lists the deep links of a bot: /links @bot.
+func (a *AdminBotHandler) handleLinks(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 1 {
+		a.reply(msg.Chat.ID, "Usage: /links @bot")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	links, err := a.services.Scripts.DeepLinks(ctx, bot.ID)
+	if err != nil {
+		a.logger.Error("failed to list deep links", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to list deep links")
+		return
+	}
+	if len(links) == 0 {
+		a.reply(msg.Chat.ID, fmt.Sprintf("@%s has no deep links", bot.Username))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Deep links of @%s:", bot.Username)
+	for _, link := range links {
+		scriptName := link.ScriptID.String()
+		if sc, err := a.services.Scripts.Get(ctx, link.ScriptID); err == nil {
+			scriptName = sc.Name
+		}
+		fmt.Fprintf(&b, "\n%s\n%s", link.URL(bot.Username), formatDeepLink(link, scriptName))
+	}
+	a.reply(msg.Chat.ID, b.String())
+}
+
+// handleGroup links a script to a private group whose invite links its steps
+// hand out: /group @bot <script name> <chat id>, or /group @bot <script name> off.
+// The bot has to be an administrator of the group.
+func (a *AdminBotHandler) handleGroup(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 3 {
+		a.reply(msg.Chat.ID, "Usage: /group @bot <script name> <chat id> or /group @bot <script name> off")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	if args[2] == "off" {
+		_, err := a.services.Scripts.SetPrivateGroup(ctx, bot, args[1], nil)
+		switch {
+		case err == nil:
+			a.reply(msg.Chat.ID, fmt.Sprintf("Script %s no longer grants group access", args[1]))
+		case errors.Is(err, app_errors.ErrNotFound):
+			a.reply(msg.Chat.ID, "Script not found")
+		default:
+			a.logger.Error("failed to unlink private group", zap.Error(err))
+			a.reply(msg.Chat.ID, "Failed to unlink group")
+		}
+		return
+	}
+
+	chatID, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		a.reply(msg.Chat.ID, "Chat id must be a number, e.g. -1001234567890")
+		return
+	}
+	g, err := a.services.Groups.Register(ctx, bot, chatID)
+	if err != nil {
+		a.logger.Warn("failed to register private group", zap.Int64("chat_id", chatID), zap.Error(err))
+		a.reply(msg.Chat.ID, fmt.Sprintf("@%s cannot access chat %d, add it to the group as an administrator", bot.Username, chatID))
+		return
+	}
+
+	_, err = a.services.Scripts.SetPrivateGroup(ctx, bot, args[1], &g.ID)
+	switch {
+	case err == nil:
+		a.reply(msg.Chat.ID, fmt.Sprintf("Script %s grants access to %s", args[1], g.Title))
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script not found")
+	default:
+		a.logger.Error("failed to link private group", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to link group")
+	}
+}
+
+// handleGroupPolicy sets who gets into a private group by join request:
+// /group_policy @bot <chat id> queue|decline [access tag]. Users who reached
+// a step granting access, or have the tag, are approved; the rest wait until
+// they qualify or are declined.
+func (a *AdminBotHandler) handleGroupPolicy(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 3 || len(args) > 4 || (args[2] != "queue" && args[2] != "decline") {
+		a.reply(msg.Chat.ID, "Usage: /group_policy @bot <chat id> queue|decline [access tag]")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+	chatID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		a.reply(msg.Chat.ID, "Chat id must be a number, e.g. -1001234567890")
+		return
+	}
+	var accessTag string
+	if len(args) == 4 {
+		accessTag = args[3]
+	}
+
+	g, err := a.services.Groups.SetPolicy(ctx, bot, chatID, accessTag, args[2] == "decline")
+	switch {
+	case err == nil:
+		a.reply(msg.Chat.ID, formatGroupPolicy(g))
+	case errors.Is(err, user.ErrInvalidKey):
+		a.reply(msg.Chat.ID, err.Error())
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Group not found, link it to a script with /group first")
+	default:
+		a.logger.Error("failed to set group policy", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to set group policy")
+	}
+}
+
+// handleVersions lists the versions of a script: /versions @bot <script name>.
+func (a *AdminBotHandler) handleVersions(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		a.reply(msg.Chat.ID, "Usage: /versions @bot <script name>")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	sc, versions, err := a.services.Scripts.Versions(ctx, bot, args[1])
+	switch {
+	case err == nil:
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script not found")
+		return
+	default:
+		a.logger.Error("failed to list script versions", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to list versions")
+		return
+	}
+	if len(versions) == 0 {
+		a.reply(msg.Chat.ID, fmt.Sprintf("Script %s has no versions", sc.Name))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Versions of %s:", sc.Name)
+	for _, v := range versions {
+		b.WriteString("\n" + formatVersion(v, sc))
+	}
+	a.reply(msg.Chat.ID, b.String())
+}
+
+// handleDraft opens the draft of a script, copying the published version
+// when there is no draft yet: /draft @bot <script name>.
+func (a *AdminBotHandler) handleDraft(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		a.reply(msg.Chat.ID, "Usage: /draft @bot <script name>")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	draft, err := a.services.Scripts.DraftByName(ctx, bot, args[1])
+	switch {
+	case err == nil:
+		a.reply(msg.Chat.ID, fmt.Sprintf("Draft of %s is version %d, publish it with /publish @%s %s", args[1], draft.Number, bot.Username, args[1]))
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script not found")
+	default:
+		a.logger.Error("failed to open script draft", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to open draft")
+	}
+}
+
+// handlePublish publishes the draft of a script to new users:
+// /publish @bot <script name>. Users already in the script stay on their
+// version.
+func (a *AdminBotHandler) handlePublish(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		a.reply(msg.Chat.ID, "Usage: /publish @bot <script name>")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	v, err := a.services.Scripts.Publish(ctx, bot, args[1])
+	switch {
+	case err == nil:
+		a.reply(msg.Chat.ID, fmt.Sprintf("Version %d of %s is published", v.Number, args[1]))
+	case errors.Is(err, script.ErrNoDraft), errors.Is(err, script.ErrNoSteps), errors.Is(err, script.ErrInvalidScript):
+		a.reply(msg.Chat.ID, err.Error())
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script not found")
+	default:
+		a.logger.Error("failed to publish script", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to publish")
+	}
+}
+
+// handleRollback makes an earlier published version the one new users get:
+// /rollback @bot <script name> <version>.
+func (a *AdminBotHandler) handleRollback(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 3 {
+		a.reply(msg.Chat.ID, "Usage: /rollback @bot <script name> <version>")
+		return
+	}
+	number, err := strconv.Atoi(args[2])
+	if err != nil || number < 1 {
+		a.reply(msg.Chat.ID, "Version must be a positive number, see /versions")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	v, err := a.services.Scripts.Rollback(ctx, bot, args[1], number)
+	switch {
+	case err == nil:
+		a.reply(msg.Chat.ID, fmt.Sprintf("New users of %s get version %d", args[1], v.Number))
+	case errors.Is(err, script.ErrDraftVersion):
+		a.reply(msg.Chat.ID, err.Error())
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script or version not found")
+	default:
+		a.logger.Error("failed to roll back script", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to roll back")
+	}
+}
+
+// handlePreview runs a version of a script for the sender with compressed
+// delays: /preview @bot <script name> [version]. The draft is previewed by
+// default, or the published version when there is no draft.
+func (a *AdminBotHandler) handlePreview(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 2 || len(args) > 3 {
+		a.reply(msg.Chat.ID, "Usage: /preview @bot <script name> [version]")
+		return
+	}
+	var number int
+	if len(args) == 3 {
+		var err error
+		if number, err = strconv.Atoi(args[2]); err != nil || number < 1 {
+			a.reply(msg.Chat.ID, "Version must be a positive number, see /versions")
+			return
+		}
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+	u, err := a.services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		a.logger.Error("failed to register user", zap.Error(err))
+		return
+	}
+
+	_, v, err := a.services.Scripts.StartPreview(ctx, bot, args[1], u, number)
+	switch {
+	case err == nil:
+		a.reply(msg.Chat.ID, fmt.Sprintf("Previewing version %d of %s in @%s, /start the bot first if you have not", v.Number, args[1], bot.Username))
+	case errors.Is(err, script.ErrNoSteps), errors.Is(err, script.ErrNotPublished):
+		a.reply(msg.Chat.ID, err.Error())
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script or version not found")
+	default:
+		a.logger.Error("failed to start script preview", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to start preview")
+	}
+}
+
+// handleAB reports the conversions of the A/B test variants of a script:
+// /ab @bot <script name> [version]. The published version is reported by
+// default.
+func (a *AdminBotHandler) handleAB(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 2 || len(args) > 3 {
+		a.reply(msg.Chat.ID, "Usage: /ab @bot <script name> [version]")
+		return
+	}
+	var number int
+	if len(args) == 3 {
+		var err error
+		if number, err = strconv.Atoi(args[2]); err != nil || number < 1 {
+			a.reply(msg.Chat.ID, "Version must be a positive number, see /versions")
+			return
+		}
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	v, stats, err := a.services.Scripts.VariantStats(ctx, bot, args[1], number)
+	switch {
+	case err == nil:
+	case errors.Is(err, script.ErrNotPublished):
+		a.reply(msg.Chat.ID, "Script has no published version, pass the version number")
+		return
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script or version not found")
+		return
+	default:
+		a.logger.Error("failed to get script variant stats", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to get variant stats")
+		return
+	}
+	if len(stats) == 0 {
+		a.reply(msg.Chat.ID, fmt.Sprintf("Version %d of %s has no A/B variants", v.Number, args[1]))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Variants of %s, version %d:", args[1], v.Number)
+	for _, s := range stats {
+		b.WriteString("\n" + formatVariantStats(s))
+	}
+	a.reply(msg.Chat.ID, b.String())
+}
+
+// handleStats reports the step funnel of a script:
+// /stats @bot <script name> [from=YYYY-MM-DD] [to=YYYY-MM-DD] [source=<source>] [version=N].
+// Dates are in UTC and both ends are inclusive; the published version is
+// reported by default.
+func (a *AdminBotHandler) handleStats(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 2 {
+		a.reply(msg.Chat.ID, "Usage: /stats @bot <script name> [from=YYYY-MM-DD] [to=YYYY-MM-DD] [source=<source>] [version=N]")
+		return
+	}
+	opts, err := keyValueArgs(args[2:], funnelOptions)
+	if err != nil {
+		a.reply(msg.Chat.ID, err.Error())
+		return
+	}
+	filter, number, err := parseFunnelOptions(func(key string) string { return opts[key] })
+	if err != nil {
+		a.reply(msg.Chat.ID, err.Error())
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	v, funnel, err := a.services.Scripts.Funnel(ctx, bot, args[1], number, filter)
+	switch {
+	case err == nil:
+	case errors.Is(err, script.ErrNotPublished):
+		a.reply(msg.Chat.ID, "Script has no published version, pass the version number")
+		return
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script or version not found")
+		return
+	default:
+		a.logger.Error("failed to build script funnel", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to build funnel")
+		return
+	}
+	if len(funnel) == 0 {
+		a.reply(msg.Chat.ID, fmt.Sprintf("Version %d of %s has no steps", v.Number, args[1]))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Funnel of %s, version %d:", args[1], v.Number)
+	for _, f := range funnel {
+		b.WriteString("\n" + formatStepFunnel(f))
+	}
+	a.reply(msg.Chat.ID, b.String())
+}
+
+// handleExport sends a version of a script as a bundle file:
+// /export @bot <script name> [yaml|json|zip] [version]. The published
+// version is exported by default; zip archives carry the media files.
+func (a *AdminBotHandler) handleExport(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 2 || len(args) > 4 {
+		a.reply(msg.Chat.ID, "Usage: /export @bot <script name> [yaml|json|zip] [version]")
+		return
+	}
+	encoding := bundle.EncodingYAML
+	if len(args) >= 3 {
+		var err error
+		if encoding, err = bundle.EncodingOf(args[2]); err != nil {
+			a.reply(msg.Chat.ID, "Format must be yaml, json or zip")
+			return
+		}
+	}
+	var number int
+	if len(args) == 4 {
+		var err error
+		if number, err = strconv.Atoi(args[3]); err != nil || number < 1 {
+			a.reply(msg.Chat.ID, "Version must be a positive number, see /versions")
+			return
+		}
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	data, err := a.services.Bundles.Export(ctx, bot, args[1], number, encoding)
+	switch {
+	case err == nil:
+	case errors.Is(err, script.ErrNotPublished):
+		a.reply(msg.Chat.ID, "Script has no published version, pass the draft version number")
+		return
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script, version or media file not found")
+		return
+	default:
+		a.logger.Error("failed to export script", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to export script")
+		return
+	}
+
+	doc := tgbotapi.NewDocument(msg.Chat.ID, tgbotapi.FileBytes{Name: args[1] + "." + encoding, Bytes: data})
+	if _, err := a.bot.Send(doc); err != nil {
+		a.logger.Error("failed to send script bundle", zap.Error(err))
+	}
+}
+
+// handleImport loads a bundle file into the draft of a script:
+// /import @bot [script name], sent as the caption of the file or as a reply
+// to it. The script is created when the bot has none with that name.
+func (a *AdminBotHandler) handleImport(ctx context.Context, msg *tgbotapi.Message, arguments string, doc *tgbotapi.Document) {
+	args := strings.Fields(arguments)
+	if len(args) < 1 || len(args) > 2 || doc == nil {
+		a.reply(msg.Chat.ID, "Usage: send a .yaml, .json or .zip bundle with the caption /import @bot [script name], or reply to it with that command")
+		return
+	}
+	encoding, err := bundle.EncodingOf(doc.FileName)
+	if err != nil {
+		a.reply(msg.Chat.ID, err.Error())
+		return
+	}
+	if doc.FileSize > maxBundleSize {
+		a.reply(msg.Chat.ID, fmt.Sprintf("Bundle must be smaller than %d MB", maxBundleSize>>20))
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+	var scriptName string
+	if len(args) == 2 {
+		scriptName = args[1]
+	}
+
+	data, err := a.download(ctx, doc.FileID)
+	if err != nil {
+		a.logger.Error("failed to download script bundle", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to download the bundle")
+		return
+	}
+
+	b, v, err := a.services.Bundles.Import(ctx, bot, data, encoding, scriptName)
+	switch {
+	case err == nil:
+		a.reply(msg.Chat.ID, fmt.Sprintf("Imported %d steps into draft %d of %s, publish it with /publish @%s %s",
+			len(b.Steps), v.Number, b.Script, bot.Username, b.Script))
+	case errors.Is(err, bundle.ErrInvalidBundle), errors.Is(err, bundle.ErrUnsupportedFormat), errors.Is(err, bundle.ErrMissingMedia),
+		errors.Is(err, script.ErrInvalidScript), errors.Is(err, script.ErrNoSteps):
+		a.reply(msg.Chat.ID, err.Error())
+	default:
+		a.logger.Error("failed to import script", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to import script")
+	}
+}
+
+// captionCommand splits a caption like "/import@admin_bot @bot name" into
+// the command and its arguments. Commands addressed to other bots are
+// ignored.
+func captionCommand(caption, self string) (string, string) {
+	if !strings.HasPrefix(caption, "/") {
+		return "", ""
+	}
+	head, args, _ := strings.Cut(caption[1:], " ")
+	cmd, mention, ok := strings.Cut(head, "@")
+	if ok && !strings.EqualFold(mention, self) {
+		return "", ""
+	}
+	return cmd, args
+}
+
+// maxBundleSize is the largest file the Bot API lets bots download.
+const maxBundleSize = 20 << 20
+
+// download fetches a file sent to the admin bot.
+func (a *AdminBotHandler) download(ctx context.Context, fileID string) ([]byte, error) {
+	url, err := a.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file url: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.bot.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBundleSize))
+}
+
+func formatVersion(v *script.Version, sc *script.Script) string {
+	switch {
+	case v.IsDraft():
+		return fmt.Sprintf("%d: draft", v.Number)
+	case sc.PublishedVersionID != nil && *sc.PublishedVersionID == v.ID:
+		return fmt.Sprintf("%d: live, published %s", v.Number, v.PublishedAt.Format(time.DateTime))
+	default:
+		return fmt.Sprintf("%d: published %s", v.Number, v.PublishedAt.Format(time.DateTime))
+	}
+}
+
+func formatVariantStats(s script.VariantStats) string {
+	return fmt.Sprintf("step %d %s (weight %d): %d sent, %d clicked (%s), %d reached next (%s)",
+		s.StepOrder, s.Name, s.Weight, s.Delivered, s.Clicked, percent(s.Clicked, s.Delivered), s.Advanced, percent(s.Advanced, s.Delivered))
+}
+
+// percent formats part as a share of total.
+func percent(part, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(part)*100/float64(total))
+}
+
+func formatGroupPolicy(g *group.Group) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Join requests to %s are approved for users who reached an access step", g.Title)
+	if g.AccessTag != "" {
+		fmt.Fprintf(&b, " or have the tag %s", g.AccessTag)
+	}
+	if g.DeclineUnqualified {
+		b.WriteString(", others are declined")
+	} else {
+		b.WriteString(", others wait until they qualify")
+	}
+	return b.String()
+}
+
+func formatDeepLink(link *script.DeepLink, scriptName string) string {
+	source := link.Source
+	if source == "" {
+		source = "none"
+	}
+	return fmt.Sprintf("script: %s, source: %s", scriptName, source)
+}
+
+func (a *AdminBotHandler) replyAttributes(ctx context.Context, chatID int64, bot *telegram_bot.TelegramBot, target *user.User) {
+	attrs, err := a.services.Users.Attributes(ctx, target.ID, bot.ID)
+	if err != nil {
+		a.logger.Error("failed to get user attributes", zap.Error(err))
+		a.reply(chatID, "Failed to get attributes")
+		return
+	}
+
+	keys := make([]string, 0, len(attrs.Values))
+	for key := range attrs.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s on @%s", formatUser(target), bot.Username)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n%s = %s", key, attrs.Values[key])
+	}
+	if tags := attrs.SortedTags(); len(tags) > 0 {
+		fmt.Fprintf(&b, "\ntags: %s", strings.Join(tags, ", "))
+	}
+	if len(keys) == 0 && len(attrs.Tags) == 0 {
+		b.WriteString("\nno attributes or tags")
+	}
+	a.reply(chatID, b.String())
+}
+
+// targetUser resolves the user an admin command refers to and replies when
+// there is none.
+func (a *AdminBotHandler) targetUser(ctx context.Context, msg *tgbotapi.Message, ref string) (*user.User, bool) {
+	u, err := a.services.Users.Find(ctx, ref)
+	switch {
+	case err == nil:
+		return u, true
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "User not found")
+	default:
+		a.logger.Error("failed to find user", zap.Error(err))
+	}
+	return nil, false
+}
+
+// managedBot resolves a bot the sender owns or administers and replies with
+// the reason when it cannot.
+func (a *AdminBotHandler) managedBot(ctx context.Context, msg *tgbotapi.Message, username string) (*telegram_bot.TelegramBot, bool) {
+	u, err := a.services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		a.logger.Error("failed to register user", zap.Error(err))
+		return nil, false
+	}
+
+	bot, err := a.services.TelegramBots.GetManaged(ctx, username, u.ID)
+	switch {
+	case err == nil:
+		return bot, true
+	case errors.Is(err, app_errors.ErrNotFound), errors.Is(err, telegram_bot.ErrNotManager):
+		a.reply(msg.Chat.ID, "Bot not found")
+	default:
+		a.logger.Error("failed to get managed bot", zap.Error(err))
+	}
+	return nil, false
+}
+
+func formatUser(u *user.User) string {
+	if u.Username != "" {
+		return fmt.Sprintf("@%s (%d)", u.Username, u.TelegramID)
+	}
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name == "" {
+		return strconv.FormatInt(u.TelegramID, 10)
+	}
+	return fmt.Sprintf("%s (%d)", name, u.TelegramID)
+}
+
+func formatQuietWindows(windows []telegram_bot.QuietWindow) string {
+	if len(windows) == 0 {
+		return "off"
+	}
+	parts := make([]string, 0, len(windows))
+	for _, w := range windows {
+		parts = append(parts, w.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (a *AdminBotHandler) reply(chatID int64, text string) {
+	m := tgbotapi.NewMessage(chatID, text)
+	if _, err := a.bot.Send(m); err != nil {
+		a.logger.Error("failed to send reply", zap.Error(err))
+	}
+}
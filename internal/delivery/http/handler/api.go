@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/VladKovDev/promo-bot/internal/delivery/http/dto"
+	"github.com/VladKovDev/promo-bot/internal/delivery/http/middleware"
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxRequestBody caps the size of API request bodies.
+const maxRequestBody = 1 << 20
+
+// APIHandler serves the HTTP API used to manage bots from outside Telegram.
+type APIHandler struct {
+	services Services
+	token    string
+	logger   logger.Logger
+}
+
+func NewAPIHandler(services Services, token string, logger logger.Logger) *APIHandler {
+	return &APIHandler{
+		services: services,
+		token:    token,
+		logger:   logger,
+	}
+}
+
+// Routes returns the API routes behind bearer token authentication and the
+// public redirect endpoint of URL buttons.
+func (h *APIHandler) Routes() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("POST /api/bots/{bot}/scripts/{script}/preview", h.handlePreview)
+	api.HandleFunc("GET /api/bots/{bot}/scripts/{script}/funnel", h.handleFunnel)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", middleware.BearerToken(h.token, api))
+	mux.HandleFunc("GET "+message.RedirectPath+"{token}", h.handleRedirect)
+	return mux
+}
+
+// handlePreview starts a preview run of a script for a user who has started
+// the bot, see script.Service.StartPreview. Previews only go to owners and
+// admins of the bot.
+func (h *APIHandler) handlePreview(w http.ResponseWriter, r *http.Request) {
+	var req dto.PreviewRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.TelegramID == 0 || req.Version < 0 {
+		writeError(w, http.StatusBadRequest, "telegram_id is required and version must not be negative")
+		return
+	}
+
+	ctx := r.Context()
+	u, err := h.services.Users.Find(ctx, strconv.FormatInt(req.TelegramID, 10))
+	if err != nil {
+		h.fail(w, "failed to find user", "user not found", err)
+		return
+	}
+	bot, err := h.services.TelegramBots.GetManaged(ctx, r.PathValue("bot"), u.ID)
+	if errors.Is(err, telegram_bot.ErrNotManager) {
+		writeError(w, http.StatusForbidden, "user is not an owner or admin of the bot")
+		return
+	}
+	if err != nil {
+		h.fail(w, "failed to get bot", "bot not found", err)
+		return
+	}
+
+	p, v, err := h.services.Scripts.StartPreview(ctx, bot, r.PathValue("script"), u, req.Version)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusCreated, dto.PreviewResponse{ProgressID: p.ID, Version: v.Number})
+	case errors.Is(err, script.ErrNoSteps), errors.Is(err, script.ErrNotPublished):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		h.fail(w, "failed to start script preview", "script or version not found", err)
+	}
+}
+
+// handleFunnel reports the step funnel of a script, filtered by the query
+// parameters from, to, source and version, see parseFunnelOptions.
+func (h *APIHandler) handleFunnel(w http.ResponseWriter, r *http.Request) {
+	filter, number, err := parseFunnelOptions(r.URL.Query().Get)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	bot, err := h.services.TelegramBots.GetByUsername(ctx, r.PathValue("bot"))
+	if err != nil {
+		h.fail(w, "failed to get bot", "bot not found", err)
+		return
+	}
+
+	name := r.PathValue("script")
+	v, funnel, err := h.services.Scripts.Funnel(ctx, bot, name, number, filter)
+	switch {
+	case err == nil:
+	case errors.Is(err, script.ErrNotPublished):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	default:
+		h.fail(w, "failed to build script funnel", "script or version not found", err)
+		return
+	}
+
+	resp := dto.Funnel{Script: name, Version: v.Number, Steps: make([]dto.FunnelStep, 0, len(funnel))}
+	for _, f := range funnel {
+		resp.Steps = append(resp.Steps, dto.FunnelStep{
+			Order:         f.Order,
+			Reached:       f.Reached,
+			Sent:          f.Sent,
+			Clicked:       f.Clicked,
+			Completed:     f.Completed,
+			DropOff:       f.DropOff(),
+			DropOffRate:   f.DropOffRate(),
+			MedianSeconds: f.MedianTime.Seconds(),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleRedirect records the click of a URL button and redirects to its URL,
+// see message.Service.Follow.
+func (h *APIHandler) handleRedirect(w http.ResponseWriter, r *http.Request) {
+	target, err := h.services.Messages.Follow(r.Context(), r.PathValue("token"))
+	switch {
+	case err == nil:
+	case target != "":
+		h.logger.Error("failed to record button click", zap.Error(err))
+	case errors.Is(err, message.ErrInvalidRedirect), errors.Is(err, app_errors.ErrNotFound):
+		http.NotFound(w, r)
+		return
+	default:
+		h.logger.Error("failed to follow button redirect", zap.Error(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// fail answers 404 with notFound for app_errors.ErrNotFound and logs other
+// errors as msg before answering 500.
+func (h *APIHandler) fail(w http.ResponseWriter, msg, notFound string, err error) {
+	if errors.Is(err, app_errors.ErrNotFound) {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	h.logger.Error(msg, zap.Error(err))
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, dto.Error{Error: msg})
+}
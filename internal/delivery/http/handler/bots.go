@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// botCallbackPrefix marks the confirmation buttons of /disable, /enable and
+// /delete, followed by the action and the bot ID.
+const botCallbackPrefix = "bot:"
+
+const (
+	botDisable = "disable"
+	botEnable  = "enable"
+	botDelete  = "delete"
+	botCancel  = "cancel"
+)
+
+// handleBots lists the bots the caller owns with their status: /bots.
+func (a *AdminBotHandler) handleBots(ctx context.Context, msg *tgbotapi.Message) {
+	u, err := a.services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		a.logger.Error("failed to register user", zap.Error(err))
+		return
+	}
+
+	bots, err := a.services.TelegramBots.ListOwned(ctx, u.ID)
+	if err != nil {
+		a.logger.Error("failed to list owned bots", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to list bots")
+		return
+	}
+	if len(bots) == 0 {
+		a.reply(msg.Chat.ID, "You own no bots")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("Your bots:")
+	for _, bot := range bots {
+		fmt.Fprintf(&b, "\n@%s: %s", bot.Username, botStatus(bot))
+	}
+	a.reply(msg.Chat.ID, b.String())
+}
+
+// botStatus describes the state of a bot from its registry marks and the
+// outcome of the last connection to it.
+func botStatus(bot *telegram_bot.TelegramBot) string {
+	switch {
+	case bot.RevokedAt != nil:
+		return "token revoked " + bot.RevokedAt.Format(time.DateTime)
+	case bot.DisabledAt != nil:
+		return "disabled " + bot.DisabledAt.Format(time.DateTime)
+	case bot.LastCheckedAt == nil:
+		return "not started yet"
+	case bot.LastError != "":
+		return fmt.Sprintf("failing since %s: %s", bot.LastCheckedAt.Format(time.DateTime), bot.LastError)
+	default:
+		return "running since " + bot.LastCheckedAt.Format(time.DateTime)
+	}
+}
+
+// handleBotAction asks the owner to confirm disabling, enabling or deleting
+// a bot: /disable @bot, /enable @bot, /delete @bot.
+func (a *AdminBotHandler) handleBotAction(ctx context.Context, msg *tgbotapi.Message, action string) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 1 {
+		a.reply(msg.Chat.ID, fmt.Sprintf("Usage: /%s @bot", action))
+		return
+	}
+
+	u, err := a.services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		a.logger.Error("failed to register user", zap.Error(err))
+		return
+	}
+	bot, err := a.services.TelegramBots.GetOwned(ctx, args[0], u.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, app_errors.ErrNotFound), errors.Is(err, telegram_bot.ErrNotOwner):
+		a.reply(msg.Chat.ID, "Bot not found")
+		return
+	default:
+		a.logger.Error("failed to get owned bot", zap.Error(err))
+		return
+	}
+
+	question := map[string]string{
+		botDisable: "Disable @%s? It stops answering users and sending scheduled steps until enabled again.",
+		botEnable:  "Enable @%s?",
+		botDelete:  "Delete @%s with all its scripts, users' data and settings? This cannot be undone.",
+	}[action]
+	m := tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf(question, bot.Username))
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("Yes, %s it", action), botCallbackPrefix+action+":"+bot.ID.String()),
+		tgbotapi.NewInlineKeyboardButtonData("Cancel", botCallbackPrefix+botCancel),
+	))
+	if _, err := a.bot.Send(m); err != nil {
+		a.logger.Error("failed to send confirmation", zap.Error(err))
+	}
+}
+
+// handleBotCallback carries out a confirmed bot action. Ownership is checked
+// again for the user who pressed the button.
+func (a *AdminBotHandler) handleBotCallback(ctx context.Context, cb *tgbotapi.CallbackQuery) {
+	answer := ""
+	defer func() {
+		if _, err := a.bot.Request(tgbotapi.NewCallback(cb.ID, answer)); err != nil {
+			a.logger.Warn("failed to answer callback query", zap.Error(err))
+		}
+	}()
+
+	if cb.Message == nil || cb.From == nil {
+		return
+	}
+	action, arg, _ := strings.Cut(strings.TrimPrefix(cb.Data, botCallbackPrefix), ":")
+	if action == botCancel {
+		a.editConfirmation(cb.Message, "Cancelled")
+		return
+	}
+	id, err := uuid.Parse(arg)
+	if err != nil {
+		return
+	}
+
+	u, err := a.services.Users.Register(ctx, userFromTelegram(cb.From))
+	if err != nil {
+		a.logger.Error("failed to register user", zap.Error(err))
+		answer = "Failed, try again"
+		return
+	}
+
+	var bot *telegram_bot.TelegramBot
+	switch action {
+	case botDisable:
+		bot, err = a.services.TelegramBots.Disable(ctx, id, u.ID)
+	case botEnable:
+		bot, err = a.services.TelegramBots.Enable(ctx, id, u.ID)
+	case botDelete:
+		bot, err = a.services.TelegramBots.Delete(ctx, id, u.ID)
+	default:
+		return
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, telegram_bot.ErrAdminBot):
+		answer = "The admin bot cannot be disabled or deleted"
+		return
+	case errors.Is(err, app_errors.ErrNotFound), errors.Is(err, telegram_bot.ErrNotOwner):
+		answer = "Bot not found"
+		return
+	default:
+		a.logger.Error("failed to change bot", zap.String("action", action), zap.Error(err))
+		answer = fmt.Sprintf("Failed to %s the bot", action)
+		return
+	}
+
+	var text string
+	switch {
+	case action != botEnable:
+		a.services.Runner.StopBot(bot)
+		text = fmt.Sprintf("@%s is %sd", bot.Username, action)
+	case !bot.IsActive():
+		text = fmt.Sprintf("@%s is enabled, but its token is revoked", bot.Username)
+	default:
+		if err := a.services.Runner.RunBot(ctx, bot); err != nil {
+			a.logger.Error("failed to start bot", zap.String("bot_id", bot.ID.String()), zap.Error(err))
+			text = fmt.Sprintf("@%s is enabled, but failed to start: %s", bot.Username, err)
+		} else {
+			text = fmt.Sprintf("@%s is enabled and running", bot.Username)
+		}
+	}
+	a.editConfirmation(cb.Message, text)
+}
+
+// handleAlerts shows, mutes or unmutes the caller's alerts about a bot they
+// own: /alerts @bot [on|off].
+func (a *AdminBotHandler) handleAlerts(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 || len(args) > 2 || (len(args) == 2 && args[1] != "on" && args[1] != "off") {
+		a.reply(msg.Chat.ID, "Usage: /alerts @bot [on|off]")
+		return
+	}
+
+	u, err := a.services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		a.logger.Error("failed to register user", zap.Error(err))
+		return
+	}
+	bot, err := a.services.TelegramBots.GetOwned(ctx, args[0], u.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, app_errors.ErrNotFound), errors.Is(err, telegram_bot.ErrNotOwner):
+		a.reply(msg.Chat.ID, "Bot not found")
+		return
+	default:
+		a.logger.Error("failed to get owned bot", zap.Error(err))
+		return
+	}
+
+	var muted bool
+	if len(args) == 2 {
+		muted = args[1] == "off"
+		err = a.services.Alerts.SetMuted(ctx, bot.ID, u.ID, muted)
+	} else {
+		muted, err = a.services.Alerts.IsMuted(ctx, bot.ID, u.ID)
+	}
+	if err != nil {
+		a.logger.Error("failed to change alerts", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to change alerts")
+		return
+	}
+	if muted {
+		a.reply(msg.Chat.ID, fmt.Sprintf("Alerts about @%s are muted", bot.Username))
+	} else {
+		a.reply(msg.Chat.ID, fmt.Sprintf("Alerts about @%s are on", bot.Username))
+	}
+}
+
+// editConfirmation replaces a confirmation question and its buttons with the
+// outcome.
+func (a *AdminBotHandler) editConfirmation(msg *tgbotapi.Message, text string) {
+	if _, err := a.bot.Request(tgbotapi.NewEditMessageText(msg.Chat.ID, msg.MessageID, text)); err != nil {
+		a.logger.Warn("failed to edit confirmation", zap.Error(err))
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/inbox"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// relayToInbox passes a message the user wrote to the bot, which no script
+// was waiting for, to the bot's admins.
+func (h *BotHandler) relayToInbox(ctx context.Context, msg *tgbotapi.Message) {
+	u, err := h.services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		h.logger.Error("failed to register user", zap.Error(err))
+		return
+	}
+	if err := h.services.Inbox.Receive(ctx, h.record, u, msg.Text); err != nil {
+		h.logger.Error("failed to relay message to inbox", zap.Int64("telegram_id", u.TelegramID), zap.Error(err))
+	}
+}
+
+// handleSupportReply sends an answer to a relayed message in the bot's
+// support group back to the user.
+func (h *BotHandler) handleSupportReply(ctx context.Context, msg *tgbotapi.Message) {
+	if msg.From.IsBot || msg.ReplyToMessage == nil || msg.ReplyToMessage.From == nil || msg.ReplyToMessage.From.ID != h.bot.Self.ID {
+		return
+	}
+	if text, ok := answerInbox(ctx, h.services, h.bot.Self.ID, msg, h.logger); ok {
+		h.reply(msg.Chat.ID, text)
+	}
+}
+
+// handleInboxReply sends an answer to a message relayed through the admin bot
+// back to the user. It reports whether msg answered a relayed message.
+func (a *AdminBotHandler) handleInboxReply(ctx context.Context, msg *tgbotapi.Message) bool {
+	if msg.ReplyToMessage == nil || msg.ReplyToMessage.From == nil || msg.ReplyToMessage.From.ID != a.bot.Self.ID {
+		return false
+	}
+	text, ok := answerInbox(ctx, a.services, a.bot.Self.ID, msg, a.logger)
+	if ok {
+		a.reply(msg.Chat.ID, text)
+	}
+	return ok
+}
+
+// answerInbox sends msg, a reply to a message the bot with Telegram ID
+// relayBotID relayed, to the user who wrote the relayed message. It returns
+// the outcome for the admin and reports whether msg answered a relayed
+// message.
+func answerInbox(ctx context.Context, services Services, relayBotID int64, msg *tgbotapi.Message, log logger.Logger) (string, bool) {
+	admin, err := services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		log.Error("failed to register user", zap.Error(err))
+		return "", false
+	}
+
+	_, err = services.Inbox.Reply(ctx, relayBotID, msg.Chat.ID, msg.ReplyToMessage.MessageID, admin, msg.Text)
+	switch {
+	case err == nil:
+		return "Answer sent", true
+	case errors.Is(err, app_errors.ErrNotFound):
+		return "", false
+	case errors.Is(err, inbox.ErrEmptyAnswer):
+		return "Only text answers can be sent", true
+	default:
+		log.Error("failed to send inbox answer", zap.Int64("chat_id", msg.Chat.ID), zap.Error(err))
+		return "Failed to send the answer", true
+	}
+}
+
+// handleInbox shows or changes where messages users write to a bot are
+// relayed: /inbox @bot [admin|<chat id>].
+func (a *AdminBotHandler) handleInbox(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		a.reply(msg.Chat.ID, "Usage: /inbox @bot [admin|<chat id>]")
+		return
+	}
+
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	if len(args) == 1 {
+		chatID, err := a.services.Inbox.SupportChat(ctx, bot)
+		switch {
+		case err != nil:
+			a.logger.Error("failed to get support chat", zap.Error(err))
+			a.reply(msg.Chat.ID, "Failed to get inbox settings")
+		case chatID == 0:
+			a.reply(msg.Chat.ID, fmt.Sprintf("Messages to @%s are relayed to its owners and admins here", bot.Username))
+		default:
+			a.reply(msg.Chat.ID, fmt.Sprintf("Messages to @%s are relayed to chat %d", bot.Username, chatID))
+		}
+		return
+	}
+
+	var chatID int64
+	if args[1] != "admin" {
+		var err error
+		chatID, err = strconv.ParseInt(args[1], 10, 64)
+		if err != nil || chatID == 0 {
+			a.reply(msg.Chat.ID, "Chat id must be a number, e.g. -1001234567890")
+			return
+		}
+	}
+	title, err := a.services.Inbox.SetSupportChat(ctx, bot, chatID)
+	switch {
+	case err == nil && chatID == 0:
+		a.reply(msg.Chat.ID, fmt.Sprintf("Messages to @%s are relayed to its owners and admins here", bot.Username))
+	case err == nil:
+		a.reply(msg.Chat.ID, fmt.Sprintf("Messages to @%s are relayed to %s, reply to them there to answer", bot.Username, title))
+	case chatID != 0:
+		a.logger.Warn("failed to set support chat", zap.Int64("chat_id", chatID), zap.Error(err))
+		a.reply(msg.Chat.ID, fmt.Sprintf("@%s cannot access chat %d, add it to the group first", bot.Username, chatID))
+	default:
+		a.logger.Error("failed to set support chat", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to change inbox settings")
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/alert"
+	"github.com/VladKovDev/promo-bot/internal/domain/bundle"
+	"github.com/VladKovDev/promo-bot/internal/domain/group"
+	"github.com/VladKovDev/promo-bot/internal/domain/inbox"
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Services groups the domain services used by bot handlers.
+type Services struct {
+	Users        *user.Service
+	Scripts      *script.Service
+	TelegramBots *telegram_bot.Service
+	Groups       *group.Service
+	Bundles      *bundle.Service
+	Messages     *message.Service
+	Alerts       *alert.Service
+	Inbox        *inbox.Service
+	Runner       BotRunner
+}
+
+// BotRunner starts and stops receiving updates of bots while the app runs.
+type BotRunner interface {
+	RunBot(ctx context.Context, bot *telegram_bot.TelegramBot) error
+	StopBot(bot *telegram_bot.TelegramBot)
+}
+
+// userFromTelegram converts the sender of an update to a domain user.
+func userFromTelegram(from *tgbotapi.User) *user.User {
+	return &user.User{
+		TelegramID:   from.ID,
+		Username:     from.UserName,
+		FirstName:    from.FirstName,
+		LastName:     from.LastName,
+		LanguageCode: from.LanguageCode,
+		IsActive:     true,
+	}
+}
+
+// recoverUpdate is deferred around the handling of one update. It logs a
+// panic and alerts the owners of the bot, so a failing update is skipped
+// instead of stopping the bot.
+func recoverUpdate(ctx context.Context, services Services, bot *telegram_bot.TelegramBot, log logger.Logger) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	log.Error("panic while handling update", zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+
+	if services.Alerts == nil {
+		return
+	}
+	err := services.Alerts.Notify(ctx, alert.Alert{
+		BotID: bot.ID,
+		Kind:  alert.KindPanic,
+		Text:  fmt.Sprintf("@%s crashed while handling an update and skipped it: %v", bot.Username, r),
+	})
+	if err != nil {
+		log.Error("failed to send alert", zap.String("kind", alert.KindPanic), zap.Error(err))
+	}
+}
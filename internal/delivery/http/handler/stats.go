@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+)
+
+// dayLayout is the date format of report filters, days are in UTC.
+const dayLayout = "2006-01-02"
+
+// funnelOptions lists the options of funnel reports.
+var funnelOptions = []string{"from", "to", "source", "version"}
+
+// parseFunnelOptions reads the filter and the version number of a funnel
+// report from the options from=YYYY-MM-DD, to=YYYY-MM-DD (inclusive),
+// source and version.
+func parseFunnelOptions(get func(string) string) (script.FunnelFilter, int, error) {
+	var (
+		filter script.FunnelFilter
+		number int
+		err    error
+	)
+	if v := get("from"); v != "" {
+		if filter.From, err = time.Parse(dayLayout, v); err != nil {
+			return filter, 0, fmt.Errorf("from must be a date like 2026-01-31")
+		}
+	}
+	if v := get("to"); v != "" {
+		if filter.To, err = time.Parse(dayLayout, v); err != nil {
+			return filter, 0, fmt.Errorf("to must be a date like 2026-01-31")
+		}
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, 0, fmt.Errorf("from must not be after to")
+	}
+	filter.Source = get("source")
+	if v := get("version"); v != "" {
+		if number, err = strconv.Atoi(v); err != nil || number < 1 {
+			return filter, 0, fmt.Errorf("version must be a positive number")
+		}
+	}
+	return filter, number, nil
+}
+
+// keyValueArgs splits command arguments of the form key=value, rejecting
+// keys outside allowed.
+func keyValueArgs(args []string, allowed []string) (map[string]string, error) {
+	values := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || !slices.Contains(allowed, key) {
+			return nil, fmt.Errorf("unknown option %q, use %s", arg, strings.Join(allowed, "=, ")+"=")
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func formatStepFunnel(f script.StepFunnel) string {
+	median := "-"
+	if f.MedianTime > 0 {
+		median = f.MedianTime.Round(time.Second).String()
+	}
+	return fmt.Sprintf("%d. reached %d, sent %d, clicked %d, completed %d, drop-off %d (%s), median to next %s",
+		f.Order, f.Reached, f.Sent, f.Clicked, f.Completed, f.DropOff(), percent(int64(f.DropOff()), int64(f.Reached)), median)
+}
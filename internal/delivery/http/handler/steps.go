@@ -0,0 +1,519 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/telegram"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// What a step draft waits for next.
+const (
+	awaitScript  = "script"
+	awaitMessage = "message"
+	awaitDelay   = "delay"
+	awaitButtons = "buttons"
+)
+
+// stepDraft is a step being written in a private chat with the admin bot.
+// The message of the step is sent or forwarded as is, then the delay and
+// the buttons are asked for in turn.
+type stepDraft struct {
+	bot        *telegram_bot.TelegramBot
+	scriptName string
+	content    *tgbotapi.Message
+	delay      time.Duration
+	awaiting   string
+}
+
+// stepMenu is the /steps menu of a chat. Its buttons only work for the admin
+// who opened it and while it is the latest menu of the chat.
+type stepMenu struct {
+	messageID  int
+	telegramID int64
+	bot        *telegram_bot.TelegramBot
+	scriptName string
+}
+
+// stepCallbackPrefix prefixes callback data of /steps menu buttons:
+// step:<action>:<step id>.
+const stepCallbackPrefix = "step:"
+
+// Actions of /steps menu buttons.
+const (
+	stepUp      = "up"
+	stepDown    = "down"
+	stepDelete  = "delete"
+	stepConfirm = "confirm"
+	stepBack    = "back"
+)
+
+// handleAddStep starts writing a new step of a script: /add_step @bot
+// <script name>. A message sent before the command becomes the step's
+// message.
+func (a *AdminBotHandler) handleAddStep(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 || !msg.Chat.IsPrivate() {
+		a.reply(msg.Chat.ID, "Usage: /add_step @bot <script name>, in a private chat with me")
+		return
+	}
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok || !a.checkDraft(ctx, msg.Chat.ID, bot, args[1]) {
+		return
+	}
+
+	d := a.authoring[msg.Chat.ID]
+	if d == nil || d.content == nil {
+		d = &stepDraft{awaiting: awaitMessage}
+		a.authoring[msg.Chat.ID] = d
+	}
+	d.bot, d.scriptName = bot, args[1]
+	if d.content == nil {
+		a.reply(msg.Chat.ID, "Send or forward the message of the step, with its formatting and media, or /cancel")
+		return
+	}
+	d.awaiting = awaitDelay
+	a.reply(msg.Chat.ID, delayPrompt)
+}
+
+const (
+	delayPrompt   = "How long after the previous step should it be sent? For example 0, 30m, 2h or 1d"
+	buttonsPrompt = "Send its buttons, one per line: Text, or Text | https://link for a link. " +
+		"Add | track after a link to count its clicks. " +
+		"Buttons without a link hold the script until one is pressed. Send /skip for none"
+)
+
+// handleAuthoring takes the next part of the step being written in a
+// private chat. A message sent when no step is being written starts one.
+func (a *AdminBotHandler) handleAuthoring(ctx context.Context, msg *tgbotapi.Message) {
+	if !msg.Chat.IsPrivate() {
+		return
+	}
+	d := a.authoring[msg.Chat.ID]
+	if d == nil {
+		if !hasStepContent(msg) {
+			return
+		}
+		a.authoring[msg.Chat.ID] = &stepDraft{content: msg, awaiting: awaitScript}
+		a.reply(msg.Chat.ID, "Which script is this step for? Send @bot <script name>, or /cancel")
+		return
+	}
+
+	switch d.awaiting {
+	case awaitScript:
+		args := strings.Fields(msg.Text)
+		if len(args) != 2 {
+			a.reply(msg.Chat.ID, "Send @bot <script name>, or /cancel")
+			return
+		}
+		bot, ok := a.managedBot(ctx, msg, args[0])
+		if !ok || !a.checkDraft(ctx, msg.Chat.ID, bot, args[1]) {
+			return
+		}
+		d.bot, d.scriptName, d.awaiting = bot, args[1], awaitDelay
+		a.reply(msg.Chat.ID, delayPrompt)
+	case awaitMessage:
+		if !hasStepContent(msg) {
+			a.reply(msg.Chat.ID, "The message of the step needs text or a caption, send it again or /cancel")
+			return
+		}
+		d.content, d.awaiting = msg, awaitDelay
+		a.reply(msg.Chat.ID, delayPrompt)
+	case awaitDelay:
+		delay, err := parseDelay(msg.Text)
+		if err != nil {
+			a.reply(msg.Chat.ID, err.Error())
+			return
+		}
+		d.delay, d.awaiting = delay, awaitButtons
+		a.reply(msg.Chat.ID, buttonsPrompt)
+	case awaitButtons:
+		buttons, err := parseButtons(msg.Text)
+		if err != nil {
+			a.reply(msg.Chat.ID, err.Error())
+			return
+		}
+		a.saveStep(ctx, msg.Chat.ID, d, buttons)
+	}
+}
+
+// handleSkip saves the step being written without buttons.
+func (a *AdminBotHandler) handleSkip(ctx context.Context, msg *tgbotapi.Message) {
+	d := a.authoring[msg.Chat.ID]
+	if d == nil || d.awaiting != awaitButtons {
+		a.reply(msg.Chat.ID, "Nothing to skip")
+		return
+	}
+	a.saveStep(ctx, msg.Chat.ID, d, nil)
+}
+
+// handleCancel drops the step being written.
+func (a *AdminBotHandler) handleCancel(msg *tgbotapi.Message) {
+	if _, ok := a.authoring[msg.Chat.ID]; !ok {
+		a.reply(msg.Chat.ID, "Nothing to cancel")
+		return
+	}
+	delete(a.authoring, msg.Chat.ID)
+	a.reply(msg.Chat.ID, "Step dropped")
+}
+
+// checkDraft checks that the bot has the script, creating its draft when
+// there is none, and replies when it does not.
+func (a *AdminBotHandler) checkDraft(ctx context.Context, chatID int64, bot *telegram_bot.TelegramBot, scriptName string) bool {
+	_, err := a.services.Scripts.DraftByName(ctx, bot, scriptName)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(chatID, "Script not found")
+	default:
+		a.logger.Error("failed to get script draft", zap.Error(err))
+		a.reply(chatID, "Failed to get the draft")
+	}
+	return false
+}
+
+// saveStep stores the message of the step with its media and buttons and
+// appends the step to the draft of the script. The draft is dropped either
+// way.
+func (a *AdminBotHandler) saveStep(ctx context.Context, chatID int64, d *stepDraft, buttons []message.Button) {
+	delete(a.authoring, chatID)
+
+	text, entities := d.content.Text, d.content.Entities
+	if text == "" {
+		text, entities = d.content.Caption, d.content.CaptionEntities
+	}
+	msg := &message.Message{Content: text, Buttons: buttons}
+	if len(entities) > 0 {
+		msg.Content = telegram.FormatHTML(text, entities)
+		msg.ParseMode = message.ParseModeHTML
+	}
+
+	var files []message.File
+	if f, ok := mediaFile(d.content); ok {
+		if f.size > maxBundleSize {
+			a.reply(chatID, fmt.Sprintf("Media must be smaller than %d MB", maxBundleSize>>20))
+			return
+		}
+		data, err := a.download(ctx, f.id)
+		if err != nil {
+			a.logger.Error("failed to download step media", zap.Error(err))
+			a.reply(chatID, "Failed to download the media")
+			return
+		}
+		files = append(files, message.File{Kind: f.kind, Ext: f.ext, MimeType: f.mimeType, Data: data})
+	}
+
+	err := a.services.Messages.Create(ctx, msg, files)
+	switch {
+	case err == nil:
+	case errors.Is(err, message.ErrInvalidTemplate), errors.Is(err, message.ErrInvalidParseMode):
+		a.reply(chatID, err.Error()+", fix the message and start again")
+		return
+	default:
+		a.logger.Error("failed to create step message", zap.Error(err))
+		a.reply(chatID, "Failed to save the message")
+		return
+	}
+
+	step := &script.Step{MessageID: msg.ID, Timing: d.delay}
+	for _, b := range buttons {
+		if b.URL == "" {
+			step.Wait = &script.Wait{For: script.WaitButton}
+			break
+		}
+	}
+	v, err := a.services.Scripts.AddStep(ctx, d.bot, d.scriptName, step)
+	switch {
+	case err == nil:
+		a.reply(chatID, fmt.Sprintf("Added step %d to draft %d of %s, see /steps @%s %s",
+			step.Order, v.Number, d.scriptName, d.bot.Username, d.scriptName))
+	case errors.Is(err, script.ErrInvalidScript):
+		a.reply(chatID, err.Error())
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(chatID, "Script not found")
+	default:
+		a.logger.Error("failed to add script step", zap.Error(err))
+		a.reply(chatID, "Failed to add the step")
+	}
+}
+
+// hasStepContent reports whether msg can be the message of a step.
+func hasStepContent(msg *tgbotapi.Message) bool {
+	return msg.Text != "" || msg.Caption != ""
+}
+
+// stepFile is the media file of a message sent to the admin bot.
+type stepFile struct {
+	kind     string
+	id       string
+	ext      string
+	mimeType string
+	size     int
+}
+
+// mediaFile returns the photo, video, animation, audio, voice note or
+// document attached to msg. Photos come in several sizes and the largest is
+// taken.
+func mediaFile(msg *tgbotapi.Message) (stepFile, bool) {
+	ext := func(name, fallback string) string {
+		if e := strings.TrimPrefix(filepath.Ext(name), "."); e != "" {
+			return strings.ToLower(e)
+		}
+		return fallback
+	}
+	switch {
+	case len(msg.Photo) > 0:
+		p := msg.Photo[len(msg.Photo)-1]
+		return stepFile{kind: message.MediaPhoto, id: p.FileID, ext: "jpg", mimeType: "image/jpeg", size: p.FileSize}, true
+	case msg.Video != nil:
+		v := msg.Video
+		return stepFile{kind: message.MediaVideo, id: v.FileID, ext: ext(v.FileName, "mp4"), mimeType: v.MimeType, size: v.FileSize}, true
+	case msg.Animation != nil:
+		v := msg.Animation
+		return stepFile{kind: message.MediaAnimation, id: v.FileID, ext: ext(v.FileName, "mp4"), mimeType: v.MimeType, size: v.FileSize}, true
+	case msg.Audio != nil:
+		v := msg.Audio
+		return stepFile{kind: message.MediaAudio, id: v.FileID, ext: ext(v.FileName, "mp3"), mimeType: v.MimeType, size: v.FileSize}, true
+	case msg.Voice != nil:
+		v := msg.Voice
+		return stepFile{kind: message.MediaVoice, id: v.FileID, ext: "ogg", mimeType: v.MimeType, size: v.FileSize}, true
+	case msg.Document != nil:
+		v := msg.Document
+		return stepFile{kind: message.MediaDocument, id: v.FileID, ext: ext(v.FileName, ""), mimeType: v.MimeType, size: v.FileSize}, true
+	default:
+		return stepFile{}, false
+	}
+}
+
+// parseDelay parses the delay of a step: a Go duration such as 30m or 2h,
+// whole days such as 1d, or 0.
+func parseDelay(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	invalid := errors.New("delay must look like 0, 30m, 2h or 1d")
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, invalid
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, invalid
+	}
+	return d, nil
+}
+
+// parseButtons parses buttons written one per line as "Text",
+// "Text | https://link" or "Text | https://link | track", the last counting
+// clicks on the link.
+func parseButtons(s string) ([]message.Button, error) {
+	var buttons []message.Button
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		text, link, hasLink := strings.Cut(line, "|")
+		b := message.Button{Text: strings.TrimSpace(text)}
+		if b.Text == "" {
+			return nil, fmt.Errorf("button text is empty in %q", line)
+		}
+		if i := strings.LastIndex(link, "|"); i >= 0 && strings.TrimSpace(link[i+1:]) == "track" {
+			b.TrackClicks = true
+			link = link[:i]
+		}
+		if hasLink {
+			b.URL = strings.TrimSpace(link)
+			u, err := url.Parse(b.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "tg") || (u.Host == "" && u.Scheme != "tg") {
+				return nil, fmt.Errorf("link of %q must be an http(s) or tg:// URL", b.Text)
+			}
+		}
+		buttons = append(buttons, b)
+	}
+	if len(buttons) == 0 {
+		return nil, errors.New("send at least one button, or /skip")
+	}
+	return buttons, nil
+}
+
+// handleSteps shows the steps of the draft of a script with buttons to move
+// and delete them: /steps @bot <script name>.
+func (a *AdminBotHandler) handleSteps(ctx context.Context, msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		a.reply(msg.Chat.ID, "Usage: /steps @bot <script name>")
+		return
+	}
+	bot, ok := a.managedBot(ctx, msg, args[0])
+	if !ok {
+		return
+	}
+
+	v, steps, err := a.services.Scripts.DraftSteps(ctx, bot, args[1])
+	switch {
+	case err == nil:
+	case errors.Is(err, app_errors.ErrNotFound):
+		a.reply(msg.Chat.ID, "Script not found")
+		return
+	default:
+		a.logger.Error("failed to list draft steps", zap.Error(err))
+		a.reply(msg.Chat.ID, "Failed to list steps")
+		return
+	}
+	if len(steps) == 0 {
+		a.reply(msg.Chat.ID, fmt.Sprintf("Draft %d of %s has no steps, add one with /add_step @%s %s",
+			v.Number, args[1], bot.Username, args[1]))
+		return
+	}
+
+	m := tgbotapi.NewMessage(msg.Chat.ID, a.stepMenuText(ctx, args[1], steps))
+	m.ReplyMarkup = stepMenuKeyboard(steps)
+	sent, err := a.bot.Send(m)
+	if err != nil {
+		a.logger.Error("failed to send steps menu", zap.Error(err))
+		return
+	}
+	a.stepMenus[msg.Chat.ID] = &stepMenu{
+		messageID:  sent.MessageID,
+		telegramID: msg.From.ID,
+		bot:        bot,
+		scriptName: args[1],
+	}
+}
+
+// handleStepCallback handles the buttons of /steps menus.
+func (a *AdminBotHandler) handleStepCallback(ctx context.Context, cb *tgbotapi.CallbackQuery) {
+	answer := ""
+	defer func() {
+		if _, err := a.bot.Request(tgbotapi.NewCallback(cb.ID, answer)); err != nil {
+			a.logger.Warn("failed to answer callback query", zap.Error(err))
+		}
+	}()
+
+	if cb.Message == nil || cb.From == nil {
+		return
+	}
+	menu := a.stepMenus[cb.Message.Chat.ID]
+	if menu == nil || menu.messageID != cb.Message.MessageID || menu.telegramID != cb.From.ID {
+		answer = "This menu has expired, open it again with /steps"
+		return
+	}
+	action, arg, _ := strings.Cut(strings.TrimPrefix(cb.Data, stepCallbackPrefix), ":")
+	stepID, err := uuid.Parse(arg)
+	if err != nil && action != stepBack {
+		return
+	}
+
+	var steps []*script.Step
+	switch action {
+	case stepUp, stepDown:
+		_, steps, err = a.services.Scripts.MoveStep(ctx, menu.bot, stepID, action == stepUp)
+	case stepDelete:
+		edit := tgbotapi.NewEditMessageReplyMarkup(cb.Message.Chat.ID, cb.Message.MessageID, tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Delete the step", stepCallbackPrefix+stepConfirm+":"+stepID.String()),
+				tgbotapi.NewInlineKeyboardButtonData("Keep it", stepCallbackPrefix+stepBack),
+			),
+		))
+		if _, err := a.bot.Request(edit); err != nil {
+			a.logger.Warn("failed to edit steps menu", zap.Error(err))
+		}
+		return
+	case stepConfirm:
+		_, steps, err = a.services.Scripts.DeleteStep(ctx, menu.bot, stepID)
+	case stepBack:
+		_, steps, err = a.services.Scripts.DraftSteps(ctx, menu.bot, menu.scriptName)
+	default:
+		return
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, script.ErrPublishedStep):
+		answer = err.Error()
+		return
+	case errors.Is(err, app_errors.ErrNotFound):
+		answer = "Step not found"
+		return
+	default:
+		a.logger.Error("failed to change draft steps", zap.String("action", action), zap.Error(err))
+		answer = "Failed to change steps"
+		return
+	}
+
+	text := a.stepMenuText(ctx, menu.scriptName, steps)
+	var edit tgbotapi.Chattable
+	if len(steps) == 0 {
+		edit = tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID, text)
+	} else {
+		edit = tgbotapi.NewEditMessageTextAndMarkup(cb.Message.Chat.ID, cb.Message.MessageID, text, stepMenuKeyboard(steps))
+	}
+	// moving the first step up changes nothing, which Telegram reports as an error
+	if _, err := a.bot.Request(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		a.logger.Warn("failed to edit steps menu", zap.Error(err))
+	}
+}
+
+// stepMenuText lists the steps of a draft with the start of their messages.
+func (a *AdminBotHandler) stepMenuText(ctx context.Context, scriptName string, steps []*script.Step) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Steps of the draft of %s:", scriptName)
+	if len(steps) == 0 {
+		b.WriteString(" none")
+	}
+	for _, st := range steps {
+		preview := "?"
+		if msg, err := a.services.Messages.Get(ctx, st.MessageID); err == nil {
+			preview = snippet(msg.Content, 40)
+		} else {
+			a.logger.Warn("failed to get step message", zap.String("step_id", st.ID.String()), zap.Error(err))
+		}
+		fmt.Fprintf(&b, "\n%d. after %s: %s", st.Order, st.Timing, preview)
+		if st.Wait != nil {
+			fmt.Fprintf(&b, " (waits for %s)", st.Wait.For)
+		}
+	}
+	return b.String()
+}
+
+func stepMenuKeyboard(steps []*script.Step) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(steps))
+	for _, st := range steps {
+		data := func(action string) string { return stepCallbackPrefix + action + ":" + st.ID.String() }
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d ↑", st.Order), data(stepUp)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d ↓", st.Order), data(stepDown)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d ✕", st.Order), data(stepDelete)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// snippet returns the first line of message content without markup, cut to
+// n characters.
+func snippet(content string, n int) string {
+	text := html.UnescapeString(htmlTag.ReplaceAllString(content, ""))
+	text, _, _ = strings.Cut(strings.TrimSpace(text), "\n")
+	if runes := []rune(text); len(runes) > n {
+		return string(runes[:n]) + "…"
+	}
+	return text
+}
@@ -2,23 +2,36 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/VladKovDev/promo-bot/internal/config"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/telegram"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
 	"github.com/VladKovDev/promo-bot/pkg/logger"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type BotHandler struct {
-	bot    *tgbotapi.BotAPI
-	cfg    config.Config
-	logger logger.Logger
+	bot      *tgbotapi.BotAPI
+	record   *telegram_bot.TelegramBot
+	services Services
+	cfg      config.Config
+	logger   logger.Logger
 }
 
-func NewBotHandler(bot *tgbotapi.BotAPI, cfg config.Config, logger logger.Logger) *BotHandler {
+func NewBotHandler(bot *tgbotapi.BotAPI, record *telegram_bot.TelegramBot, services Services, cfg config.Config, logger logger.Logger) *BotHandler {
 	return &BotHandler{
-		bot:    bot,
-		cfg:    cfg,
-		logger: logger,
+		bot:      bot,
+		record:   record,
+		services: services,
+		cfg:      cfg,
+		logger:   logger.With(zap.String("bot_id", record.ID.String())),
 	}
 }
 
@@ -26,8 +39,11 @@ func NewBotHandler(bot *tgbotapi.BotAPI, cfg config.Config, logger logger.Logger
 func (h *BotHandler) Start(ctx context.Context) {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
+	// chat_member updates are only delivered when asked for explicitly
+	u.AllowedUpdates = []string{"message", "callback_query", "chat_member", "chat_join_request"}
 
 	updates := h.bot.GetUpdatesChan(u)
+	defer h.bot.StopReceivingUpdates()
 
 	for {
 		select {
@@ -37,29 +53,228 @@ func (h *BotHandler) Start(ctx context.Context) {
 			if !ok {
 				return
 			}
+			h.handleUpdate(ctx, upd)
+		}
+	}
+}
 
-			if upd.Message == nil {
-				continue
-			}
+func (h *BotHandler) handleUpdate(ctx context.Context, upd tgbotapi.Update) {
+	defer recoverUpdate(ctx, h.services, h.record, h.logger)
 
-			if upd.Message.IsCommand() {
-				switch upd.Message.Command() {
-				case "start":
-					h.handleStart(upd.Message)
-				default:
-					// unhandled commands can be ignored for now
-				}
-			}
+	if upd.CallbackQuery != nil {
+		h.handleCallback(ctx, upd.CallbackQuery)
+		return
+	}
+
+	if upd.ChatMember != nil {
+		h.handleChatMember(ctx, upd.ChatMember)
+		return
+	}
+
+	if upd.ChatJoinRequest != nil {
+		h.handleJoinRequest(ctx, upd.ChatJoinRequest)
+		return
+	}
+
+	if upd.Message == nil || upd.Message.From == nil {
+		return
+	}
+	// messages in groups the bot administers are not script input, but
+	// replies in its support group answer users
+	if !upd.Message.Chat.IsPrivate() {
+		h.handleSupportReply(ctx, upd.Message)
+		return
+	}
+
+	if upd.Message.IsCommand() {
+		switch upd.Message.Command() {
+		case "start":
+			h.handleStart(ctx, upd.Message)
+		case "timezone":
+			h.handleTimezone(ctx, upd.Message)
+		default:
+			// unhandled commands can be ignored for now
 		}
+		return
 	}
+
+	h.handleMessage(ctx, upd.Message)
 }
 
-func (h *BotHandler) handleStart(msg *tgbotapi.Message) {
-	chatID := msg.Chat.ID
-	reply := "start command received"
+// handleStart enrolls the user into the script of the deep link payload,
+// t.me/<bot>?start=<payload>, or into the default script.
+func (h *BotHandler) handleStart(ctx context.Context, msg *tgbotapi.Message) {
+	u, err := h.services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		h.logger.Error("failed to register user", zap.Error(err))
+		return
+	}
+
+	_, err = h.services.Scripts.StartFromLink(ctx, h.record, u, strings.TrimSpace(msg.CommandArguments()))
+	switch {
+	case err == nil, errors.Is(err, script.ErrAlreadyStarted):
+	case errors.Is(err, app_errors.ErrNotFound), errors.Is(err, script.ErrNoSteps), errors.Is(err, script.ErrNotPublished):
+		h.reply(msg.Chat.ID, "start command received")
+	default:
+		h.logger.Error("failed to start script", zap.Int64("telegram_id", u.TelegramID), zap.Error(err))
+	}
+}
+
+// handleTimezone shows or sets the user's timezone: /timezone Europe/Berlin
+// or /timezone UTC+3.
+func (h *BotHandler) handleTimezone(ctx context.Context, msg *tgbotapi.Message) {
+	u, err := h.services.Users.Register(ctx, userFromTelegram(msg.From))
+	if err != nil {
+		h.logger.Error("failed to register user", zap.Error(err))
+		return
+	}
+
+	arg := msg.CommandArguments()
+	if arg == "" {
+		tz := u.Timezone
+		if tz == "" {
+			tz = h.record.Location().String()
+		}
+		h.reply(msg.Chat.ID, fmt.Sprintf("Your timezone: %s\nChange it with /timezone Europe/Berlin or /timezone UTC+3", tz))
+		return
+	}
+
+	tz, err := h.services.Users.SetTimezone(ctx, u.ID, arg)
+	if err != nil {
+		h.reply(msg.Chat.ID, "Unknown timezone. Use a name like Europe/Berlin or an offset like UTC+3")
+		return
+	}
+	h.reply(msg.Chat.ID, "Timezone set to "+tz)
+}
+
+// handleMessage passes text replies and shared contacts to the step the user
+// is waiting on. Text no step was waiting for goes to the bot's admins.
+func (h *BotHandler) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
+	var input script.Input
+	switch {
+	case msg.Contact != nil:
+		// only the user's own contact counts
+		if msg.Contact.UserID != msg.From.ID {
+			return
+		}
+		input = script.Input{Type: script.InputContact, Phone: msg.Contact.PhoneNumber}
+	case msg.Text != "":
+		input = script.Input{Type: script.InputText, Text: msg.Text}
+	default:
+		return
+	}
+	if h.handleInput(ctx, msg.From, input) || input.Type != script.InputText {
+		return
+	}
+	h.relayToInbox(ctx, msg)
+}
+
+// handleCallback handles presses of message buttons.
+func (h *BotHandler) handleCallback(ctx context.Context, cb *tgbotapi.CallbackQuery) {
+	if cb.Data == script.CheckSubscriptionCallback && cb.From != nil {
+		h.handleCheckSubscription(ctx, cb)
+		return
+	}
+
+	if _, err := h.bot.Request(tgbotapi.NewCallback(cb.ID, "")); err != nil {
+		h.logger.Warn("failed to answer callback query", zap.Error(err))
+	}
+
+	data, ok := strings.CutPrefix(cb.Data, script.ButtonCallbackPrefix)
+	if !ok || cb.From == nil {
+		return
+	}
+	buttonID, err := uuid.Parse(data)
+	if err != nil {
+		return
+	}
+	h.handleInput(ctx, cb.From, script.Input{Type: script.InputButton, ButtonID: buttonID})
+}
+
+// handleChatMember records users joining private groups of the bot and moves
+// on scripts waiting for a channel subscription.
+func (h *BotHandler) handleChatMember(ctx context.Context, upd *tgbotapi.ChatMemberUpdated) {
+	member := upd.NewChatMember
+	if member.User == nil {
+		return
+	}
+	if telegram.IsMember(upd.OldChatMember.Status, upd.OldChatMember.IsMember) ||
+		!telegram.IsMember(member.Status, member.IsMember) {
+		return
+	}
+
+	if err := h.services.Groups.HandleJoin(ctx, h.record, upd.Chat.ID, member.User.ID); err != nil {
+		h.logger.Error("failed to handle group join",
+			zap.Int64("chat_id", upd.Chat.ID),
+			zap.Int64("telegram_id", member.User.ID),
+			zap.Error(err))
+	}
+	if err := h.services.Scripts.HandleSubscribed(ctx, h.record, upd.Chat.ID, member.User.ID); err != nil {
+		h.logger.Error("failed to handle channel subscription",
+			zap.Int64("chat_id", upd.Chat.ID),
+			zap.Int64("telegram_id", member.User.ID),
+			zap.Error(err))
+	}
+}
+
+// handleCheckSubscription handles the "check again" button of subscription
+// gates.
+func (h *BotHandler) handleCheckSubscription(ctx context.Context, cb *tgbotapi.CallbackQuery) {
+	var answer string
+	u, err := h.services.Users.Register(ctx, userFromTelegram(cb.From))
+	if err != nil {
+		h.logger.Error("failed to register user", zap.Error(err))
+	} else {
+		subscribed, err := h.services.Scripts.CheckSubscription(ctx, h.record, u)
+		if err != nil {
+			h.logger.Error("failed to check subscription", zap.Int64("telegram_id", u.TelegramID), zap.Error(err))
+		}
+		if !subscribed {
+			answer = "Subscribe to the channel first"
+		}
+	}
+
+	if _, err := h.bot.Request(tgbotapi.NewCallback(cb.ID, answer)); err != nil {
+		h.logger.Warn("failed to answer callback query", zap.Error(err))
+	}
+}
+
+// handleJoinRequest lets qualified users into private groups of the bot.
+func (h *BotHandler) handleJoinRequest(ctx context.Context, req *tgbotapi.ChatJoinRequest) {
+	u, err := h.services.Users.Register(ctx, userFromTelegram(&req.From))
+	if err != nil {
+		h.logger.Error("failed to register user", zap.Error(err))
+		return
+	}
+
+	if err := h.services.Groups.HandleJoinRequest(ctx, h.record, req.Chat.ID, u); err != nil {
+		h.logger.Error("failed to handle join request",
+			zap.Int64("chat_id", req.Chat.ID),
+			zap.Int64("telegram_id", u.TelegramID),
+			zap.Error(err))
+	}
+}
+
+func (h *BotHandler) handleInput(ctx context.Context, from *tgbotapi.User, input script.Input) bool {
+	u, err := h.services.Users.Register(ctx, userFromTelegram(from))
+	if err != nil {
+		h.logger.Error("failed to register user", zap.Error(err))
+		return false
+	}
+
+	handled, err := h.services.Scripts.HandleInput(ctx, h.record, u, input)
+	if err != nil {
+		h.logger.Error("failed to handle user input",
+			zap.Int64("telegram_id", u.TelegramID),
+			zap.String("input_type", input.Type),
+			zap.Error(err))
+	}
+	return handled
+}
 
-	m := tgbotapi.NewMessage(chatID, reply)
+func (h *BotHandler) reply(chatID int64, text string) {
+	m := tgbotapi.NewMessage(chatID, text)
 	if _, err := h.bot.Send(m); err != nil {
-		h.logger.Error("failed to send /start reply")
+		h.logger.Error("failed to send reply", zap.Error(err))
 	}
 }
@@ -0,0 +1,110 @@
+package alert
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/telegram"
+)
+
+// Deliveries counts the outcomes of script deliveries per bot over a sliding
+// window, in one-minute buckets. Chats the bot has no access to, such as
+// those of users who blocked it, are not counted as failures.
+type Deliveries struct {
+	mu      sync.Mutex
+	window  time.Duration
+	buckets map[int64][]deliveryBucket
+}
+
+type deliveryBucket struct {
+	minute    time.Time
+	attempted int
+	failed    int
+}
+
+func NewDeliveries(window time.Duration) *Deliveries {
+	return &Deliveries{
+		window:  window,
+		buckets: make(map[int64][]deliveryBucket),
+	}
+}
+
+// Record counts a delivery through the bot with Telegram ID botID made at
+// at, failed when err is not nil.
+func (d *Deliveries) Record(botID int64, at time.Time, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	minute := at.Truncate(time.Minute)
+	buckets := d.prune(botID, at)
+	if n := len(buckets); n == 0 || !buckets[n-1].minute.Equal(minute) {
+		buckets = append(buckets, deliveryBucket{minute: minute})
+	}
+	last := &buckets[len(buckets)-1]
+	last.attempted++
+	if err != nil && !telegram.IsForbidden(err) {
+		last.failed++
+	}
+	d.buckets[botID] = buckets
+}
+
+// Count returns the deliveries through the bot attempted within the window
+// before now, and how many of them failed.
+func (d *Deliveries) Count(botID int64, now time.Time) (attempted, failed int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, b := range d.prune(botID, now) {
+		attempted += b.attempted
+		failed += b.failed
+	}
+	return attempted, failed
+}
+
+// prune drops the bot's buckets that fell out of the window.
+func (d *Deliveries) prune(botID int64, now time.Time) []deliveryBucket {
+	buckets := d.buckets[botID]
+	start := now.Add(-d.window)
+	i := 0
+	for i < len(buckets) && !buckets[i].minute.After(start) {
+		i++
+	}
+	if i == len(buckets) {
+		delete(d.buckets, botID)
+		return nil
+	}
+	buckets = buckets[i:]
+	d.buckets[botID] = buckets
+	return buckets
+}
+
+// TrackDeliveries returns a sender that records the outcome of every message
+// sent through sender in d.
+func TrackDeliveries(sender script.Sender, d *Deliveries) script.Sender {
+	return &trackingSender{sender: sender, deliveries: d}
+}
+
+type trackingSender struct {
+	sender     script.Sender
+	deliveries *Deliveries
+}
+
+func (s *trackingSender) Send(ctx context.Context, botID int64, msg telegram.OutgoingMessage) (int, error) {
+	id, err := s.sender.Send(ctx, botID, msg)
+	s.deliveries.Record(botID, time.Now(), err)
+	return id, err
+}
+
+func (s *trackingSender) SendToGroup(ctx context.Context, botID int64, msg telegram.OutgoingMessage, threadID int) (int, error) {
+	id, err := s.sender.SendToGroup(ctx, botID, msg, threadID)
+	s.deliveries.Record(botID, time.Now(), err)
+	return id, err
+}
+
+func (s *trackingSender) Post(ctx context.Context, botID int64, msg telegram.OutgoingMessage) (int, error) {
+	id, err := s.sender.Post(ctx, botID, msg)
+	s.deliveries.Record(botID, time.Now(), err)
+	return id, err
+}
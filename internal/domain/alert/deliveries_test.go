@@ -0,0 +1,36 @@
+package alert
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestDeliveries(t *testing.T) {
+	d := NewDeliveries(10 * time.Minute)
+	start := time.Date(2026, 1, 30, 12, 0, 0, 0, time.UTC)
+	failure := errors.New("timeout")
+	blocked := &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+
+	d.Record(1, start, nil)
+	d.Record(1, start.Add(30*time.Second), failure)
+	d.Record(1, start.Add(5*time.Minute), blocked)
+	d.Record(1, start.Add(9*time.Minute), failure)
+	d.Record(2, start, failure)
+
+	if attempted, failed := d.Count(1, start.Add(9*time.Minute)); attempted != 4 || failed != 2 {
+		t.Errorf("Count() = %d, %d, want 4, 2", attempted, failed)
+	}
+	// the first minute falls out of the window
+	if attempted, failed := d.Count(1, start.Add(10*time.Minute)); attempted != 2 || failed != 1 {
+		t.Errorf("Count() after the window moved = %d, %d, want 2, 1", attempted, failed)
+	}
+	if attempted, failed := d.Count(2, start.Add(20*time.Minute)); attempted != 0 || failed != 0 {
+		t.Errorf("Count() of an idle bot = %d, %d, want 0, 0", attempted, failed)
+	}
+	if attempted, _ := d.Count(3, start); attempted != 0 {
+		t.Errorf("Count() of an unknown bot = %d, want 0", attempted)
+	}
+}
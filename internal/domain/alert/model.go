@@ -0,0 +1,18 @@
+package alert
+
+import "github.com/google/uuid"
+
+// Kinds of alerts. Alerts of one kind about a bot are deduplicated.
+const (
+	KindTokenRevoked     = "token_revoked"
+	KindDeliveryFailures = "delivery_failures"
+	KindSchedulerLag     = "scheduler_lag"
+	KindPanic            = "panic"
+)
+
+// Alert is a message about a problem with a bot for its owners.
+type Alert struct {
+	BotID uuid.UUID
+	Kind  string
+	Text  string
+}
@@ -0,0 +1,25 @@
+package alert
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Record logs an alert that was sent at createdAt. CountSince compares
+	// with the same clock, so the time is not left to the database.
+	Record(ctx context.Context, alert *Alert, createdAt time.Time) error
+	// CountSince returns the number of alerts about the bot sent since, of
+	// the given kind or of any kind when kind is empty.
+	CountSince(ctx context.Context, botID uuid.UUID, kind string, since time.Time) (int, error)
+	// ListRecipients returns the Telegram IDs of the bot's owners who did not
+	// mute its alerts.
+	ListRecipients(ctx context.Context, botID uuid.UUID) ([]int64, error)
+	// AdminBotID returns the Telegram ID of the active admin bot.
+	AdminBotID(ctx context.Context) (int64, error)
+
+	SetMuted(ctx context.Context, botID, userID uuid.UUID, muted bool) error
+	IsMuted(ctx context.Context, botID, userID uuid.UUID) (bool, error)
+}
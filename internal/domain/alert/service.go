@@ -0,0 +1,97 @@
+package alert
+
+import (
+	"context"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Sender sends text messages through a bot.
+type Sender interface {
+	SendMessage(ctx context.Context, botID, chatID int64, text string) error
+}
+
+type Service struct {
+	repo       Repository
+	sender     Sender
+	cooldown   time.Duration
+	maxPerHour int
+	logger     logger.Logger
+}
+
+func NewService(repo Repository, sender Sender, cooldown time.Duration, maxPerHour int, logger logger.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		sender:     sender,
+		cooldown:   cooldown,
+		maxPerHour: maxPerHour,
+		logger:     logger,
+	}
+}
+
+// Notify sends the alert through the admin bot to the owners of its bot who
+// did not mute it. The alert is dropped when one of the same kind about the
+// bot was sent within the cooldown, or when the bot's alerts in the last hour
+// reached the limit.
+func (s *Service) Notify(ctx context.Context, a Alert) error {
+	now := time.Now().UTC()
+	if s.cooldown > 0 {
+		repeats, err := s.repo.CountSince(ctx, a.BotID, a.Kind, now.Add(-s.cooldown))
+		if err != nil {
+			return err
+		}
+		if repeats > 0 {
+			return nil
+		}
+	}
+	sent, err := s.repo.CountSince(ctx, a.BotID, "", now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if sent >= s.maxPerHour {
+		s.logger.Warn("alert dropped by rate limit",
+			zap.String("bot_id", a.BotID.String()),
+			zap.String("kind", a.Kind))
+		return nil
+	}
+
+	recipients, err := s.repo.ListRecipients(ctx, a.BotID)
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+	adminBotID, err := s.repo.AdminBotID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the alert is logged before sending so a failing recipient does not
+	// make the next check send it again
+	if err := s.repo.Record(ctx, &a, now); err != nil {
+		return err
+	}
+	for _, chatID := range recipients {
+		if err := s.sender.SendMessage(ctx, adminBotID, chatID, a.Text); err != nil {
+			// owners who never started the admin bot cannot be messaged
+			s.logger.Warn("failed to send alert",
+				zap.String("bot_id", a.BotID.String()),
+				zap.Int64("telegram_id", chatID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// SetMuted mutes or unmutes the alerts about the bot for one of its owners.
+func (s *Service) SetMuted(ctx context.Context, botID, userID uuid.UUID, muted bool) error {
+	return s.repo.SetMuted(ctx, botID, userID, muted)
+}
+
+func (s *Service) IsMuted(ctx context.Context, botID, userID uuid.UUID) (bool, error) {
+	return s.repo.IsMuted(ctx, botID, userID)
+}
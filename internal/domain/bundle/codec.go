@@ -0,0 +1,163 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Bundle encodings.
+const (
+	EncodingYAML = "yaml"
+	EncodingJSON = "json"
+	// EncodingArchive is a zip archive with bundle.yaml and the media files
+	// under media/.
+	EncodingArchive = "zip"
+)
+
+// archiveManifest is the name of the bundle inside an archive.
+const archiveManifest = "bundle.yaml"
+
+// maxArchiveFile caps the size of one unpacked archive entry.
+const maxArchiveFile = 50 << 20
+
+// EncodingOf picks the encoding from a file name or a bare encoding name.
+func EncodingOf(name string) (string, error) {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	if ext == "" {
+		ext = strings.ToLower(name)
+	}
+	switch ext {
+	case "yaml", "yml":
+		return EncodingYAML, nil
+	case "json":
+		return EncodingJSON, nil
+	case "zip":
+		return EncodingArchive, nil
+	default:
+		return "", fmt.Errorf("%w, got: %q", ErrUnknownEncoding, name)
+	}
+}
+
+// Encode writes the bundle as YAML or JSON.
+func Encode(b *Bundle, encoding string) ([]byte, error) {
+	switch encoding {
+	case EncodingYAML:
+		return yaml.Marshal(b)
+	case EncodingJSON:
+		return json.MarshalIndent(b, "", "  ")
+	default:
+		return nil, fmt.Errorf("%w, got: %q", ErrUnknownEncoding, encoding)
+	}
+}
+
+// Decode reads a YAML or JSON bundle.
+func Decode(data []byte, encoding string) (*Bundle, error) {
+	var b Bundle
+	var err error
+	switch encoding {
+	case EncodingYAML:
+		err = yaml.Unmarshal(data, &b)
+	case EncodingJSON:
+		err = json.Unmarshal(data, &b)
+	default:
+		return nil, fmt.Errorf("%w, got: %q", ErrUnknownEncoding, encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	return &b, nil
+}
+
+// EncodeArchive writes the bundle and the media files, keyed by their path
+// in the archive, as a zip archive.
+func EncodeArchive(b *Bundle, files map[string][]byte) ([]byte, error) {
+	manifest, err := Encode(b, EncodingYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := writeArchiveFile(zw, archiveManifest, manifest); err != nil {
+		return nil, err
+	}
+	for name, data := range files {
+		if err := writeArchiveFile(zw, name, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeArchiveFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// DecodeArchive reads a zip archive written by EncodeArchive and returns the
+// bundle and the media files under media/.
+func DecodeArchive(data []byte) (*Bundle, map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+
+	var b *Bundle
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if f.Name != archiveManifest && !ValidFile(f.Name) {
+			continue
+		}
+		content, err := readArchiveFile(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		if f.Name == archiveManifest {
+			if b, err = Decode(content, EncodingYAML); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		files[f.Name] = content
+	}
+	if b == nil {
+		return nil, nil, fmt.Errorf("%w: %s is missing from the archive", ErrInvalidBundle, archiveManifest)
+	}
+	return b, files, nil
+}
+
+func readArchiveFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, f.Name, err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, maxArchiveFile+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, f.Name, err)
+	}
+	if len(content) > maxArchiveFile {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidBundle, f.Name, maxArchiveFile)
+	}
+	return content, nil
+}
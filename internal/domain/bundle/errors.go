@@ -0,0 +1,10 @@
+package bundle
+
+import "errors"
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported bundle format version")
+	ErrInvalidBundle     = errors.New("invalid bundle")
+	ErrUnknownEncoding   = errors.New("bundle must be a .yaml, .yml, .json or .zip file")
+	ErrMissingMedia      = errors.New("media file is missing")
+)
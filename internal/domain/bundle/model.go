@@ -0,0 +1,325 @@
+package bundle
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/google/uuid"
+)
+
+// FormatVersion is the version of the bundle format written by Export.
+const FormatVersion = 1
+
+// Bundle is a portable copy of one version of a script with its messages,
+// buttons and media references. IDs are the ones of the exporting database;
+// they only link the parts of the bundle, import assigns new ones.
+type Bundle struct {
+	Format      int          `json:"format" yaml:"format"`
+	Script      string       `json:"script" yaml:"script"`
+	Steps       []Step       `json:"steps" yaml:"steps"`
+	Transitions []Transition `json:"transitions,omitempty" yaml:"transitions,omitempty"`
+	Messages    []Message    `json:"messages" yaml:"messages"`
+}
+
+type Step struct {
+	ID                uuid.UUID `json:"id" yaml:"id"`
+	MessageID         uuid.UUID `json:"message_id" yaml:"message_id"`
+	Order             int       `json:"order" yaml:"order"`
+	Channel           string    `json:"channel" yaml:"channel"`
+	TargetChatID      int64     `json:"target_chat_id,omitempty" yaml:"target_chat_id,omitempty"`
+	TargetThreadID    int       `json:"target_thread_id,omitempty" yaml:"target_thread_id,omitempty"`
+	DelaySeconds      int       `json:"delay_seconds" yaml:"delay_seconds"`
+	SkipOnError       bool      `json:"skip_on_error,omitempty" yaml:"skip_on_error,omitempty"`
+	Wait              *Wait     `json:"wait,omitempty" yaml:"wait,omitempty"`
+	SaveAs            string    `json:"save_as,omitempty" yaml:"save_as,omitempty"`
+	GrantsGroupAccess bool      `json:"grants_group_access,omitempty" yaml:"grants_group_access,omitempty"`
+	Variants          []Variant `json:"variants,omitempty" yaml:"variants,omitempty"`
+}
+
+// Variant is an A/B test variant of a step's message.
+type Variant struct {
+	Name      string    `json:"name" yaml:"name"`
+	MessageID uuid.UUID `json:"message_id" yaml:"message_id"`
+	Weight    int       `json:"weight" yaml:"weight"`
+}
+
+type Wait struct {
+	For            string     `json:"for" yaml:"for"`
+	Keywords       []string   `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	TimeoutSeconds int        `json:"timeout_seconds,omitempty" yaml:"timeout_seconds,omitempty"`
+	FallbackStepID *uuid.UUID `json:"fallback_step_id,omitempty" yaml:"fallback_step_id,omitempty"`
+	ChannelID      int64      `json:"channel_id,omitempty" yaml:"channel_id,omitempty"`
+}
+
+type Transition struct {
+	FromStepID uuid.UUID `json:"from_step_id" yaml:"from_step_id"`
+	ToStepID   uuid.UUID `json:"to_step_id" yaml:"to_step_id"`
+	Condition  string    `json:"condition" yaml:"condition"`
+	Value      string    `json:"value,omitempty" yaml:"value,omitempty"`
+	Priority   int       `json:"priority,omitempty" yaml:"priority,omitempty"`
+}
+
+type Message struct {
+	ID        uuid.UUID `json:"id" yaml:"id"`
+	Content   string    `json:"content" yaml:"content"`
+	ParseMode string    `json:"parse_mode,omitempty" yaml:"parse_mode,omitempty"`
+	Buttons   []Button  `json:"buttons,omitempty" yaml:"buttons,omitempty"`
+	Media     []Media   `json:"media,omitempty" yaml:"media,omitempty"`
+}
+
+type Button struct {
+	ID           uuid.UUID `json:"id" yaml:"id"`
+	Text         string    `json:"text" yaml:"text"`
+	URL          string    `json:"url,omitempty" yaml:"url,omitempty"`
+	SetAttribute string    `json:"set_attribute,omitempty" yaml:"set_attribute,omitempty"`
+	SetValue     string    `json:"set_value,omitempty" yaml:"set_value,omitempty"`
+	AddTag       string    `json:"add_tag,omitempty" yaml:"add_tag,omitempty"`
+	TrackClicks  bool      `json:"track_clicks,omitempty" yaml:"track_clicks,omitempty"`
+}
+
+// Media references a file in the media storage by its key. File is the path
+// of its bytes inside an archive and is empty when the bundle carries only
+// references. Kind is one of the message media kinds; bundles written before
+// it existed leave it empty and import as documents.
+type Media struct {
+	Key      string `json:"key" yaml:"key"`
+	Ext      string `json:"ext" yaml:"ext"`
+	Size     int64  `json:"size" yaml:"size"`
+	MimeType string `json:"mime_type" yaml:"mime_type"`
+	Kind     string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	File     string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+// New builds a bundle from the steps and transitions of a script version and
+// the messages they send. media holds the media of each message.
+func New(scriptName string, steps []*script.Step, transitions []*script.Transition, messages []*message.Message, media map[uuid.UUID][]message.Media) *Bundle {
+	b := &Bundle{
+		Format:      FormatVersion,
+		Script:      scriptName,
+		Steps:       make([]Step, 0, len(steps)),
+		Transitions: make([]Transition, 0, len(transitions)),
+		Messages:    make([]Message, 0, len(messages)),
+	}
+	for _, st := range steps {
+		step := Step{
+			ID:                st.ID,
+			MessageID:         st.MessageID,
+			Order:             st.Order,
+			Channel:           st.Channel,
+			TargetChatID:      st.TargetChatID,
+			TargetThreadID:    st.TargetThreadID,
+			DelaySeconds:      int(st.Timing / time.Second),
+			SkipOnError:       st.SkipOnError,
+			SaveAs:            st.SaveAs,
+			GrantsGroupAccess: st.GrantsGroupAccess,
+		}
+		if st.Wait != nil {
+			step.Wait = &Wait{
+				For:            st.Wait.For,
+				Keywords:       st.Wait.Keywords,
+				TimeoutSeconds: int(st.Wait.Timeout / time.Second),
+				FallbackStepID: st.Wait.FallbackStepID,
+				ChannelID:      st.Wait.ChannelID,
+			}
+		}
+		for _, v := range st.Variants {
+			step.Variants = append(step.Variants, Variant{Name: v.Name, MessageID: v.MessageID, Weight: v.Weight})
+		}
+		b.Steps = append(b.Steps, step)
+	}
+	for _, t := range transitions {
+		b.Transitions = append(b.Transitions, Transition{
+			FromStepID: t.FromStepID,
+			ToStepID:   t.ToStepID,
+			Condition:  t.Condition,
+			Value:      t.Value,
+			Priority:   t.Priority,
+		})
+	}
+	for _, m := range messages {
+		msg := Message{
+			ID:        m.ID,
+			Content:   m.Content,
+			ParseMode: m.ParseMode,
+		}
+		for _, btn := range m.Buttons {
+			msg.Buttons = append(msg.Buttons, Button{
+				ID:           btn.ID,
+				Text:         btn.Text,
+				URL:          btn.URL,
+				SetAttribute: btn.SetAttribute,
+				SetValue:     btn.SetValue,
+				AddTag:       btn.AddTag,
+				TrackClicks:  btn.TrackClicks,
+			})
+		}
+		for _, md := range media[m.ID] {
+			msg.Media = append(msg.Media, Media{
+				Key:      md.StorageKey,
+				Ext:      md.Ext,
+				Size:     md.Size,
+				MimeType: md.MimeType,
+				Kind:     md.Kind,
+			})
+		}
+		b.Messages = append(b.Messages, msg)
+	}
+	return b
+}
+
+// Validate checks that the bundle is complete and that the script it holds
+// passes the same checks as a script built by hand: IDs are unique, every
+// reference points inside the bundle, messages render and the graph is
+// valid.
+func (b *Bundle) Validate() error {
+	if b.Format != FormatVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedFormat, b.Format)
+	}
+	if strings.TrimSpace(b.Script) == "" {
+		return fmt.Errorf("%w: script name is empty", ErrInvalidBundle)
+	}
+	if len(b.Steps) == 0 {
+		return script.ErrNoSteps
+	}
+
+	messages := make(map[uuid.UUID]bool, len(b.Messages))
+	buttons := make(map[uuid.UUID]bool)
+	for _, m := range b.Messages {
+		if m.ID == uuid.Nil || messages[m.ID] {
+			return fmt.Errorf("%w: message ID %q is empty or repeated", ErrInvalidBundle, m.ID)
+		}
+		messages[m.ID] = true
+		msg := m.toMessage()
+		if err := msg.Validate(); err != nil {
+			return fmt.Errorf("%w: message %s: %v", ErrInvalidBundle, m.ID, err)
+		}
+		for _, btn := range m.Buttons {
+			if btn.ID == uuid.Nil || buttons[btn.ID] {
+				return fmt.Errorf("%w: button ID %q is empty or repeated", ErrInvalidBundle, btn.ID)
+			}
+			buttons[btn.ID] = true
+		}
+		for _, md := range m.Media {
+			if md.Key == "" {
+				return fmt.Errorf("%w: media of message %s has no key", ErrInvalidBundle, m.ID)
+			}
+			if md.File != "" && !ValidFile(md.File) {
+				return fmt.Errorf("%w: media file %q must be inside media/", ErrInvalidBundle, md.File)
+			}
+		}
+	}
+
+	steps := make(map[uuid.UUID]bool, len(b.Steps))
+	for _, st := range b.Steps {
+		if st.ID == uuid.Nil || steps[st.ID] {
+			return fmt.Errorf("%w: step ID %q is empty or repeated", ErrInvalidBundle, st.ID)
+		}
+		steps[st.ID] = true
+		if !messages[st.MessageID] {
+			return fmt.Errorf("%w: step %d sends message %s which is not in the bundle", ErrInvalidBundle, st.Order, st.MessageID)
+		}
+		for _, v := range st.Variants {
+			if !messages[v.MessageID] {
+				return fmt.Errorf("%w: variant %s of step %d sends message %s which is not in the bundle", ErrInvalidBundle, v.Name, st.Order, v.MessageID)
+			}
+		}
+	}
+	for _, t := range b.Transitions {
+		if t.Condition != script.ConditionButton {
+			continue
+		}
+		if id, err := uuid.Parse(t.Value); err != nil || !buttons[id] {
+			return fmt.Errorf("%w: transition refers to button %q which is not in the bundle", ErrInvalidBundle, t.Value)
+		}
+	}
+
+	return script.NewGraph(b.ScriptSteps(), b.ScriptTransitions()).Validate()
+}
+
+// ValidFile reports whether name is a path inside the media/ directory of an
+// archive.
+func ValidFile(name string) bool {
+	clean := path.Clean(name)
+	return clean == name && strings.HasPrefix(clean, "media/") && !strings.Contains(clean, "..")
+}
+
+// ScriptSteps converts the steps of the bundle to script steps with the
+// bundle's IDs.
+func (b *Bundle) ScriptSteps() []*script.Step {
+	steps := make([]*script.Step, 0, len(b.Steps))
+	for _, st := range b.Steps {
+		step := &script.Step{
+			ID:                st.ID,
+			MessageID:         st.MessageID,
+			Order:             st.Order,
+			Channel:           st.Channel,
+			TargetChatID:      st.TargetChatID,
+			TargetThreadID:    st.TargetThreadID,
+			Timing:            time.Duration(st.DelaySeconds) * time.Second,
+			SkipOnError:       st.SkipOnError,
+			SaveAs:            st.SaveAs,
+			GrantsGroupAccess: st.GrantsGroupAccess,
+		}
+		if st.Wait != nil {
+			step.Wait = &script.Wait{
+				For:            st.Wait.For,
+				Keywords:       st.Wait.Keywords,
+				Timeout:        time.Duration(st.Wait.TimeoutSeconds) * time.Second,
+				FallbackStepID: st.Wait.FallbackStepID,
+				ChannelID:      st.Wait.ChannelID,
+			}
+		}
+		for _, v := range st.Variants {
+			step.Variants = append(step.Variants, script.Variant{
+				StepID:    st.ID,
+				MessageID: v.MessageID,
+				Name:      v.Name,
+				Weight:    v.Weight,
+			})
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// ScriptTransitions converts the transitions of the bundle to script
+// transitions with the bundle's IDs.
+func (b *Bundle) ScriptTransitions() []*script.Transition {
+	transitions := make([]*script.Transition, 0, len(b.Transitions))
+	for _, t := range b.Transitions {
+		transitions = append(transitions, &script.Transition{
+			ID:         uuid.New(),
+			FromStepID: t.FromStepID,
+			ToStepID:   t.ToStepID,
+			Condition:  t.Condition,
+			Value:      t.Value,
+			Priority:   t.Priority,
+		})
+	}
+	return transitions
+}
+
+func (m *Message) toMessage() *message.Message {
+	msg := &message.Message{
+		ID:        m.ID,
+		Content:   m.Content,
+		ParseMode: m.ParseMode,
+	}
+	for _, btn := range m.Buttons {
+		msg.Buttons = append(msg.Buttons, message.Button{
+			ID:           btn.ID,
+			MessageID:    m.ID,
+			Text:         btn.Text,
+			URL:          btn.URL,
+			SetAttribute: btn.SetAttribute,
+			SetValue:     btn.SetValue,
+			AddTag:       btn.AddTag,
+			TrackClicks:  btn.TrackClicks,
+		})
+	}
+	return msg
+}
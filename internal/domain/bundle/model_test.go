@@ -0,0 +1,129 @@
+package bundle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/google/uuid"
+)
+
+func testBundle() *Bundle {
+	welcome := &message.Message{ID: uuid.New(), Content: "Hi {{first_name}}", ParseMode: message.ParseModeHTML}
+	yes := message.Button{ID: uuid.New(), MessageID: welcome.ID, Text: "Yes", AddTag: "interested"}
+	welcome.Buttons = []message.Button{yes}
+	bonus := &message.Message{ID: uuid.New(), Content: "Here is your bonus"}
+	gift2 := &message.Message{ID: uuid.New(), Content: "Your gift is ready"}
+
+	question := &script.Step{ID: uuid.New(), MessageID: welcome.ID, Order: 1, Channel: script.ChannelPrivate, Wait: &script.Wait{For: script.WaitButton, Timeout: time.Hour}}
+	gift := &script.Step{ID: uuid.New(), MessageID: bonus.ID, Order: 2, Channel: script.ChannelPrivate, Timing: 90 * time.Second}
+	question.Wait.FallbackStepID = &gift.ID
+	gift.Variants = []script.Variant{
+		{Name: "bonus", MessageID: bonus.ID, Weight: 1},
+		{Name: "gift", MessageID: gift2.ID, Weight: 1},
+	}
+
+	transitions := []*script.Transition{
+		{FromStepID: question.ID, ToStepID: gift.ID, Condition: script.ConditionButton, Value: yes.ID.String()},
+	}
+	media := map[uuid.UUID][]message.Media{
+		welcome.ID: {{StorageKey: "hello.jpg", Ext: "jpg", Size: 4, MimeType: "image/jpeg", Kind: message.MediaPhoto}},
+		bonus.ID:   {{StorageKey: "bonus.pdf", Ext: "pdf", Size: 3, MimeType: "application/pdf", Kind: message.MediaDocument}},
+	}
+	return New("welcome", []*script.Step{question, gift}, transitions, []*message.Message{welcome, bonus, gift2}, media)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	b := testBundle()
+	if err := b.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	for _, encoding := range []string{EncodingYAML, EncodingJSON} {
+		data, err := Encode(b, encoding)
+		if err != nil {
+			t.Fatalf("Encode(%s) = %v", encoding, err)
+		}
+		got, err := Decode(data, encoding)
+		if err != nil {
+			t.Fatalf("Decode(%s) = %v", encoding, err)
+		}
+		if !reflect.DeepEqual(got, b) {
+			t.Errorf("%s round trip changed the bundle:\n%+v\n%+v", encoding, got, b)
+		}
+	}
+}
+
+func TestEncodeDecodeKeepsMediaKind(t *testing.T) {
+	for _, encoding := range []string{EncodingYAML, EncodingJSON} {
+		data, err := Encode(testBundle(), encoding)
+		if err != nil {
+			t.Fatalf("Encode(%s) = %v", encoding, err)
+		}
+		got, err := Decode(data, encoding)
+		if err != nil {
+			t.Fatalf("Decode(%s) = %v", encoding, err)
+		}
+		if kind := got.Messages[0].Media[0].Kind; kind != message.MediaPhoto {
+			t.Errorf("%s round trip media kind = %q, want %q", encoding, kind, message.MediaPhoto)
+		}
+	}
+}
+
+func TestArchive(t *testing.T) {
+	b := testBundle()
+	b.Messages[1].Media[0].File = "media/bonus.pdf"
+	data, err := EncodeArchive(b, map[string][]byte{"media/bonus.pdf": []byte("pdf")})
+	if err != nil {
+		t.Fatalf("EncodeArchive() = %v", err)
+	}
+	got, files, err := DecodeArchive(data)
+	if err != nil {
+		t.Fatalf("DecodeArchive() = %v", err)
+	}
+	if !reflect.DeepEqual(got, b) || string(files["media/bonus.pdf"]) != "pdf" {
+		t.Fatalf("archive round trip changed the bundle or lost media: %+v %v", got, files)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(b *Bundle)
+		want   error
+	}{
+		{"future format", func(b *Bundle) { b.Format = FormatVersion + 1 }, ErrUnsupportedFormat},
+		{"no name", func(b *Bundle) { b.Script = " " }, ErrInvalidBundle},
+		{"no steps", func(b *Bundle) { b.Steps = nil }, script.ErrNoSteps},
+		{"missing message", func(b *Bundle) { b.Messages = b.Messages[:1] }, ErrInvalidBundle},
+		{"missing variant message", func(b *Bundle) { b.Messages = b.Messages[:2] }, ErrInvalidBundle},
+		{"zero variant weight", func(b *Bundle) { b.Steps[1].Variants[1].Weight = 0 }, script.ErrInvalidScript},
+		{"repeated step", func(b *Bundle) { b.Steps[1].ID = b.Steps[0].ID }, ErrInvalidBundle},
+		{"unknown button", func(b *Bundle) { b.Transitions[0].Value = uuid.NewString() }, ErrInvalidBundle},
+		{"broken template", func(b *Bundle) { b.Messages[0].Content = "Hi {{first_name" }, ErrInvalidBundle},
+		{"media outside media/", func(b *Bundle) { b.Messages[1].Media[0].File = "../bonus.pdf" }, ErrInvalidBundle},
+		{"unreachable step", func(b *Bundle) { b.Transitions[0].ToStepID = b.Steps[0].ID; b.Steps[0].Wait.FallbackStepID = nil }, script.ErrInvalidScript},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testBundle()
+			tt.change(b)
+			if err := b.Validate(); !errors.Is(err, tt.want) {
+				t.Fatalf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodingOf(t *testing.T) {
+	for name, want := range map[string]string{"funnel.yml": EncodingYAML, "funnel.YAML": EncodingYAML, "json": EncodingJSON, "funnel.zip": EncodingArchive} {
+		if got, err := EncodingOf(name); err != nil || got != want {
+			t.Errorf("EncodingOf(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := EncodingOf("funnel.txt"); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("EncodingOf(funnel.txt) error = %v", err)
+	}
+}
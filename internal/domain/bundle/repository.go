@@ -0,0 +1,15 @@
+package bundle
+
+import (
+	"context"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Import stores the bundle as the draft of the bot's script with the
+	// bundle's name, creating the script when the bot has none and
+	// replacing the content of an existing draft. All IDs are reassigned.
+	Import(ctx context.Context, telegramBotID uuid.UUID, b *Bundle) (*script.Version, error)
+}
@@ -0,0 +1,172 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/google/uuid"
+)
+
+// Storage holds the bytes of message media by storage key.
+type Storage interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, data []byte) error
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+type Service struct {
+	repo     Repository
+	scripts  script.Repository
+	versions script.VersionRepository
+	messages message.Repository
+	storage  Storage
+}
+
+func NewService(repo Repository, scripts script.Repository, versions script.VersionRepository, messages message.Repository, storage Storage) *Service {
+	return &Service{
+		repo:     repo,
+		scripts:  scripts,
+		versions: versions,
+		messages: messages,
+		storage:  storage,
+	}
+}
+
+// Export encodes a version of the bot's script, the published one when
+// number is 0. The archive encoding carries the media bytes, the others only
+// reference media by storage key.
+func (s *Service) Export(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string, number int, encoding string) ([]byte, error) {
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	versionID, err := s.versionID(ctx, sc, number)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.scripts.ListSteps(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := s.scripts.ListTransitions(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	var messages []*message.Message
+	media := make(map[uuid.UUID][]message.Media)
+	for _, st := range steps {
+		for _, id := range st.MessageIDs() {
+			if _, ok := media[id]; ok {
+				continue
+			}
+			msg, err := s.messages.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			files, err := s.messages.ListMedia(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, msg)
+			media[id] = files
+		}
+	}
+	b := New(sc.Name, steps, transitions, messages, media)
+
+	if encoding != EncodingArchive {
+		return Encode(b, encoding)
+	}
+	files := make(map[string][]byte)
+	for i := range b.Messages {
+		for j := range b.Messages[i].Media {
+			md := &b.Messages[i].Media[j]
+			data, err := s.storage.Get(ctx, md.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read media %s: %w", md.Key, err)
+			}
+			md.File = "media/" + md.Key
+			files[md.File] = data
+		}
+	}
+	return EncodeArchive(b, files)
+}
+
+func (s *Service) versionID(ctx context.Context, sc *script.Script, number int) (uuid.UUID, error) {
+	if number == 0 {
+		if sc.PublishedVersionID == nil {
+			return uuid.Nil, script.ErrNotPublished
+		}
+		return *sc.PublishedVersionID, nil
+	}
+	v, err := s.versions.GetByNumber(ctx, sc.ID, number)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return v.ID, nil
+}
+
+// Import validates an encoded bundle and stores it as the draft of the
+// bot's script named in the bundle, or scriptName when it is not empty.
+// Media files carried by an archive are stored under new keys; media that
+// is only referenced must already be in the storage.
+func (s *Service) Import(ctx context.Context, bot *telegram_bot.TelegramBot, data []byte, encoding, scriptName string) (*Bundle, *script.Version, error) {
+	var (
+		b     *Bundle
+		files map[string][]byte
+		err   error
+	)
+	if encoding == EncodingArchive {
+		b, files, err = DecodeArchive(data)
+	} else {
+		b, err = Decode(data, encoding)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if scriptName != "" {
+		b.Script = scriptName
+	}
+	if err := b.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range b.Messages {
+		for j := range b.Messages[i].Media {
+			md := &b.Messages[i].Media[j]
+			if md.File == "" {
+				ok, err := s.storage.Exists(ctx, md.Key)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to check media %s: %w", md.Key, err)
+				}
+				if !ok {
+					return nil, nil, fmt.Errorf("%w from the storage: %s", ErrMissingMedia, md.Key)
+				}
+				continue
+			}
+			content, ok := files[md.File]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w from the archive: %s", ErrMissingMedia, md.File)
+			}
+			key := uuid.NewString()
+			if ext := strings.TrimPrefix(md.Ext, "."); ext != "" {
+				key += "." + ext
+			}
+			if err := s.storage.Put(ctx, key, content); err != nil {
+				return nil, nil, fmt.Errorf("failed to store media %s: %w", md.File, err)
+			}
+			md.Key = key
+			md.Size = int64(len(content))
+		}
+	}
+
+	v, err := s.repo.Import(ctx, bot.ID, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, v, nil
+}
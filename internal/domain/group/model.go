@@ -0,0 +1,82 @@
+package group
+
+import (
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// Invite statuses.
+const (
+	InvitePending = "pending"
+	InviteJoined  = "joined"
+	InviteRevoked = "revoked"
+)
+
+// Join request statuses.
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestDeclined = "declined"
+)
+
+// Group is a private Telegram group or supergroup the bot administers and
+// lets script users into with personal invite links.
+type Group struct {
+	ID            uuid.UUID
+	TelegramBotID uuid.UUID
+	ChatID        int64
+	Title         string
+	// AccessTag admits users with the tag to the group by join request
+	// regardless of their scripts, e.g. "paid".
+	AccessTag string
+	// DeclineUnqualified declines join requests of users who do not qualify
+	// instead of keeping them until they do.
+	DeclineUnqualified bool
+}
+
+// JoinRequest is a request of a user to join a group that waits for the
+// user to qualify.
+type JoinRequest struct {
+	ID          uuid.UUID
+	GroupID     uuid.UUID
+	UserID      uuid.UUID
+	TelegramID  int64
+	ChatID      int64
+	BotID       int64
+	RequestedAt time.Time
+}
+
+// Decide returns the status a join request to the group gets. Users who
+// reached a step granting access to the group, or have its access tag, are
+// approved.
+func (g *Group) Decide(reachedStep bool, attrs *user.Attributes) string {
+	switch {
+	case reachedStep, g.AccessTag != "" && attrs.HasTag(g.AccessTag):
+		return JoinRequestApproved
+	case g.DeclineUnqualified:
+		return JoinRequestDeclined
+	default:
+		return JoinRequestPending
+	}
+}
+
+// Invite is a single-use, expiring invite link issued to one user.
+type Invite struct {
+	ID         uuid.UUID
+	GroupID    uuid.UUID
+	UserID     uuid.UUID
+	ProgressID *uuid.UUID
+	Link       string
+	ExpiresAt  time.Time
+}
+
+// ExpiredInvite is an unused invite past its expiry together with the chat
+// and the bot it has to be revoked through.
+type ExpiredInvite struct {
+	ID     uuid.UUID
+	Link   string
+	ChatID int64
+	BotID  int64
+}
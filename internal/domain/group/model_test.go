@@ -0,0 +1,35 @@
+package group
+
+import (
+	"testing"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+)
+
+func TestGroupDecide(t *testing.T) {
+	paid := user.NewAttributes()
+	paid.Tags["paid"] = true
+	none := user.NewAttributes()
+
+	tests := []struct {
+		name        string
+		group       Group
+		reachedStep bool
+		attrs       *user.Attributes
+		want        string
+	}{
+		{"reached step", Group{}, true, none, JoinRequestApproved},
+		{"access tag", Group{AccessTag: "paid"}, false, paid, JoinRequestApproved},
+		{"tag without access tag", Group{}, false, paid, JoinRequestPending},
+		{"queued", Group{AccessTag: "paid"}, false, none, JoinRequestPending},
+		{"declined", Group{AccessTag: "paid", DeclineUnqualified: true}, false, none, JoinRequestDeclined},
+		{"reached step beats decline", Group{DeclineUnqualified: true}, true, none, JoinRequestApproved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.group.Decide(tt.reachedStep, tt.attrs); got != tt.want {
+				t.Errorf("Decide() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package group
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*Group, error)
+	GetByChatID(ctx context.Context, telegramBotID uuid.UUID, chatID int64) (*Group, error)
+	// Save creates the group or refreshes the title of a known one.
+	Save(ctx context.Context, group *Group) error
+	SetPolicy(ctx context.Context, id uuid.UUID, accessTag string, declineUnqualified bool) error
+	// ReachedAccessStep reports whether the user was delivered a step that
+	// grants access to the group.
+	ReachedAccessStep(ctx context.Context, groupID, userID uuid.UUID) (bool, error)
+
+	CreateInvite(ctx context.Context, invite *Invite) error
+	// GetPendingInvite returns an unused invite of the user that is still
+	// valid at now.
+	GetPendingInvite(ctx context.Context, groupID, userID uuid.UUID, now time.Time) (*Invite, error)
+	// JoinInvites marks the user's pending invites to the group as used and
+	// returns how many there were.
+	JoinInvites(ctx context.Context, groupID, userID uuid.UUID, joinedAt time.Time) (int64, error)
+	ListExpiredInvites(ctx context.Context, now time.Time, limit int) ([]*ExpiredInvite, error)
+	RevokeInvite(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+
+	// SaveJoinRequest records a pending join request, reopening a decided one.
+	SaveJoinRequest(ctx context.Context, groupID, userID uuid.UUID, requestedAt time.Time) error
+	DecideJoinRequest(ctx context.Context, groupID, userID uuid.UUID, status string, decidedAt time.Time) error
+	// ListPendingJoinRequests returns up to limit pending requests that come
+	// after the given one in (RequestedAt, ID) order; pass nil for the first
+	// page.
+	ListPendingJoinRequests(ctx context.Context, after *JoinRequest, limit int) ([]*JoinRequest, error)
+}
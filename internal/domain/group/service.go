@@ -0,0 +1,270 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Chats manages Telegram chats on behalf of a bot.
+type Chats interface {
+	// CreateInviteLink creates a link that admits one member until expireAt.
+	CreateInviteLink(ctx context.Context, botID, chatID int64, name string, expireAt time.Time) (string, error)
+	RevokeInviteLink(ctx context.Context, botID, chatID int64, link string) error
+	ChatTitle(ctx context.Context, botID, chatID int64) (string, error)
+	ApproveJoinRequest(ctx context.Context, botID, chatID, userID int64) error
+	DeclineJoinRequest(ctx context.Context, botID, chatID, userID int64) error
+}
+
+type Service struct {
+	repo       Repository
+	users      user.Repository
+	attributes user.AttributeRepository
+	chats      Chats
+	inviteTTL  time.Duration
+	logger     logger.Logger
+}
+
+func NewService(repo Repository, users user.Repository, attributes user.AttributeRepository, chats Chats, inviteTTL time.Duration, logger logger.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		users:      users,
+		attributes: attributes,
+		chats:      chats,
+		inviteTTL:  inviteTTL,
+		logger:     logger,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*Group, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Register remembers a group of the bot. The bot has to be a member of the
+// chat, which is checked by reading its title.
+func (s *Service) Register(ctx context.Context, bot *telegram_bot.TelegramBot, chatID int64) (*Group, error) {
+	title, err := s.chats.ChatTitle(ctx, bot.BotID, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat %d: %w", chatID, err)
+	}
+	g := &Group{
+		TelegramBotID: bot.ID,
+		ChatID:        chatID,
+		Title:         title,
+	}
+	if err := s.repo.Save(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetPolicy sets who the bot lets into its group by join request: users
+// with accessTag besides those who reached an access step, with the rest
+// declined or kept waiting. An empty accessTag admits by steps only.
+func (s *Service) SetPolicy(ctx context.Context, bot *telegram_bot.TelegramBot, chatID int64, accessTag string, declineUnqualified bool) (*Group, error) {
+	g, err := s.repo.GetByChatID(ctx, bot.ID, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if accessTag != "" {
+		accessTag = user.NormalizeKey(accessTag)
+		if err := user.ValidateKey(accessTag); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.SetPolicy(ctx, g.ID, accessTag, declineUnqualified); err != nil {
+		return nil, err
+	}
+	g.AccessTag = accessTag
+	g.DeclineUnqualified = declineUnqualified
+	return g, nil
+}
+
+// Invite returns a personal invite link of u to the group, reusing one that
+// is still valid.
+func (s *Service) Invite(ctx context.Context, bot *telegram_bot.TelegramBot, groupID uuid.UUID, u *user.User, progressID uuid.UUID) (string, error) {
+	now := time.Now().UTC()
+	existing, err := s.repo.GetPendingInvite(ctx, groupID, u.ID, now)
+	if err == nil {
+		return existing.Link, nil
+	}
+	if !errors.Is(err, app_errors.ErrNotFound) {
+		return "", err
+	}
+
+	g, err := s.repo.GetByID(ctx, groupID)
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := now.Add(s.inviteTTL)
+	// link names are shown to group admins and limited to 32 characters
+	name := fmt.Sprintf("user %d", u.TelegramID)
+	link, err := s.chats.CreateInviteLink(ctx, bot.BotID, g.ChatID, name, expiresAt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create invite link: %w", err)
+	}
+
+	err = s.repo.CreateInvite(ctx, &Invite{
+		GroupID:    g.ID,
+		UserID:     u.ID,
+		ProgressID: &progressID,
+		Link:       link,
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		if revokeErr := s.chats.RevokeInviteLink(ctx, bot.BotID, g.ChatID, link); revokeErr != nil {
+			s.logger.Warn("failed to revoke unsaved invite link", zap.Error(revokeErr))
+		}
+		return "", err
+	}
+	return link, nil
+}
+
+// HandleJoin records that the Telegram user joined a chat of the bot. Chats
+// that are not registered groups and unknown users are ignored.
+func (s *Service) HandleJoin(ctx context.Context, bot *telegram_bot.TelegramBot, chatID, telegramUserID int64) error {
+	g, err := s.repo.GetByChatID(ctx, bot.ID, chatID)
+	if errors.Is(err, app_errors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	u, err := s.users.GetByTelegramID(ctx, &telegramUserID)
+	if errors.Is(err, app_errors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	joined, err := s.repo.JoinInvites(ctx, g.ID, u.ID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if joined > 0 {
+		s.logger.Info("user joined private group",
+			zap.String("group_id", g.ID.String()),
+			zap.String("user_id", u.ID.String()))
+	}
+	return nil
+}
+
+// RevokeExpired revokes up to limit unused invite links that expired by now
+// and returns how many it handled.
+func (s *Service) RevokeExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	invites, err := s.repo.ListExpiredInvites(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, inv := range invites {
+		// Telegram already rejects expired links, revoking them keeps the
+		// chat's link list clean; a failure is not worth retrying forever.
+		if err := s.chats.RevokeInviteLink(ctx, inv.BotID, inv.ChatID, inv.Link); err != nil {
+			s.logger.Warn("failed to revoke invite link",
+				zap.String("invite_id", inv.ID.String()),
+				zap.Error(err))
+		}
+		if err := s.repo.RevokeInvite(ctx, inv.ID, now); err != nil {
+			return 0, err
+		}
+	}
+	return len(invites), nil
+}
+
+// HandleJoinRequest approves the request of u to join a chat of the bot when
+// the user qualifies for the group, and declines or keeps it otherwise.
+// Requests to chats that are not registered groups are left to the chat's
+// administrators.
+func (s *Service) HandleJoinRequest(ctx context.Context, bot *telegram_bot.TelegramBot, chatID int64, u *user.User) error {
+	g, err := s.repo.GetByChatID(ctx, bot.ID, chatID)
+	if errors.Is(err, app_errors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if err := s.repo.SaveJoinRequest(ctx, g.ID, u.ID, now); err != nil {
+		return err
+	}
+	return s.decide(ctx, g, &JoinRequest{
+		GroupID:    g.ID,
+		UserID:     u.ID,
+		TelegramID: u.TelegramID,
+		ChatID:     chatID,
+		BotID:      bot.BotID,
+	}, now)
+}
+
+// ReviewJoinRequests decides waiting join requests again, approving users who
+// qualified since they asked.
+func (s *Service) ReviewJoinRequests(ctx context.Context, now time.Time, batchSize int) error {
+	groups := make(map[uuid.UUID]*Group)
+	// requests that stay pending are paged past by the cursor instead of
+	// being listed again
+	var after *JoinRequest
+	for {
+		requests, err := s.repo.ListPendingJoinRequests(ctx, after, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, req := range requests {
+			g, ok := groups[req.GroupID]
+			if !ok {
+				g, err = s.repo.GetByID(ctx, req.GroupID)
+				if err != nil {
+					return err
+				}
+				groups[req.GroupID] = g
+			}
+			if err := s.decide(ctx, g, req, now); err != nil {
+				// the request stays pending and is retried on the next review
+				s.logger.Warn("failed to review join request",
+					zap.String("group_id", req.GroupID.String()),
+					zap.String("user_id", req.UserID.String()),
+					zap.Error(err))
+			}
+		}
+		if len(requests) < batchSize {
+			return nil
+		}
+		after = requests[len(requests)-1]
+	}
+}
+
+func (s *Service) decide(ctx context.Context, g *Group, req *JoinRequest, now time.Time) error {
+	reached, err := s.repo.ReachedAccessStep(ctx, g.ID, req.UserID)
+	if err != nil {
+		return err
+	}
+	attrs, err := s.attributes.Get(ctx, req.UserID, g.TelegramBotID)
+	if err != nil {
+		return err
+	}
+
+	status := g.Decide(reached, attrs)
+	switch status {
+	case JoinRequestApproved:
+		if err := s.chats.ApproveJoinRequest(ctx, req.BotID, req.ChatID, req.TelegramID); err != nil {
+			return fmt.Errorf("failed to approve join request: %w", err)
+		}
+	case JoinRequestDeclined:
+		if err := s.chats.DeclineJoinRequest(ctx, req.BotID, req.ChatID, req.TelegramID); err != nil {
+			return fmt.Errorf("failed to decline join request: %w", err)
+		}
+	default:
+		return nil
+	}
+	return s.repo.DecideJoinRequest(ctx, g.ID, req.UserID, status, now)
+}
@@ -0,0 +1,5 @@
+package inbox
+
+import "errors"
+
+var ErrEmptyAnswer = errors.New("only text answers can be sent")
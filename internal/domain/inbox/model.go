@@ -0,0 +1,58 @@
+package inbox
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// Message directions.
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+// Thread is the conversation of a user with the admins of a bot.
+type Thread struct {
+	ID            uuid.UUID
+	TelegramBotID uuid.UUID
+	UserID        uuid.UUID
+	// BotID and UserTelegramID are the Telegram IDs answers are sent with.
+	BotID          int64
+	UserTelegramID int64
+}
+
+// Message is a message of a thread, sent by the user or answered by an admin.
+type Message struct {
+	ThreadID    uuid.UUID
+	Direction   string
+	Text        string
+	AdminUserID *uuid.UUID
+}
+
+// Relay is a copy of a user's message posted to an admin or a support group.
+// An answer to the copy goes back to the thread's user.
+type Relay struct {
+	RelayBotID int64
+	ChatID     int64
+	MessageID  int
+	ThreadID   uuid.UUID
+}
+
+// RelayText formats a user's message to the bot for its admins.
+func RelayText(botUsername string, u *user.User, text string) string {
+	from := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	switch {
+	case u.Username != "" && from != "":
+		from = fmt.Sprintf("%s (@%s, %d)", from, u.Username, u.TelegramID)
+	case u.Username != "":
+		from = fmt.Sprintf("@%s (%d)", u.Username, u.TelegramID)
+	case from != "":
+		from = fmt.Sprintf("%s (%d)", from, u.TelegramID)
+	default:
+		from = fmt.Sprintf("%d", u.TelegramID)
+	}
+	return fmt.Sprintf("%s wrote to @%s:\n\n%s\n\nReply to this message to answer.", from, botUsername, text)
+}
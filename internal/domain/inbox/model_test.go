@@ -0,0 +1,28 @@
+package inbox
+
+import (
+	"testing"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+)
+
+func TestRelayText(t *testing.T) {
+	tests := []struct {
+		name string
+		user user.User
+		want string
+	}{
+		{"name and username", user.User{TelegramID: 42, Username: "ann", FirstName: "Ann", LastName: "Lee"}, "Ann Lee (@ann, 42)"},
+		{"username", user.User{TelegramID: 42, Username: "ann"}, "@ann (42)"},
+		{"first name", user.User{TelegramID: 42, FirstName: "Ann"}, "Ann (42)"},
+		{"nothing", user.User{TelegramID: 42}, "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want + " wrote to @shop_bot:\n\nhello\n\nReply to this message to answer."
+			if got := RelayText("shop_bot", &tt.user, "hello"); got != want {
+				t.Errorf("RelayText() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package inbox
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// SaveThread returns the ID of the thread of the user with the bot,
+	// starting it on the first message.
+	SaveThread(ctx context.Context, botID, userID uuid.UUID) (uuid.UUID, error)
+	AddMessage(ctx context.Context, m *Message) error
+	AddRelay(ctx context.Context, r *Relay) error
+	// GetThreadByRelay returns the thread of the message relayed by the bot
+	// with Telegram ID relayBotID to chatID.
+	GetThreadByRelay(ctx context.Context, relayBotID, chatID int64, messageID int) (*Thread, error)
+
+	// ListRecipients returns the Telegram IDs of the bot's owners and admins.
+	ListRecipients(ctx context.Context, botID uuid.UUID) ([]int64, error)
+	// AdminBotID returns the Telegram ID of the active admin bot.
+	AdminBotID(ctx context.Context) (int64, error)
+	// SupportChat returns the support group of the bot, 0 when there is none.
+	SupportChat(ctx context.Context, botID uuid.UUID) (int64, error)
+	SetSupportChat(ctx context.Context, botID uuid.UUID, chatID int64) error
+}
@@ -0,0 +1,152 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/telegram"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Sender sends messages through a bot and returns their IDs.
+type Sender interface {
+	Send(ctx context.Context, botID int64, msg telegram.OutgoingMessage) (int, error)
+}
+
+// Chats reads Telegram chats on behalf of a bot.
+type Chats interface {
+	ChatTitle(ctx context.Context, botID, chatID int64) (string, error)
+}
+
+type Service struct {
+	repo   Repository
+	sender Sender
+	chats  Chats
+	logger logger.Logger
+}
+
+func NewService(repo Repository, sender Sender, chats Chats, logger logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		sender: sender,
+		chats:  chats,
+		logger: logger,
+	}
+}
+
+// Receive stores a message the user wrote to the bot and relays it to the
+// bot's support group, or through the admin bot to its owners and admins
+// when the bot has no support group.
+func (s *Service) Receive(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User, text string) error {
+	threadID, err := s.repo.SaveThread(ctx, bot.ID, u.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.AddMessage(ctx, &Message{ThreadID: threadID, Direction: DirectionIn, Text: text}); err != nil {
+		return err
+	}
+
+	relayBotID, chatIDs, err := s.relayTargets(ctx, bot)
+	if err != nil {
+		return err
+	}
+	relayText := RelayText(bot.Username, u, text)
+	for _, chatID := range chatIDs {
+		msgID, err := s.sender.Send(ctx, relayBotID, telegram.OutgoingMessage{ChatID: chatID, Text: relayText})
+		if err != nil {
+			// admins who never started the admin bot cannot be messaged
+			s.logger.Warn("failed to relay inbox message",
+				zap.String("bot_id", bot.ID.String()),
+				zap.Int64("chat_id", chatID),
+				zap.Error(err))
+			continue
+		}
+		err = s.repo.AddRelay(ctx, &Relay{RelayBotID: relayBotID, ChatID: chatID, MessageID: msgID, ThreadID: threadID})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relayTargets returns the bot that relays messages of the bot's users and
+// the chats it relays them to.
+func (s *Service) relayTargets(ctx context.Context, bot *telegram_bot.TelegramBot) (int64, []int64, error) {
+	supportChat, err := s.repo.SupportChat(ctx, bot.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if supportChat != 0 {
+		return bot.BotID, []int64{supportChat}, nil
+	}
+
+	recipients, err := s.repo.ListRecipients(ctx, bot.ID)
+	if err != nil || len(recipients) == 0 {
+		return 0, nil, err
+	}
+	adminBotID, err := s.repo.AdminBotID(ctx)
+	if errors.Is(err, app_errors.ErrNotFound) {
+		s.logger.Warn("no admin bot to relay inbox messages", zap.String("bot_id", bot.ID.String()))
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return adminBotID, recipients, nil
+}
+
+// Reply sends an admin's answer to a relayed message back to the user through
+// the bot the user wrote to. relayBotID and chatID identify where the relayed
+// message was posted. It returns app_errors.ErrNotFound when the message
+// answered is not a relayed one, and ErrEmptyAnswer when the answer has no
+// text.
+func (s *Service) Reply(ctx context.Context, relayBotID, chatID int64, messageID int, admin *user.User, text string) (*Thread, error) {
+	thread, err := s.repo.GetThreadByRelay(ctx, relayBotID, chatID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyAnswer
+	}
+	if _, err := s.sender.Send(ctx, thread.BotID, telegram.OutgoingMessage{ChatID: thread.UserTelegramID, Text: text}); err != nil {
+		return nil, fmt.Errorf("failed to send answer: %w", err)
+	}
+	m := &Message{
+		ThreadID:    thread.ID,
+		Direction:   DirectionOut,
+		Text:        text,
+		AdminUserID: &admin.ID,
+	}
+	if err := s.repo.AddMessage(ctx, m); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+func (s *Service) SupportChat(ctx context.Context, bot *telegram_bot.TelegramBot) (int64, error) {
+	return s.repo.SupportChat(ctx, bot.ID)
+}
+
+// SetSupportChat makes chatID the support group of the bot and returns its
+// title. The bot has to be a member of the chat, which is checked by reading
+// the title. A chatID of 0 relays messages through the admin bot again.
+func (s *Service) SetSupportChat(ctx context.Context, bot *telegram_bot.TelegramBot, chatID int64) (string, error) {
+	var title string
+	if chatID != 0 {
+		var err error
+		title, err = s.chats.ChatTitle(ctx, bot.BotID, chatID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get chat %d: %w", chatID, err)
+		}
+	}
+	if err := s.repo.SetSupportChat(ctx, bot.ID, chatID); err != nil {
+		return "", err
+	}
+	return title, nil
+}
@@ -0,0 +1,10 @@
+package message
+
+import "errors"
+
+var (
+	ErrInvalidTemplate  = errors.New("invalid message template")
+	ErrInvalidParseMode = errors.New("parse mode must be HTML, MarkdownV2 or empty")
+	ErrPublished        = errors.New("message belongs to a published script version, edit it in a draft")
+	ErrInvalidRedirect  = errors.New("invalid redirect token")
+)
@@ -0,0 +1,98 @@
+package message
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Message struct {
+	ID      uuid.UUID
+	Content string
+	// ParseMode is how Telegram formats Content: ParseModeHTML,
+	// ParseModeMarkdownV2 or empty for plain text.
+	ParseMode string
+	Buttons   []Button
+}
+
+// Validate checks the parse mode and the templates of the content and the
+// button texts.
+func (m *Message) Validate() error {
+	switch m.ParseMode {
+	case "", ParseModeHTML, ParseModeMarkdownV2:
+	default:
+		return fmt.Errorf("%w, got: %q", ErrInvalidParseMode, m.ParseMode)
+	}
+	if _, err := ParseTemplate(m.Content); err != nil {
+		return err
+	}
+	for _, b := range m.Buttons {
+		if _, err := ParseTemplate(b.Text); err != nil {
+			return fmt.Errorf("button %q: %w", b.Text, err)
+		}
+		if b.TrackClicks && b.URL == "" {
+			return fmt.Errorf("button %q tracks clicks but has no URL", b.Text)
+		}
+	}
+	return nil
+}
+
+// Button is an inline keyboard button. Buttons with a URL open it, the rest
+// send a callback to the bot.
+type Button struct {
+	ID        uuid.UUID
+	MessageID uuid.UUID
+	Text      string
+	URL       string
+	// SetAttribute is the user attribute a press sets, to SetValue or to the
+	// button text when SetValue is empty.
+	SetAttribute string
+	SetValue     string
+	// AddTag is the tag a press adds to the user.
+	AddTag string
+	// TrackClicks sends URL button presses through the redirect endpoint so
+	// they are recorded, see Redirects.
+	TrackClicks bool
+}
+
+// Click is a press of a button. StepID and DeliveryID are known for URL
+// buttons followed through the redirect endpoint.
+type Click struct {
+	ButtonID   uuid.UUID
+	UserID     uuid.UUID
+	StepID     *uuid.UUID
+	DeliveryID *uuid.UUID
+	ClickedAt  time.Time
+}
+
+// Media kinds. The kind selects how the file is sent, the message content
+// becomes its caption.
+const (
+	MediaPhoto     = "photo"
+	MediaVideo     = "video"
+	MediaAnimation = "animation"
+	MediaAudio     = "audio"
+	MediaVoice     = "voice"
+	MediaDocument  = "document"
+)
+
+// Media is a file attached to a message. StorageKey locates its bytes in the
+// media storage.
+type Media struct {
+	ID         uuid.UUID
+	MessageID  uuid.UUID
+	StorageKey string
+	Ext        string
+	Size       int64
+	MimeType   string
+	Kind       string
+}
+
+// AttributeValue is the value a press sets SetAttribute to.
+func (b *Button) AttributeValue() string {
+	if b.SetValue != "" {
+		return b.SetValue
+	}
+	return b.Text
+}
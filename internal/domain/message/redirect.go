@@ -0,0 +1,76 @@
+package message
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RedirectPath is the path of the redirect endpoint, the token follows it.
+const RedirectPath = "/r/"
+
+// signatureSize is the length of the truncated HMAC-SHA256 of a token.
+const signatureSize = 16
+
+// Redirect identifies a URL button sent to a user in a step delivery.
+type Redirect struct {
+	ButtonID   uuid.UUID
+	UserID     uuid.UUID
+	StepID     uuid.UUID
+	DeliveryID uuid.UUID
+}
+
+// Redirects signs links to the redirect endpoint, which records the click of
+// a button and sends the user on to its URL. Tokens are signed with a secret
+// so users cannot forge clicks of others.
+type Redirects struct {
+	baseURL string
+	secret  []byte
+}
+
+// NewRedirects signs links to the redirect endpoint served at baseURL.
+func NewRedirects(baseURL, secret string) *Redirects {
+	return &Redirects{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  []byte(secret),
+	}
+}
+
+// URL returns the signed link to the redirect endpoint for rd.
+func (r *Redirects) URL(rd Redirect) string {
+	payload := make([]byte, 0, 4*len(uuid.UUID{})+signatureSize)
+	payload = append(payload, rd.ButtonID[:]...)
+	payload = append(payload, rd.UserID[:]...)
+	payload = append(payload, rd.StepID[:]...)
+	payload = append(payload, rd.DeliveryID[:]...)
+	token := append(payload, r.sign(payload)...)
+	return r.baseURL + RedirectPath + base64.RawURLEncoding.EncodeToString(token)
+}
+
+// Parse verifies the signature of a token and returns the redirect it was
+// issued for.
+func (r *Redirects) Parse(token string) (Redirect, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != 4*len(uuid.UUID{})+signatureSize {
+		return Redirect{}, ErrInvalidRedirect
+	}
+	payload, signature := raw[:len(raw)-signatureSize], raw[len(raw)-signatureSize:]
+	if !hmac.Equal(signature, r.sign(payload)) {
+		return Redirect{}, ErrInvalidRedirect
+	}
+
+	var rd Redirect
+	for i, id := range []*uuid.UUID{&rd.ButtonID, &rd.UserID, &rd.StepID, &rd.DeliveryID} {
+		copy(id[:], payload[i*len(id):])
+	}
+	return rd, nil
+}
+
+func (r *Redirects) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)[:signatureSize]
+}
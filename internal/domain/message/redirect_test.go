@@ -0,0 +1,44 @@
+package message
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRedirects(t *testing.T) {
+	redirects := NewRedirects("https://bots.example.com/", "0123456789abcdef0123456789abcdef")
+	rd := Redirect{ButtonID: uuid.New(), UserID: uuid.New(), StepID: uuid.New(), DeliveryID: uuid.New()}
+
+	link := redirects.URL(rd)
+	token, ok := strings.CutPrefix(link, "https://bots.example.com"+RedirectPath)
+	if !ok {
+		t.Fatalf("URL() = %q, want a link under the redirect path", link)
+	}
+	got, err := redirects.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got != rd {
+		t.Errorf("Parse() = %+v, want %+v", got, rd)
+	}
+
+	tampered := []byte(token)
+	tampered[0] ^= 'A' ^ 'B'
+	other := NewRedirects("https://bots.example.com", "another secret of thirty-two chars")
+	for name, tt := range map[string]struct {
+		r     *Redirects
+		token string
+	}{
+		"tampered":     {redirects, string(tampered)},
+		"other secret": {other, token},
+		"truncated":    {redirects, token[:len(token)-4]},
+		"not base64":   {redirects, "%%%"},
+	} {
+		if _, err := tt.r.Parse(tt.token); !errors.Is(err, ErrInvalidRedirect) {
+			t.Errorf("%s: Parse() error = %v, want ErrInvalidRedirect", name, err)
+		}
+	}
+}
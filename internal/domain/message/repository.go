@@ -0,0 +1,26 @@
+package message
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// GetByID returns the message together with its buttons.
+	GetByID(ctx context.Context, id uuid.UUID) (*Message, error)
+	GetButton(ctx context.Context, id uuid.UUID) (*Button, error)
+	// Create stores the message with its buttons.
+	Create(ctx context.Context, msg *Message) error
+	// Update changes the content and the parse mode of the message.
+	Update(ctx context.Context, msg *Message) error
+	// ListMedia returns the media files attached to the message.
+	ListMedia(ctx context.Context, messageID uuid.UUID) ([]Media, error)
+	// CreateMedia attaches a stored media file to a message.
+	CreateMedia(ctx context.Context, media *Media) error
+	// IsPublished reports whether a step of a published script version
+	// sends the message.
+	IsPublished(ctx context.Context, id uuid.UUID) (bool, error)
+	// CreateClick records a press of a button.
+	CreateClick(ctx context.Context, click *Click) error
+}
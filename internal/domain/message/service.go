@@ -0,0 +1,133 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Storage holds the bytes of message media by storage key.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// File is the content of a media file attached to a new message.
+type File struct {
+	Kind     string
+	Ext      string
+	MimeType string
+	Data     []byte
+}
+
+type Service struct {
+	repo      Repository
+	storage   Storage
+	redirects *Redirects
+}
+
+// NewService creates the message service. redirects may be nil when the
+// redirect endpoint is not configured.
+func NewService(repo Repository, storage Storage, redirects *Redirects) *Service {
+	return &Service{
+		repo:      repo,
+		storage:   storage,
+		redirects: redirects,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*Message, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create validates and creates the message with its buttons and attaches
+// files to it. The files are stored first, so a failure leaves no message
+// without its media.
+func (s *Service) Create(ctx context.Context, msg *Message, files []File) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	media := make([]Media, 0, len(files))
+	for _, f := range files {
+		ext := strings.TrimPrefix(f.Ext, ".")
+		kind := f.Kind
+		if kind == "" {
+			kind = MediaDocument
+		}
+		key := uuid.NewString()
+		if ext != "" {
+			key += "." + ext
+		}
+		if err := s.storage.Put(ctx, key, f.Data); err != nil {
+			return fmt.Errorf("failed to store media: %w", err)
+		}
+		media = append(media, Media{
+			StorageKey: key,
+			Ext:        ext,
+			Size:       int64(len(f.Data)),
+			MimeType:   f.MimeType,
+			Kind:       kind,
+		})
+	}
+
+	if err := s.repo.Create(ctx, msg); err != nil {
+		return err
+	}
+	for i := range media {
+		media[i].MessageID = msg.ID
+		if err := s.repo.CreateMedia(ctx, &media[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save validates the message and creates it, or updates its content and
+// parse mode when it already has an ID. Messages of published script
+// versions cannot be changed.
+func (s *Service) Save(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	if msg.ID == uuid.Nil {
+		return s.repo.Create(ctx, msg)
+	}
+	published, err := s.repo.IsPublished(ctx, msg.ID)
+	if err != nil {
+		return err
+	}
+	if published {
+		return ErrPublished
+	}
+	return s.repo.Update(ctx, msg)
+}
+
+// Follow records the click of a redirect token and returns the URL of its
+// button. The URL is returned even when recording the click fails so the
+// user still gets through.
+func (s *Service) Follow(ctx context.Context, token string) (string, error) {
+	if s.redirects == nil {
+		return "", ErrInvalidRedirect
+	}
+	rd, err := s.redirects.Parse(token)
+	if err != nil {
+		return "", err
+	}
+	b, err := s.repo.GetButton(ctx, rd.ButtonID)
+	if err != nil {
+		return "", err
+	}
+	if b.URL == "" {
+		return "", ErrInvalidRedirect
+	}
+
+	return b.URL, s.repo.CreateClick(ctx, &Click{
+		ButtonID:   b.ID,
+		UserID:     rd.UserID,
+		StepID:     &rd.StepID,
+		DeliveryID: &rd.DeliveryID,
+		ClickedAt:  time.Now().UTC(),
+	})
+}
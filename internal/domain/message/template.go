@@ -0,0 +1,258 @@
+package message
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+)
+
+// Parse modes of message content, as Telegram names them. An empty parse mode
+// sends the content as plain text.
+const (
+	ParseModeHTML       = "HTML"
+	ParseModeMarkdownV2 = "MarkdownV2"
+)
+
+// Template variables.
+const (
+	VarFirstName   = "first_name"
+	VarLastName    = "last_name"
+	VarFullName    = "full_name"
+	VarUsername    = "username"
+	VarBotName     = "bot_name"
+	VarBotUsername = "bot_username"
+	// VarDate and VarDateTime render the current date, optionally shifted by
+	// a duration: {{date+72h}}, {{datetime+30m}}, {{date+3d}}.
+	VarDate     = "date"
+	VarDateTime = "datetime"
+	// VarAttrPrefix reads a custom user attribute: {{attr.interested_in}}.
+	VarAttrPrefix = "attr."
+)
+
+const (
+	dateLayout     = "02.01.2006"
+	dateTimeLayout = "02.01.2006 15:04"
+)
+
+// Vars are the values a template is rendered with.
+type Vars struct {
+	FirstName   string
+	LastName    string
+	Username    string
+	BotName     string
+	BotUsername string
+	Attributes  map[string]string
+	// Now and Location are used for dates, which are shown in the user's
+	// timezone.
+	Now      time.Time
+	Location *time.Location
+}
+
+// Template is message content with placeholders such as {{first_name}} or
+// {{attr.city|your city}}. The text after "|" is shown when the value is
+// empty. Literal braces are written as {{{{ and }}}}.
+type Template struct {
+	parts []part
+}
+
+type part struct {
+	text        string
+	placeholder *placeholder
+}
+
+type placeholder struct {
+	name     string
+	shift    time.Duration
+	fallback string
+}
+
+// ParseTemplate checks the placeholders of s. It rejects unknown variables
+// and unclosed braces so a broken template is caught when the message is
+// saved rather than when it is sent.
+func ParseTemplate(s string) (*Template, error) {
+	t := &Template{}
+	var text strings.Builder
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, "{{{{"):
+			text.WriteString("{{")
+			s = s[4:]
+		case strings.HasPrefix(s, "}}}}"):
+			text.WriteString("}}")
+			s = s[4:]
+		case strings.HasPrefix(s, "{{"):
+			end := strings.Index(s, "}}")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed placeholder %q", ErrInvalidTemplate, truncate(s, 20))
+			}
+			ph, err := parsePlaceholder(s[2:end])
+			if err != nil {
+				return nil, err
+			}
+			if text.Len() > 0 {
+				t.parts = append(t.parts, part{text: text.String()})
+				text.Reset()
+			}
+			t.parts = append(t.parts, part{placeholder: ph})
+			s = s[end+2:]
+		case strings.HasPrefix(s, "}}"):
+			return nil, fmt.Errorf("%w: unexpected }}, write }}}} for literal braces", ErrInvalidTemplate)
+		default:
+			text.WriteByte(s[0])
+			s = s[1:]
+		}
+	}
+	if text.Len() > 0 {
+		t.parts = append(t.parts, part{text: text.String()})
+	}
+	return t, nil
+}
+
+func parsePlaceholder(s string) (*placeholder, error) {
+	expr, fallback, _ := strings.Cut(s, "|")
+	ph := &placeholder{
+		name:     strings.TrimSpace(expr),
+		fallback: strings.TrimSpace(fallback),
+	}
+
+	switch {
+	case ph.name == "":
+		return nil, fmt.Errorf("%w: empty placeholder", ErrInvalidTemplate)
+	case strings.HasPrefix(ph.name, VarAttrPrefix):
+		if err := user.ValidateKey(strings.TrimPrefix(ph.name, VarAttrPrefix)); err != nil {
+			return nil, fmt.Errorf("%w: {{%s}}: %v", ErrInvalidTemplate, ph.name, err)
+		}
+		return ph, nil
+	}
+
+	name, shift, hasShift := strings.Cut(ph.name, "+")
+	name = strings.TrimSpace(name)
+	switch name {
+	case VarFirstName, VarLastName, VarFullName, VarUsername, VarBotName, VarBotUsername:
+		if hasShift {
+			return nil, fmt.Errorf("%w: {{%s}} does not take a duration", ErrInvalidTemplate, name)
+		}
+	case VarDate, VarDateTime:
+		if hasShift {
+			d, err := parseShift(strings.TrimSpace(shift))
+			if err != nil {
+				return nil, fmt.Errorf("%w: {{%s}}: %v", ErrInvalidTemplate, ph.name, err)
+			}
+			ph.shift = d
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown variable {{%s}}", ErrInvalidTemplate, ph.name)
+	}
+	ph.name = name
+	return ph, nil
+}
+
+// parseShift parses a Go duration and additionally accepts whole days: "3d".
+func parseShift(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration must not be negative: %s", s)
+	}
+	return d, nil
+}
+
+// Render substitutes the placeholders with vars, escaping every value for
+// parseMode. The literal text of the template is left as written, so it may
+// contain markup.
+func (t *Template) Render(vars Vars, parseMode string) string {
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.placeholder == nil {
+			b.WriteString(p.text)
+			continue
+		}
+		value := p.placeholder.value(vars)
+		if value == "" {
+			value = p.placeholder.fallback
+		}
+		b.WriteString(Escape(value, parseMode))
+	}
+	return b.String()
+}
+
+func (p *placeholder) value(vars Vars) string {
+	if key, ok := strings.CutPrefix(p.name, VarAttrPrefix); ok {
+		return vars.Attributes[key]
+	}
+	switch p.name {
+	case VarFirstName:
+		return vars.FirstName
+	case VarLastName:
+		return vars.LastName
+	case VarFullName:
+		return strings.TrimSpace(vars.FirstName + " " + vars.LastName)
+	case VarUsername:
+		if vars.Username == "" {
+			return ""
+		}
+		return "@" + vars.Username
+	case VarBotName:
+		return vars.BotName
+	case VarBotUsername:
+		if vars.BotUsername == "" {
+			return ""
+		}
+		return "@" + vars.BotUsername
+	case VarDate, VarDateTime:
+		loc := vars.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+		at := vars.Now.Add(p.shift).In(loc)
+		if p.name == VarDate {
+			return at.Format(dateLayout)
+		}
+		return at.Format(dateTimeLayout)
+	}
+	return ""
+}
+
+// markdownV2Special are the characters Telegram requires to be escaped in
+// MarkdownV2 text.
+const markdownV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// Escape makes s safe to embed in text of the given parse mode.
+func Escape(s, parseMode string) string {
+	switch parseMode {
+	case ParseModeHTML:
+		return html.EscapeString(s)
+	case ParseModeMarkdownV2:
+		var b strings.Builder
+		for _, r := range s {
+			if strings.ContainsRune(markdownV2Special, r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	default:
+		return s
+	}
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
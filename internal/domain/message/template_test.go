@@ -0,0 +1,79 @@
+package message
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTemplateRender(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := Vars{
+		FirstName:   "Ann <3",
+		Username:    "ann_b",
+		BotName:     "Promo",
+		BotUsername: "promo_bot",
+		Attributes:  map[string]string{"interested_in": "course_b"},
+		Now:         time.Date(2026, 1, 10, 22, 30, 0, 0, time.UTC),
+		Location:    moscow,
+	}
+
+	tests := []struct {
+		template  string
+		parseMode string
+		want      string
+	}{
+		{"Hi, {{first_name}}!", "", "Hi, Ann <3!"},
+		{"<b>Hi, {{ first_name }}</b>", ParseModeHTML, "<b>Hi, Ann &lt;3</b>"},
+		{"*{{username}}* picked {{attr.interested_in}}", ParseModeMarkdownV2, `*@ann\_b* picked course\_b`},
+		{"From {{bot_name}} {{bot_username}}", "", "From Promo @promo_bot"},
+		{"City: {{attr.city|unknown}}", "", "City: unknown"},
+		{"Until {{date+3d}}", ParseModeMarkdownV2, `Until 14\.01\.2026`},
+		{"At {{datetime+2h}}", "", "At 11.01.2026 03:30"},
+		{"{{{{literal}}}}", "", "{{literal}}"},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate(tt.template)
+		if err != nil {
+			t.Errorf("ParseTemplate(%q): %v", tt.template, err)
+			continue
+		}
+		if got := tmpl.Render(vars, tt.parseMode); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	for _, in := range []string{
+		"Hi, {{first_name",
+		"Hi, {{}}",
+		"Hi, {{name}}",
+		"{{attr.Bad-Key}}",
+		"{{first_name+1h}}",
+		"{{date+tomorrow}}",
+		"stray }}",
+	} {
+		if _, err := ParseTemplate(in); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("ParseTemplate(%q) = %v, want ErrInvalidTemplate", in, err)
+		}
+	}
+}
+
+func TestMessageValidate(t *testing.T) {
+	msg := &Message{Content: "Hi", ParseMode: "Markdown"}
+	if err := msg.Validate(); !errors.Is(err, ErrInvalidParseMode) {
+		t.Errorf("expected ErrInvalidParseMode, got %v", err)
+	}
+	msg = &Message{Content: "Hi", Buttons: []Button{{Text: "{{nope}}"}}}
+	if err := msg.Validate(); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("expected ErrInvalidTemplate for button, got %v", err)
+	}
+	msg = &Message{Content: "Hi", Buttons: []Button{{Text: "Go", TrackClicks: true}}}
+	if err := msg.Validate(); err == nil {
+		t.Error("expected an error for a tracked button without URL")
+	}
+}
@@ -0,0 +1,35 @@
+package script
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// DeepLink routes /start <payload> on a bot to a script and records the
+// acquisition source of users who come by it.
+type DeepLink struct {
+	ID            uuid.UUID
+	TelegramBotID uuid.UUID
+	Payload       string
+	ScriptID      uuid.UUID
+	Source        string
+}
+
+// Telegram allows up to 64 characters A-Z, a-z, 0-9, _ and - in start
+// parameters.
+var payloadRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidatePayload checks that payload can be passed in a t.me start link.
+func ValidatePayload(payload string) error {
+	if !payloadRe.MatchString(payload) {
+		return fmt.Errorf("%w: %q", ErrInvalidPayload, payload)
+	}
+	return nil
+}
+
+// URL returns the t.me link that starts the bot with the payload.
+func (l *DeepLink) URL(botUsername string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", botUsername, l.Payload)
+}
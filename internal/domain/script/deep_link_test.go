@@ -0,0 +1,20 @@
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePayload(t *testing.T) {
+	for _, payload := range []string{"ads_vk", "Summer-2026", strings.Repeat("a", 64)} {
+		if err := ValidatePayload(payload); err != nil {
+			t.Errorf("ValidatePayload(%q): %v", payload, err)
+		}
+	}
+	for _, payload := range []string{"", "with space", "utm=vk", strings.Repeat("a", 65)} {
+		if err := ValidatePayload(payload); !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("ValidatePayload(%q) = %v, want ErrInvalidPayload", payload, err)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package script
+
+import "errors"
+
+var (
+	ErrNoSteps        = errors.New("script has no steps")
+	ErrAlreadyStarted = errors.New("script already started for user")
+	ErrInvalidScript  = errors.New("invalid script")
+	ErrInvalidPayload = errors.New("deep link payload must be 1-64 characters A-Z, a-z, 0-9, _ or -")
+	ErrNotPublished   = errors.New("script has no published version")
+	ErrNoDraft        = errors.New("script has no draft")
+	ErrDraftVersion   = errors.New("version is a draft, publish it instead")
+	ErrPublishedStep  = errors.New("step belongs to a published version, edit the draft")
+)
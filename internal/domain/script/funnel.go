@@ -0,0 +1,130 @@
+package script
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FunnelFilter selects the runs a funnel is built from by when they started,
+// From inclusive and To exclusive, and by acquisition source. Zero values do
+// not filter.
+type FunnelFilter struct {
+	From   time.Time
+	To     time.Time
+	Source string
+}
+
+// FunnelEntry is one entry of a run into a step, as recorded in its path.
+type FunnelEntry struct {
+	ProgressID uuid.UUID
+	StepID     uuid.UUID
+	EnteredAt  time.Time
+	// Finished is set when the run reached the end of the script.
+	Finished bool
+	// Sent is set when the step was delivered on the run, Clicked when the
+	// user pressed a button of the step's message after entering it.
+	Sent    bool
+	Clicked bool
+}
+
+// StepFunnel counts the runs that reached a step, were sent it, clicked one
+// of its buttons and completed it by moving on or finishing the script.
+type StepFunnel struct {
+	StepID    uuid.UUID
+	Order     int
+	Reached   int
+	Sent      int
+	Clicked   int
+	Completed int
+	// MedianTime is the median time from entering the step to entering the
+	// next one, zero when no run moved on.
+	MedianTime time.Duration
+}
+
+// DropOff is the number of runs that reached the step and went no further,
+// including those still on it.
+func (f StepFunnel) DropOff() int {
+	return f.Reached - f.Completed
+}
+
+// DropOffRate is DropOff as a share of Reached.
+func (f StepFunnel) DropOffRate() float64 {
+	if f.Reached == 0 {
+		return 0
+	}
+	return float64(f.DropOff()) / float64(f.Reached)
+}
+
+// BuildFunnel aggregates the path entries of runs through a script version
+// into a funnel over its steps, in the order of steps. Runs that visit a
+// step several times count once.
+func BuildFunnel(steps []*Step, entries []FunnelEntry) []StepFunnel {
+	sorted := make([]FunnelEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ProgressID != sorted[j].ProgressID {
+			return sorted[i].ProgressID.String() < sorted[j].ProgressID.String()
+		}
+		return sorted[i].EnteredAt.Before(sorted[j].EnteredAt)
+	})
+
+	type runSet map[uuid.UUID]bool
+	var (
+		reached   = map[uuid.UUID]runSet{}
+		sent      = map[uuid.UUID]runSet{}
+		clicked   = map[uuid.UUID]runSet{}
+		completed = map[uuid.UUID]runSet{}
+		durations = map[uuid.UUID][]time.Duration{}
+	)
+	mark := func(sets map[uuid.UUID]runSet, e FunnelEntry) {
+		if sets[e.StepID] == nil {
+			sets[e.StepID] = runSet{}
+		}
+		sets[e.StepID][e.ProgressID] = true
+	}
+	for i, e := range sorted {
+		mark(reached, e)
+		if e.Sent {
+			mark(sent, e)
+		}
+		if e.Clicked {
+			mark(clicked, e)
+		}
+		if i+1 < len(sorted) && sorted[i+1].ProgressID == e.ProgressID {
+			mark(completed, e)
+			durations[e.StepID] = append(durations[e.StepID], sorted[i+1].EnteredAt.Sub(e.EnteredAt))
+		} else if e.Finished {
+			mark(completed, e)
+		}
+	}
+
+	funnel := make([]StepFunnel, 0, len(steps))
+	for _, st := range steps {
+		funnel = append(funnel, StepFunnel{
+			StepID:     st.ID,
+			Order:      st.Order,
+			Reached:    len(reached[st.ID]),
+			Sent:       len(sent[st.ID]),
+			Clicked:    len(clicked[st.ID]),
+			Completed:  len(completed[st.ID]),
+			MedianTime: median(durations[st.ID]),
+		})
+	}
+	return funnel
+}
+
+func median(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(ds))
+	copy(sorted, ds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
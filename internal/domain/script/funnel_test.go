@@ -0,0 +1,45 @@
+package script
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildFunnel(t *testing.T) {
+	first := &Step{ID: uuid.New(), Order: 1}
+	second := &Step{ID: uuid.New(), Order: 2}
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// a finishes the script, b stops on the second step, c never gets the
+	// first step delivered and d revisits the first step
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	entries := []FunnelEntry{
+		{ProgressID: a, StepID: second.ID, EnteredAt: start.Add(time.Hour), Sent: true, Finished: true},
+		{ProgressID: a, StepID: first.ID, EnteredAt: start, Sent: true, Clicked: true, Finished: true},
+		{ProgressID: b, StepID: first.ID, EnteredAt: start, Sent: true},
+		{ProgressID: b, StepID: second.ID, EnteredAt: start.Add(3 * time.Hour)},
+		{ProgressID: c, StepID: first.ID, EnteredAt: start},
+		{ProgressID: d, StepID: first.ID, EnteredAt: start, Sent: true},
+		{ProgressID: d, StepID: second.ID, EnteredAt: start.Add(2 * time.Hour), Sent: true},
+		{ProgressID: d, StepID: first.ID, EnteredAt: start.Add(5 * time.Hour), Sent: true},
+	}
+
+	got := BuildFunnel([]*Step{first, second}, entries)
+	want := []StepFunnel{
+		{StepID: first.ID, Order: 1, Reached: 4, Sent: 3, Clicked: 1, Completed: 3, MedianTime: 2 * time.Hour},
+		{StepID: second.ID, Order: 2, Reached: 3, Sent: 2, Completed: 2, MedianTime: 3 * time.Hour},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("BuildFunnel() returned %d steps, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", want[i].Order, got[i], want[i])
+		}
+	}
+	if got[0].DropOff() != 1 || got[1].DropOff() != 1 {
+		t.Errorf("DropOff() = %d, %d, want 1, 1", got[0].DropOff(), got[1].DropOff())
+	}
+}
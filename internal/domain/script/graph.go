@@ -0,0 +1,332 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/google/uuid"
+)
+
+// Transition conditions.
+const (
+	ConditionAlways       = "always"
+	ConditionAnswer       = "answer"
+	ConditionButton       = "button"
+	ConditionDelivered    = "delivered"
+	ConditionNotDelivered = "not_delivered"
+	ConditionTag          = "tag"
+	ConditionAttribute    = "attribute"
+)
+
+// Transition is an edge of the script graph. Value holds the expected answer
+// for ConditionAnswer, the button ID for ConditionButton, the step ID for
+// ConditionDelivered and ConditionNotDelivered, the tag for ConditionTag and
+// "key=value", or just "key" to require the attribute to be set, for
+// ConditionAttribute.
+type Transition struct {
+	ID         uuid.UUID
+	ScriptID   uuid.UUID
+	VersionID  uuid.UUID
+	FromStepID uuid.UUID
+	ToStepID   uuid.UUID
+	Condition  string
+	Value      string
+	Priority   int
+}
+
+// Answer is an input the user gave on a step.
+type Answer struct {
+	StepID uuid.UUID
+	Input  Input
+}
+
+// State is what transition conditions are evaluated against.
+type State struct {
+	// Answers holds the latest input per step.
+	Answers   map[uuid.UUID]Input
+	Delivered map[uuid.UUID]bool
+	// Attributes and Tags are the user's custom attributes and tags on the
+	// bot.
+	Attributes map[string]string
+	Tags       map[string]bool
+}
+
+// NewState builds a State from the answers in the order they were given and
+// the IDs of delivered steps.
+func NewState(answers []Answer, delivered []uuid.UUID) State {
+	state := State{
+		Answers:   make(map[uuid.UUID]Input, len(answers)),
+		Delivered: make(map[uuid.UUID]bool, len(delivered)),
+	}
+	for _, a := range answers {
+		state.Answers[a.StepID] = a.Input
+	}
+	for _, id := range delivered {
+		state.Delivered[id] = true
+	}
+	return state
+}
+
+func (t *Transition) Validate() error {
+	switch t.Condition {
+	case ConditionAlways:
+	case ConditionAnswer:
+		if strings.TrimSpace(t.Value) == "" {
+			return fmt.Errorf("answer condition needs a value")
+		}
+	case ConditionButton, ConditionDelivered, ConditionNotDelivered:
+		if _, err := uuid.Parse(t.Value); err != nil {
+			return fmt.Errorf("%s condition needs an ID, got: %q", t.Condition, t.Value)
+		}
+	case ConditionTag:
+		if err := user.ValidateKey(t.Value); err != nil {
+			return fmt.Errorf("tag condition: %w", err)
+		}
+	case ConditionAttribute:
+		key, _, _ := strings.Cut(t.Value, "=")
+		if err := user.ValidateKey(key); err != nil {
+			return fmt.Errorf("attribute condition: %w", err)
+		}
+	default:
+		return fmt.Errorf("condition must be (always, answer, button, delivered, not_delivered, tag, attribute), got: %v", t.Condition)
+	}
+	return nil
+}
+
+// Matches reports whether the transition can be taken in state.
+func (t *Transition) Matches(state State) bool {
+	switch t.Condition {
+	case ConditionAlways:
+		return true
+	case ConditionAnswer:
+		in, ok := state.Answers[t.FromStepID]
+		if !ok {
+			return false
+		}
+		answer := in.Text
+		if in.Type == InputContact {
+			answer = in.Phone
+		}
+		return in.Type != InputButton && strings.EqualFold(strings.TrimSpace(answer), strings.TrimSpace(t.Value))
+	case ConditionButton:
+		in, ok := state.Answers[t.FromStepID]
+		return ok && in.Type == InputButton && in.ButtonID.String() == t.Value
+	case ConditionDelivered, ConditionNotDelivered:
+		id, err := uuid.Parse(t.Value)
+		if err != nil {
+			return false
+		}
+		return state.Delivered[id] == (t.Condition == ConditionDelivered)
+	case ConditionTag:
+		return state.Tags[t.Value]
+	case ConditionAttribute:
+		key, want, hasValue := strings.Cut(t.Value, "=")
+		got, ok := state.Attributes[key]
+		if !hasValue {
+			return ok
+		}
+		return ok && strings.EqualFold(got, strings.TrimSpace(want))
+	default:
+		return false
+	}
+}
+
+// Remap returns a copy of the transition with step and button IDs, including
+// the ones held in Value, replaced by their counterparts in steps and
+// buttons. It is used when a script version is copied.
+func (t *Transition) Remap(steps, buttons map[uuid.UUID]uuid.UUID) Transition {
+	out := *t
+	out.ID = uuid.Nil
+	out.FromStepID = remapID(steps, t.FromStepID)
+	out.ToStepID = remapID(steps, t.ToStepID)
+	ids := steps
+	if t.Condition == ConditionButton {
+		ids = buttons
+	}
+	switch t.Condition {
+	case ConditionButton, ConditionDelivered, ConditionNotDelivered:
+		if id, err := uuid.Parse(t.Value); err == nil {
+			out.Value = remapID(ids, id).String()
+		}
+	}
+	return out
+}
+
+func remapID(ids map[uuid.UUID]uuid.UUID, id uuid.UUID) uuid.UUID {
+	if mapped, ok := ids[id]; ok {
+		return mapped
+	}
+	return id
+}
+
+// Graph is a script's steps and the transitions between them. A step without
+// outgoing transitions continues with the next step by order.
+type Graph struct {
+	steps []*Step
+	byID  map[uuid.UUID]*Step
+	out   map[uuid.UUID][]*Transition
+	all   []*Transition
+}
+
+func NewGraph(steps []*Step, transitions []*Transition) *Graph {
+	g := &Graph{
+		steps: append([]*Step(nil), steps...),
+		byID:  make(map[uuid.UUID]*Step, len(steps)),
+		out:   make(map[uuid.UUID][]*Transition),
+		all:   transitions,
+	}
+	sort.SliceStable(g.steps, func(i, j int) bool { return g.steps[i].Order < g.steps[j].Order })
+	for _, st := range g.steps {
+		g.byID[st.ID] = st
+	}
+	for _, t := range transitions {
+		g.out[t.FromStepID] = append(g.out[t.FromStepID], t)
+	}
+	for _, ts := range g.out {
+		sort.SliceStable(ts, func(i, j int) bool { return ts[i].Priority < ts[j].Priority })
+	}
+	return g
+}
+
+// Entry returns the step the script starts with.
+func (g *Graph) Entry() *Step {
+	if len(g.steps) == 0 {
+		return nil
+	}
+	return g.steps[0]
+}
+
+func (g *Graph) Step(id uuid.UUID) *Step {
+	return g.byID[id]
+}
+
+// HasTransitions reports whether the step continues by explicit transitions.
+func (g *Graph) HasTransitions(stepID uuid.UUID) bool {
+	return len(g.out[stepID]) > 0
+}
+
+// Next returns the step to continue with after from and the transition taken,
+// which is nil when the step continues by order. A nil step ends the script.
+func (g *Graph) Next(from *Step, state State) (*Step, *Transition) {
+	if ts := g.out[from.ID]; len(ts) > 0 {
+		for _, t := range ts {
+			if t.Matches(state) {
+				return g.byID[t.ToStepID], t
+			}
+		}
+		return nil, nil
+	}
+	return g.nextByOrder(from), nil
+}
+
+func (g *Graph) nextByOrder(from *Step) *Step {
+	for _, st := range g.steps {
+		if st.Order > from.Order {
+			return st
+		}
+	}
+	return nil
+}
+
+// successors lists every step the script can move to from step.
+func (g *Graph) successors(step *Step) []*Step {
+	var next []*Step
+	if ts := g.out[step.ID]; len(ts) > 0 {
+		for _, t := range ts {
+			next = append(next, g.byID[t.ToStepID])
+		}
+	} else if st := g.nextByOrder(step); st != nil {
+		next = append(next, st)
+	}
+	if step.Wait != nil && step.Wait.FallbackStepID != nil {
+		if st := g.byID[*step.Wait.FallbackStepID]; st != nil {
+			next = append(next, st)
+		}
+	}
+	return next
+}
+
+// Validate rejects transitions between unknown steps, steps that cannot be
+// reached from the entry step and cycles in which no step waits for the user,
+// since those would loop forever on timers.
+func (g *Graph) Validate() error {
+	if len(g.steps) == 0 {
+		return nil
+	}
+
+	for _, t := range g.all {
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidScript, err)
+		}
+		if g.byID[t.FromStepID] == nil || g.byID[t.ToStepID] == nil {
+			return fmt.Errorf("%w: transition %s refers to a step outside the script", ErrInvalidScript, t.ID)
+		}
+	}
+	for _, st := range g.steps {
+		if err := st.ValidateChannel(); err != nil {
+			return fmt.Errorf("%w: step %d: %v", ErrInvalidScript, st.Order, err)
+		}
+		if err := st.ValidateVariants(); err != nil {
+			return fmt.Errorf("%w: step %d: %v", ErrInvalidScript, st.Order, err)
+		}
+		if st.Wait != nil && st.Wait.FallbackStepID != nil && g.byID[*st.Wait.FallbackStepID] == nil {
+			return fmt.Errorf("%w: fallback of step %d is outside the script", ErrInvalidScript, st.Order)
+		}
+	}
+
+	reached := map[uuid.UUID]bool{g.steps[0].ID: true}
+	queue := []*Step{g.steps[0]}
+	for len(queue) > 0 {
+		st := queue[0]
+		queue = queue[1:]
+		for _, next := range g.successors(st) {
+			if !reached[next.ID] {
+				reached[next.ID] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	for _, st := range g.steps {
+		if !reached[st.ID] {
+			return fmt.Errorf("%w: step %d is unreachable", ErrInvalidScript, st.Order)
+		}
+	}
+
+	// look for cycles among steps that do not wait for the user
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	color := make(map[uuid.UUID]int, len(g.steps))
+	var visit func(st *Step) *Step
+	visit = func(st *Step) *Step {
+		color[st.ID] = visiting
+		for _, next := range g.successors(st) {
+			if next.Wait != nil {
+				continue
+			}
+			switch color[next.ID] {
+			case visiting:
+				return next
+			case unvisited:
+				if found := visit(next); found != nil {
+					return found
+				}
+			}
+		}
+		color[st.ID] = done
+		return nil
+	}
+	for _, st := range g.steps {
+		if st.Wait != nil || color[st.ID] != unvisited {
+			continue
+		}
+		if found := visit(st); found != nil {
+			return fmt.Errorf("%w: step %d is in a cycle without a waiting step", ErrInvalidScript, found.Order)
+		}
+	}
+	return nil
+}
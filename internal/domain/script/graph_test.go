@@ -0,0 +1,157 @@
+package script
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newStep(order int, wait *Wait) *Step {
+	return &Step{ID: uuid.New(), Order: order, Wait: wait}
+}
+
+func TestGraphNext(t *testing.T) {
+	question := newStep(1, &Wait{For: WaitButton})
+	yes := newStep(2, nil)
+	no := newStep(3, nil)
+	tail := newStep(4, nil)
+	yesButton := uuid.New()
+
+	g := NewGraph([]*Step{tail, no, yes, question}, []*Transition{
+		{ID: uuid.New(), FromStepID: question.ID, ToStepID: no.ID, Condition: ConditionAlways, Priority: 10},
+		{ID: uuid.New(), FromStepID: question.ID, ToStepID: yes.ID, Condition: ConditionButton, Value: yesButton.String(), Priority: 1},
+		{ID: uuid.New(), FromStepID: yes.ID, ToStepID: tail.ID, Condition: ConditionAlways},
+	})
+
+	if g.Entry() != question {
+		t.Fatalf("entry must be the first step by order")
+	}
+
+	state := NewState([]Answer{{StepID: question.ID, Input: Input{Type: InputButton, ButtonID: yesButton}}}, nil)
+	if next, tr := g.Next(question, state); next != yes || tr == nil {
+		t.Fatalf("expected button branch, got step %v", next)
+	}
+	if next, _ := g.Next(question, NewState(nil, nil)); next != no {
+		t.Fatalf("expected fallback branch, got step %v", next)
+	}
+	// no has no transitions and continues by order
+	if next, tr := g.Next(no, State{}); next != tail || tr != nil {
+		t.Fatalf("expected next by order, got step %v", next)
+	}
+	if next, _ := g.Next(tail, State{}); next != nil {
+		t.Fatalf("expected end of script, got step %v", next)
+	}
+}
+
+func TestTransitionMatches(t *testing.T) {
+	from := uuid.New()
+	sent := uuid.New()
+	state := NewState([]Answer{
+		{StepID: from, Input: Input{Type: InputText, Text: "course A"}},
+		{StepID: from, Input: Input{Type: InputText, Text: "Course B "}},
+	}, []uuid.UUID{sent})
+	state.Attributes = map[string]string{"interested_in": "course_b"}
+	state.Tags = map[string]bool{"vip": true}
+
+	tests := []struct {
+		tr   Transition
+		want bool
+	}{
+		{Transition{FromStepID: from, Condition: ConditionAnswer, Value: "course b"}, true},
+		{Transition{FromStepID: from, Condition: ConditionAnswer, Value: "course a"}, false},
+		{Transition{FromStepID: from, Condition: ConditionDelivered, Value: sent.String()}, true},
+		{Transition{FromStepID: from, Condition: ConditionNotDelivered, Value: sent.String()}, false},
+		{Transition{FromStepID: from, Condition: ConditionButton, Value: uuid.NewString()}, false},
+		{Transition{FromStepID: from, Condition: ConditionTag, Value: "vip"}, true},
+		{Transition{FromStepID: from, Condition: ConditionTag, Value: "churned"}, false},
+		{Transition{FromStepID: from, Condition: ConditionAttribute, Value: "interested_in=Course_B"}, true},
+		{Transition{FromStepID: from, Condition: ConditionAttribute, Value: "interested_in=course_a"}, false},
+		{Transition{FromStepID: from, Condition: ConditionAttribute, Value: "interested_in"}, true},
+		{Transition{FromStepID: from, Condition: ConditionAttribute, Value: "city"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.tr.Matches(state); got != tt.want {
+			t.Errorf("%s %q: got %v, want %v", tt.tr.Condition, tt.tr.Value, got, tt.want)
+		}
+	}
+}
+
+func TestGraphValidate(t *testing.T) {
+	t.Run("linear script", func(t *testing.T) {
+		if err := NewGraph([]*Step{newStep(1, nil), newStep(2, nil)}, nil).Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("unreachable step", func(t *testing.T) {
+		a, b, c := newStep(1, nil), newStep(2, nil), newStep(3, nil)
+		g := NewGraph([]*Step{a, b, c}, []*Transition{
+			{FromStepID: a.ID, ToStepID: c.ID, Condition: ConditionAlways},
+		})
+		if err := g.Validate(); !errors.Is(err, ErrInvalidScript) {
+			t.Fatalf("expected unreachable step error, got %v", err)
+		}
+	})
+
+	t.Run("fallback makes step reachable", func(t *testing.T) {
+		fallback := newStep(3, nil)
+		a := newStep(1, &Wait{For: WaitText, FallbackStepID: &fallback.ID})
+		b := newStep(2, nil)
+		g := NewGraph([]*Step{a, b, fallback}, []*Transition{
+			{FromStepID: a.ID, ToStepID: b.ID, Condition: ConditionAlways},
+			{FromStepID: b.ID, ToStepID: a.ID, Condition: ConditionAlways},
+		})
+		if err := g.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("cycle without wait", func(t *testing.T) {
+		a, b := newStep(1, nil), newStep(2, nil)
+		g := NewGraph([]*Step{a, b}, []*Transition{
+			{FromStepID: a.ID, ToStepID: b.ID, Condition: ConditionAlways},
+			{FromStepID: b.ID, ToStepID: a.ID, Condition: ConditionAlways},
+		})
+		if err := g.Validate(); !errors.Is(err, ErrInvalidScript) {
+			t.Fatalf("expected cycle error, got %v", err)
+		}
+	})
+
+	t.Run("unknown step", func(t *testing.T) {
+		a := newStep(1, nil)
+		g := NewGraph([]*Step{a}, []*Transition{
+			{FromStepID: a.ID, ToStepID: uuid.New(), Condition: ConditionAlways},
+		})
+		if err := g.Validate(); !errors.Is(err, ErrInvalidScript) {
+			t.Fatalf("expected unknown step error, got %v", err)
+		}
+	})
+}
+
+func TestTransitionRemap(t *testing.T) {
+	from, to, sent, button := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	steps := map[uuid.UUID]uuid.UUID{from: uuid.New(), to: uuid.New(), sent: uuid.New()}
+	buttons := map[uuid.UUID]uuid.UUID{button: uuid.New()}
+
+	tests := []struct {
+		tr        Transition
+		wantValue string
+	}{
+		{Transition{Condition: ConditionButton, Value: button.String()}, buttons[button].String()},
+		{Transition{Condition: ConditionDelivered, Value: sent.String()}, steps[sent].String()},
+		{Transition{Condition: ConditionNotDelivered, Value: sent.String()}, steps[sent].String()},
+		{Transition{Condition: ConditionAnswer, Value: sent.String()}, sent.String()},
+		{Transition{Condition: ConditionTag, Value: "vip"}, "vip"},
+	}
+	for _, tt := range tests {
+		tt.tr.ID, tt.tr.FromStepID, tt.tr.ToStepID = uuid.New(), from, to
+		got := tt.tr.Remap(steps, buttons)
+		if got.ID != uuid.Nil || got.FromStepID != steps[from] || got.ToStepID != steps[to] {
+			t.Errorf("%s: steps not remapped: %+v", tt.tr.Condition, got)
+		}
+		if got.Value != tt.wantValue {
+			t.Errorf("%s: value = %q, want %q", tt.tr.Condition, got.Value, tt.wantValue)
+		}
+	}
+}
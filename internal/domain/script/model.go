@@ -0,0 +1,301 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/google/uuid"
+)
+
+// Progress statuses.
+const (
+	ProgressActive   = "active"
+	ProgressWaiting  = "waiting"
+	ProgressFinished = "finished"
+	ProgressFailed   = "failed"
+)
+
+// Conditions a step can wait for before the script moves on.
+const (
+	WaitButton  = "button"
+	WaitText    = "text"
+	WaitKeyword = "keyword"
+	WaitContact = "contact"
+	// WaitSubscription holds the script until the user subscribes to the
+	// channel of the wait.
+	WaitSubscription = "subscription"
+)
+
+// Kinds of scheduled steps.
+const (
+	KindDeliver = "deliver"
+	KindTimeout = "timeout"
+)
+
+// Scheduled step statuses.
+const (
+	ScheduledPending    = "pending"
+	ScheduledProcessing = "processing"
+	ScheduledSent       = "sent"
+	ScheduledFailed     = "failed"
+	ScheduledCancelled  = "cancelled"
+)
+
+// Channels a step is delivered to.
+const (
+	// ChannelPrivate delivers a step to the user's private chat with the bot.
+	ChannelPrivate = "private"
+	// ChannelGroup sends a step to the group TargetChatID, into the forum
+	// topic TargetThreadID when it is set.
+	ChannelGroup = "group"
+	// ChannelPost publishes a step as a post in the channel TargetChatID.
+	ChannelPost = "channel"
+)
+
+// ContactButtonText labels the keyboard button that shares the contact on
+// steps waiting for it.
+const ContactButtonText = "Share contact"
+
+// ButtonCallbackPrefix prefixes callback data of message buttons without URL.
+const ButtonCallbackPrefix = "button:"
+
+// CheckSubscriptionText labels the button of subscription gates that checks
+// the subscription again. Its callback data is CheckSubscriptionCallback.
+const (
+	CheckSubscriptionText     = "Check again"
+	CheckSubscriptionCallback = "check_subscription"
+)
+
+// GroupButtonText labels the invite link button of steps granting access to
+// the private group.
+const GroupButtonText = "Join the group"
+
+type Script struct {
+	ID             uuid.UUID
+	TelegramBotID  uuid.UUID
+	Name           string
+	IsActive       bool
+	PrivateGroupID *uuid.UUID
+	// PublishedVersionID is the version new users go through.
+	PublishedVersionID *uuid.UUID
+}
+
+// Version is a snapshot of the steps and transitions of a script. Only the
+// draft, which is not published yet, can be changed; users stay on the
+// version they started.
+type Version struct {
+	ID          uuid.UUID
+	ScriptID    uuid.UUID
+	Number      int
+	CreatedAt   time.Time
+	PublishedAt *time.Time
+}
+
+func (v *Version) IsDraft() bool {
+	return v.PublishedAt == nil
+}
+
+type Step struct {
+	ID        uuid.UUID
+	ScriptID  uuid.UUID
+	VersionID uuid.UUID
+	MessageID uuid.UUID
+	Order     int
+	Channel   string
+	// TargetChatID and TargetThreadID address group and channel steps.
+	TargetChatID   int64
+	TargetThreadID int
+	// Timing is the delay after the previous step was delivered, or after the
+	// script was started for the first step.
+	Timing      time.Duration
+	SkipOnError bool
+	// Wait is set for steps that hold the script until the user acts.
+	Wait *Wait
+	// SaveAs is the user attribute the answer to the step is saved to.
+	SaveAs string
+	// GrantsGroupAccess adds a personal invite link to the private group of
+	// the script to the step's message.
+	GrantsGroupAccess bool
+	// Variants are the A/B test variants of the step's message, see
+	// PickVariant.
+	Variants []Variant
+}
+
+// Shared reports whether the step goes to a group or channel, where one post
+// is seen by everybody, instead of the user's private chat.
+func (s *Step) Shared() bool {
+	return s.Channel == ChannelGroup || s.Channel == ChannelPost
+}
+
+// ValidateChannel checks that the step has a target for its channel. Steps
+// waiting for the user or handing out invite links go to the private chat.
+func (s *Step) ValidateChannel() error {
+	switch s.Channel {
+	case "", ChannelPrivate:
+		if s.TargetChatID != 0 || s.TargetThreadID != 0 {
+			return fmt.Errorf("private steps have no target chat")
+		}
+		return nil
+	case ChannelGroup, ChannelPost:
+		if s.TargetChatID == 0 {
+			return fmt.Errorf("%s steps need a target chat", s.Channel)
+		}
+		if s.Channel == ChannelPost && s.TargetThreadID != 0 {
+			return fmt.Errorf("channel posts have no topics")
+		}
+		if s.Wait != nil {
+			return fmt.Errorf("only private steps can wait for the user")
+		}
+		if s.GrantsGroupAccess {
+			return fmt.Errorf("invite links are personal and only sent in private")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown channel %q", s.Channel)
+	}
+}
+
+// Wait is a condition a step waits on. When Timeout passes without the
+// condition being met the script continues with FallbackStepID, or finishes
+// when there is none.
+type Wait struct {
+	For            string
+	Keywords       []string
+	Timeout        time.Duration
+	FallbackStepID *uuid.UUID
+	// ChannelID is the channel a subscription wait checks.
+	ChannelID int64
+}
+
+type Progress struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	ScriptID      uuid.UUID
+	VersionID     uuid.UUID
+	CurrentStepID *uuid.UUID
+	Status        string
+	StepStartedAt *time.Time
+	StartedAt     time.Time
+	FinishedAt    *time.Time
+	// WaitingFor is the condition of the current step the user is expected
+	// to meet while Status is ProgressWaiting.
+	WaitingFor string
+	// Source is the acquisition source of the deep link the user came by.
+	Source string
+	// Preview marks a test run of a script version by one of the bot's
+	// managers, see Service.StartPreview.
+	Preview bool
+}
+
+type ScheduledStep struct {
+	ID         uuid.UUID
+	ProgressID uuid.UUID
+	StepID     uuid.UUID
+	ExecuteAt  time.Time
+	Kind       string
+	Status     string
+	Attempts   int
+	LastError  string
+}
+
+// Overdue are the pending scheduled steps of a bot that are late.
+type Overdue struct {
+	TelegramBotID uuid.UUID
+	Steps         int
+	OldestDueAt   time.Time
+}
+
+// StepPost is the single post of a group or channel step, shared by all
+// users who reach the step.
+type StepPost struct {
+	StepID            uuid.UUID
+	TelegramMessageID string
+	PostedAt          time.Time
+}
+
+type Delivery struct {
+	ID         uuid.UUID
+	ProgressID uuid.UUID
+	StepID     uuid.UUID
+	MessageID  uuid.UUID
+	// VariantID is the variant sent when the step has variants.
+	VariantID         *uuid.UUID
+	Channel           string
+	Snapshot          []byte
+	TelegramMessageID string
+	SentAt            time.Time
+}
+
+// Input types.
+const (
+	InputText    = "text"
+	InputButton  = "button"
+	InputContact = "contact"
+	// InputSubscription is a confirmed subscription to ChannelID.
+	InputSubscription = "subscription"
+)
+
+// Input is something the user sent to the bot: a text message, a press of a
+// message button or their shared contact.
+type Input struct {
+	Type      string
+	Text      string
+	ButtonID  uuid.UUID
+	Phone     string
+	ChannelID int64
+}
+
+// Value returns what the user answered: the text, the phone number or, for
+// button presses, the text of the pressed button among buttons.
+func (in Input) Value(buttons []message.Button) string {
+	switch in.Type {
+	case InputText:
+		return strings.TrimSpace(in.Text)
+	case InputContact:
+		return in.Phone
+	case InputButton:
+		for _, b := range buttons {
+			if b.ID == in.ButtonID {
+				return b.Text
+			}
+		}
+	}
+	return ""
+}
+
+// Matches reports whether input satisfies the wait condition.
+func (w *Wait) Matches(input Input, stepButtons []uuid.UUID) bool {
+	switch w.For {
+	case WaitButton:
+		if input.Type != InputButton {
+			return false
+		}
+		for _, id := range stepButtons {
+			if id == input.ButtonID {
+				return true
+			}
+		}
+		return false
+	case WaitText:
+		return input.Type == InputText && strings.TrimSpace(input.Text) != ""
+	case WaitKeyword:
+		if input.Type != InputText {
+			return false
+		}
+		text := strings.TrimSpace(input.Text)
+		for _, keyword := range w.Keywords {
+			if strings.EqualFold(text, strings.TrimSpace(keyword)) {
+				return true
+			}
+		}
+		return false
+	case WaitContact:
+		return input.Type == InputContact && input.Phone != ""
+	case WaitSubscription:
+		return input.Type == InputSubscription && input.ChannelID == w.ChannelID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,64 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestWaitMatches(t *testing.T) {
+	button := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name  string
+		wait  Wait
+		input Input
+		want  bool
+	}{
+		{"button of the step", Wait{For: WaitButton}, Input{Type: InputButton, ButtonID: button}, true},
+		{"button of another message", Wait{For: WaitButton}, Input{Type: InputButton, ButtonID: other}, false},
+		{"text instead of button", Wait{For: WaitButton}, Input{Type: InputText, Text: "hi"}, false},
+		{"any text", Wait{For: WaitText}, Input{Type: InputText, Text: "hello"}, true},
+		{"blank text", Wait{For: WaitText}, Input{Type: InputText, Text: "  "}, false},
+		{"keyword", Wait{For: WaitKeyword, Keywords: []string{"bonus", "gift"}}, Input{Type: InputText, Text: " Gift "}, true},
+		{"not a keyword", Wait{For: WaitKeyword, Keywords: []string{"bonus"}}, Input{Type: InputText, Text: "bonuses"}, false},
+		{"contact", Wait{For: WaitContact}, Input{Type: InputContact, Phone: "+79990000000"}, true},
+		{"text instead of contact", Wait{For: WaitContact}, Input{Type: InputText, Text: "+79990000000"}, false},
+		{"subscription", Wait{For: WaitSubscription, ChannelID: -100}, Input{Type: InputSubscription, ChannelID: -100}, true},
+		{"subscription to another channel", Wait{For: WaitSubscription, ChannelID: -100}, Input{Type: InputSubscription, ChannelID: -200}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.wait.Matches(tt.input, []uuid.UUID{button}); got != tt.want {
+				t.Fatalf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepValidateChannel(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    Step
+		wantErr bool
+	}{
+		{"private", Step{Channel: ChannelPrivate}, false},
+		{"private with target", Step{Channel: ChannelPrivate, TargetChatID: -100}, true},
+		{"group", Step{Channel: ChannelGroup, TargetChatID: -100}, false},
+		{"forum topic", Step{Channel: ChannelGroup, TargetChatID: -100, TargetThreadID: 7}, false},
+		{"group without target", Step{Channel: ChannelGroup}, true},
+		{"channel post", Step{Channel: ChannelPost, TargetChatID: -100}, false},
+		{"channel post in topic", Step{Channel: ChannelPost, TargetChatID: -100, TargetThreadID: 7}, true},
+		{"group step waiting", Step{Channel: ChannelGroup, TargetChatID: -100, Wait: &Wait{For: WaitText}}, true},
+		{"group step with invite link", Step{Channel: ChannelGroup, TargetChatID: -100, GrantsGroupAccess: true}, true},
+		{"unknown", Step{Channel: "email"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.step.ValidateChannel(); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateChannel() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
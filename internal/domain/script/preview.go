@@ -0,0 +1,71 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Preview runs replace each hour of delays and wait timeouts with a second,
+// within previewMinDelay and previewMaxDelay, so a manager can walk through a
+// script in minutes.
+const (
+	previewSpeedup  = 3600
+	previewMinDelay = time.Second
+	previewMaxDelay = 30 * time.Second
+)
+
+// PreviewDelay compresses a delay of a script for a preview run. Zero stays
+// zero so steps sent right away keep their order.
+func PreviewDelay(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	d /= previewSpeedup
+	if d < previewMinDelay {
+		return previewMinDelay
+	}
+	if d > previewMaxDelay {
+		return previewMaxDelay
+	}
+	return d
+}
+
+// PreviewLabel describes the step to the manager previewing the script. It
+// is sent after the step's message with the settings that are not visible
+// in the message itself.
+func (s *Step) PreviewLabel() string {
+	parts := []string{fmt.Sprintf("Preview: step %d", s.Order)}
+	if s.Timing > 0 {
+		parts = append(parts, "after "+s.Timing.String())
+	} else {
+		parts = append(parts, "right away")
+	}
+	if s.Wait != nil {
+		wait := "waits for " + s.Wait.For
+		if s.Wait.For == WaitKeyword && len(s.Wait.Keywords) > 0 {
+			wait += " " + strings.Join(s.Wait.Keywords, "/")
+		}
+		if s.Wait.For == WaitSubscription {
+			wait += fmt.Sprintf(" to %d", s.Wait.ChannelID)
+		}
+		if s.Wait.Timeout > 0 {
+			wait += fmt.Sprintf(" (timeout %s)", s.Wait.Timeout)
+		}
+		parts = append(parts, wait)
+	}
+	switch s.Channel {
+	case ChannelGroup:
+		target := fmt.Sprintf("sent to group %d", s.TargetChatID)
+		if s.TargetThreadID != 0 {
+			target += fmt.Sprintf(" topic %d", s.TargetThreadID)
+		}
+		parts = append(parts, target)
+	case ChannelPost:
+		parts = append(parts, fmt.Sprintf("posted to channel %d", s.TargetChatID))
+	}
+	if s.GrantsGroupAccess {
+		parts = append(parts, "grants group access")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,60 @@
+package script
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreviewDelay(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want time.Duration
+	}{
+		{0, 0},
+		{5 * time.Second, time.Second},
+		{time.Hour, time.Second},
+		{10 * time.Hour, 10 * time.Second},
+		{7 * 24 * time.Hour, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := PreviewDelay(tt.in); got != tt.want {
+			t.Errorf("PreviewDelay(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStepPreviewLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		step Step
+		want string
+	}{
+		{
+			"first step",
+			Step{Order: 1},
+			"Preview: step 1, right away",
+		},
+		{
+			"keyword wait",
+			Step{Order: 2, Timing: 2 * time.Hour, Wait: &Wait{For: WaitKeyword, Keywords: []string{"bonus", "gift"}, Timeout: time.Hour}},
+			"Preview: step 2, after 2h0m0s, waits for keyword bonus/gift (timeout 1h0m0s)",
+		},
+		{
+			"forum topic",
+			Step{Order: 3, Timing: time.Minute, Channel: ChannelGroup, TargetChatID: -100, TargetThreadID: 7},
+			"Preview: step 3, after 1m0s, sent to group -100 topic 7",
+		},
+		{
+			"group access",
+			Step{Order: 4, Channel: ChannelPrivate, GrantsGroupAccess: true},
+			"Preview: step 4, right away, grants group access",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.step.PreviewLabel(); got != tt.want {
+				t.Errorf("PreviewLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
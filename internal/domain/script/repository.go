@@ -0,0 +1,111 @@
+package script
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*Script, error)
+	// GetDefaultForBot returns the oldest active script of the bot.
+	GetDefaultForBot(ctx context.Context, telegramBotID uuid.UUID) (*Script, error)
+	GetByName(ctx context.Context, telegramBotID uuid.UUID, name string) (*Script, error)
+	// SetPrivateGroup links the script to a private group, or unlinks it when
+	// groupID is nil.
+	SetPrivateGroup(ctx context.Context, id uuid.UUID, groupID *uuid.UUID) error
+	ListSteps(ctx context.Context, versionID uuid.UUID) ([]*Step, error)
+	GetStep(ctx context.Context, id uuid.UUID) (*Step, error)
+	// CreateStep adds the step to its version.
+	CreateStep(ctx context.Context, step *Step) error
+	// ReorderSteps numbers the steps from 1 in the order of ids.
+	ReorderSteps(ctx context.Context, ids []uuid.UUID) error
+	// DeleteStep removes the step along with the transitions to and from it
+	// and the timeout fallbacks leading to it.
+	DeleteStep(ctx context.Context, id uuid.UUID) error
+	ListTransitions(ctx context.Context, versionID uuid.UUID) ([]*Transition, error)
+	ReplaceTransitions(ctx context.Context, versionID uuid.UUID, transitions []*Transition) error
+	// ListVariantStats returns the conversions of the variants of the
+	// version's steps, ordered by step.
+	ListVariantStats(ctx context.Context, versionID uuid.UUID) ([]VariantStats, error)
+}
+
+type VersionRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*Version, error)
+	GetDraft(ctx context.Context, scriptID uuid.UUID) (*Version, error)
+	GetByNumber(ctx context.Context, scriptID uuid.UUID, number int) (*Version, error)
+	// List returns the versions of the script, newest first.
+	List(ctx context.Context, scriptID uuid.UUID) ([]*Version, error)
+	// CreateDraft creates a draft with copies of the steps, transitions and
+	// messages of the version from, or an empty draft when from is nil.
+	CreateDraft(ctx context.Context, scriptID uuid.UUID, from *uuid.UUID) (*Version, error)
+	// Publish freezes the draft and makes it the version new users get.
+	Publish(ctx context.Context, version *Version, publishedAt time.Time) error
+	// SetPublished makes an already published version the one new users get.
+	SetPublished(ctx context.Context, scriptID, versionID uuid.UUID) error
+}
+
+type DeepLinkRepository interface {
+	Get(ctx context.Context, telegramBotID uuid.UUID, payload string) (*DeepLink, error)
+	List(ctx context.Context, telegramBotID uuid.UUID) ([]*DeepLink, error)
+	// Save creates the link or points an existing payload at another script
+	// and source.
+	Save(ctx context.Context, link *DeepLink) error
+	Delete(ctx context.Context, telegramBotID uuid.UUID, payload string) error
+}
+
+type ProgressRepository interface {
+	Create(ctx context.Context, progress *Progress) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Progress, error)
+	GetActive(ctx context.Context, userID, scriptID uuid.UUID) (*Progress, error)
+	// GetWaiting returns the user's progress on the bot that waits for input.
+	GetWaiting(ctx context.Context, userID, telegramBotID uuid.UUID) (*Progress, error)
+	Update(ctx context.Context, progress *Progress) error
+	// Resume switches a waiting progress back to active. It reports false when
+	// the progress was not waiting, e.g. another input or the timeout won.
+	Resume(ctx context.Context, id uuid.UUID) (bool, error)
+	CreateDelivery(ctx context.Context, delivery *Delivery) error
+	CreateInput(ctx context.Context, progressID, stepID uuid.UUID, input Input) error
+	ListAnswers(ctx context.Context, progressID uuid.UUID) ([]Answer, error)
+	ListDeliveredSteps(ctx context.Context, progressID uuid.UUID) ([]uuid.UUID, error)
+	// ClaimStepPost reserves the post of a shared step. It reports false when
+	// the step was already posted or is being posted.
+	ClaimStepPost(ctx context.Context, stepID uuid.UUID, postedAt time.Time) (bool, error)
+	GetStepPost(ctx context.Context, stepID uuid.UUID) (*StepPost, error)
+	SetStepPostMessage(ctx context.Context, stepID uuid.UUID, telegramMessageID string) error
+	// ReleaseStepPost drops a claim whose post failed so it can be retried.
+	ReleaseStepPost(ctx context.Context, stepID uuid.UUID) error
+	// IsButtonDelivered reports whether a message with the button was
+	// delivered to the user by one of the bot's scripts.
+	IsButtonDelivered(ctx context.Context, buttonID, userID, telegramBotID uuid.UUID) (bool, error)
+	// AddPathStep records that the progress entered step, through transitionID
+	// when it was an explicit transition.
+	AddPathStep(ctx context.Context, progressID, stepID uuid.UUID, transitionID *uuid.UUID, enteredAt time.Time) error
+	// FinishPreviews finishes the user's unfinished preview runs of the
+	// script.
+	FinishPreviews(ctx context.Context, userID, scriptID uuid.UUID, finishedAt time.Time) error
+	// ListFunnelEntries returns the path entries of the runs through the
+	// version that match the filter, leaving out previews.
+	ListFunnelEntries(ctx context.Context, versionID uuid.UUID, filter FunnelFilter) ([]FunnelEntry, error)
+}
+
+type ScheduleRepository interface {
+	Create(ctx context.Context, step *ScheduledStep) error
+	// ClaimDue marks up to limit due steps as processing and returns them.
+	// Steps stuck in processing since before staleBefore are claimed again.
+	ClaimDue(ctx context.Context, now, staleBefore time.Time, limit int) ([]*ScheduledStep, error)
+	MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error
+	Cancel(ctx context.Context, id uuid.UUID) error
+	// Reschedule puts a failed step back to pending for a retry; the failed
+	// attempt keeps counting.
+	Reschedule(ctx context.Context, id uuid.UUID, executeAt time.Time, lastError string) error
+	// Defer puts a claimed step back to pending without running it, so the
+	// claim does not count as an attempt.
+	Defer(ctx context.Context, id uuid.UUID, executeAt time.Time, reason string) error
+	CancelForProgress(ctx context.Context, progressID uuid.UUID) error
+	// ListOverdue counts per bot the pending steps that were due before
+	// dueBefore, leaving out preview runs.
+	ListOverdue(ctx context.Context, dueBefore time.Time) ([]*Overdue, error)
+}
@@ -0,0 +1,1342 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/telegram"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// inactiveBotDelay is how long steps of a disabled or revoked bot wait before
+// they are checked again.
+const inactiveBotDelay = time.Hour
+
+type Sender interface {
+	Send(ctx context.Context, botID int64, msg telegram.OutgoingMessage) (int, error)
+	SendToGroup(ctx context.Context, botID int64, msg telegram.OutgoingMessage, threadID int) (int, error)
+	Post(ctx context.Context, botID int64, msg telegram.OutgoingMessage) (int, error)
+}
+
+// GroupAccess issues personal invite links to private groups.
+type GroupAccess interface {
+	Invite(ctx context.Context, bot *telegram_bot.TelegramBot, groupID uuid.UUID, u *user.User, progressID uuid.UUID) (string, error)
+}
+
+// MediaStorage reads the media files of step messages.
+type MediaStorage interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Membership checks whether users are subscribed to channels.
+type Membership interface {
+	IsMember(ctx context.Context, botID, chatID, userID int64) (bool, error)
+}
+
+type Service struct {
+	scripts    Repository
+	links      DeepLinkRepository
+	versions   VersionRepository
+	progress   ProgressRepository
+	schedule   ScheduleRepository
+	messages   message.Repository
+	media      MediaStorage
+	redirects  *message.Redirects
+	bots       telegram_bot.Repository
+	users      user.Repository
+	attributes user.AttributeRepository
+	groups     GroupAccess
+	members    Membership
+	sender     Sender
+	logger     logger.Logger
+}
+
+func NewService(
+	scripts Repository,
+	links DeepLinkRepository,
+	versions VersionRepository,
+	progress ProgressRepository,
+	schedule ScheduleRepository,
+	messages message.Repository,
+	media MediaStorage,
+	redirects *message.Redirects,
+	bots telegram_bot.Repository,
+	users user.Repository,
+	attributes user.AttributeRepository,
+	groups GroupAccess,
+	members Membership,
+	sender Sender,
+	logger logger.Logger,
+) *Service {
+	return &Service{
+		scripts:    scripts,
+		links:      links,
+		versions:   versions,
+		progress:   progress,
+		schedule:   schedule,
+		messages:   messages,
+		media:      media,
+		redirects:  redirects,
+		bots:       bots,
+		users:      users,
+		attributes: attributes,
+		groups:     groups,
+		members:    members,
+		sender:     sender,
+		logger:     logger,
+	}
+}
+
+// run is a user's pass through a script on a bot.
+type run struct {
+	script *Script
+	bot    *telegram_bot.TelegramBot
+	user   *user.User
+}
+
+// StartDefault enrolls u into the bot's default script.
+func (s *Service) StartDefault(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User) (*Progress, error) {
+	sc, err := s.scripts.GetDefaultForBot(ctx, bot.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Start(ctx, bot, u, sc, "")
+}
+
+// StartFromLink enrolls u into the script the deep link payload of /start
+// points to and records the link's source. An empty or unknown payload
+// starts the bot's default script.
+func (s *Service) StartFromLink(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User, payload string) (*Progress, error) {
+	if payload == "" {
+		return s.StartDefault(ctx, bot, u)
+	}
+	link, err := s.links.Get(ctx, bot.ID, payload)
+	if errors.Is(err, app_errors.ErrNotFound) {
+		return s.StartDefault(ctx, bot, u)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sc, err := s.scripts.GetByID(ctx, link.ScriptID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Start(ctx, bot, u, sc, link.Source)
+}
+
+// Start enrolls u into the published version of the script and schedules
+// its first step. The user stays on that version until the end of the
+// script. If the user is already going through the script, the active
+// progress is returned along with ErrAlreadyStarted.
+func (s *Service) Start(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User, sc *Script, source string) (*Progress, error) {
+	existing, err := s.progress.GetActive(ctx, u.ID, sc.ID)
+	if err == nil {
+		return existing, ErrAlreadyStarted
+	}
+	if !errors.Is(err, app_errors.ErrNotFound) {
+		return nil, err
+	}
+	if sc.PublishedVersionID == nil {
+		return nil, ErrNotPublished
+	}
+
+	g, err := s.graph(ctx, *sc.PublishedVersionID)
+	if err != nil {
+		return nil, err
+	}
+	first := g.Entry()
+	if first == nil {
+		return nil, ErrNoSteps
+	}
+
+	now := time.Now().UTC()
+	p := &Progress{
+		UserID:        u.ID,
+		ScriptID:      sc.ID,
+		VersionID:     *sc.PublishedVersionID,
+		CurrentStepID: &first.ID,
+		Status:        ProgressActive,
+		StepStartedAt: &now,
+		StartedAt:     now,
+		Source:        source,
+	}
+	if err := s.progress.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := s.progress.AddPathStep(ctx, p.ID, first.ID, nil, now); err != nil {
+		return nil, err
+	}
+
+	r := &run{script: sc, bot: bot, user: u}
+	if err := s.scheduleStep(ctx, r, p, first, now); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// StartPreview runs a version of the bot's script for u, one of its
+// managers: the draft, or the published version when there is no draft, if
+// number is 0. Delays and timeouts are compressed, quiet hours ignored, each
+// step is labelled and sent to u's private chat whatever its channel, and no
+// invite links are issued. Preview runs do not count as starting the script
+// and are left out of analytics; a new preview ends the previous one.
+func (s *Service) StartPreview(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string, u *user.User, number int) (*Progress, *Version, error) {
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := s.previewVersion(ctx, sc, number)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g, err := s.graph(ctx, v.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	first := g.Entry()
+	if first == nil {
+		return nil, nil, ErrNoSteps
+	}
+
+	now := time.Now().UTC()
+	if err := s.progress.FinishPreviews(ctx, u.ID, sc.ID, now); err != nil {
+		return nil, nil, err
+	}
+	p := &Progress{
+		UserID:        u.ID,
+		ScriptID:      sc.ID,
+		VersionID:     v.ID,
+		CurrentStepID: &first.ID,
+		Status:        ProgressActive,
+		StepStartedAt: &now,
+		StartedAt:     now,
+		Preview:       true,
+	}
+	if err := s.progress.Create(ctx, p); err != nil {
+		return nil, nil, err
+	}
+	if err := s.progress.AddPathStep(ctx, p.ID, first.ID, nil, now); err != nil {
+		return nil, nil, err
+	}
+
+	r := &run{script: sc, bot: bot, user: u}
+	if err := s.scheduleStep(ctx, r, p, first, now); err != nil {
+		return nil, nil, err
+	}
+	return p, v, nil
+}
+
+// previewVersion picks the version StartPreview runs.
+func (s *Service) previewVersion(ctx context.Context, sc *Script, number int) (*Version, error) {
+	if number > 0 {
+		return s.versions.GetByNumber(ctx, sc.ID, number)
+	}
+	draft, err := s.versions.GetDraft(ctx, sc.ID)
+	if err == nil {
+		return draft, nil
+	}
+	if !errors.Is(err, app_errors.ErrNotFound) {
+		return nil, err
+	}
+	if sc.PublishedVersionID == nil {
+		return nil, ErrNotPublished
+	}
+	return s.versions.GetByID(ctx, *sc.PublishedVersionID)
+}
+
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*Script, error) {
+	return s.scripts.GetByID(ctx, id)
+}
+
+// DeepLinks lists the deep links of the bot.
+func (s *Service) DeepLinks(ctx context.Context, telegramBotID uuid.UUID) ([]*DeepLink, error) {
+	return s.links.List(ctx, telegramBotID)
+}
+
+// SaveDeepLink points payload on the bot at the script with the given name.
+func (s *Service) SaveDeepLink(ctx context.Context, bot *telegram_bot.TelegramBot, payload, scriptName, source string) (*DeepLink, error) {
+	if err := ValidatePayload(payload); err != nil {
+		return nil, err
+	}
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	link := &DeepLink{
+		TelegramBotID: bot.ID,
+		Payload:       payload,
+		ScriptID:      sc.ID,
+		Source:        source,
+	}
+	if err := s.links.Save(ctx, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (s *Service) DeleteDeepLink(ctx context.Context, telegramBotID uuid.UUID, payload string) error {
+	return s.links.Delete(ctx, telegramBotID, payload)
+}
+
+// SetPrivateGroup links the bot's script to a private group whose invite
+// links its steps hand out, or unlinks it when groupID is nil.
+func (s *Service) SetPrivateGroup(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string, groupID *uuid.UUID) (*Script, error) {
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scripts.SetPrivateGroup(ctx, sc.ID, groupID); err != nil {
+		return nil, err
+	}
+	sc.PrivateGroupID = groupID
+	return sc, nil
+}
+
+// Validate checks the graph of the script version, see Graph.Validate.
+func (s *Service) Validate(ctx context.Context, versionID uuid.UUID) error {
+	g, err := s.graph(ctx, versionID)
+	if err != nil {
+		return err
+	}
+	return g.Validate()
+}
+
+// SaveTransitions replaces the transitions of the script's draft if the
+// resulting graph is valid.
+func (s *Service) SaveTransitions(ctx context.Context, scriptID uuid.UUID, transitions []*Transition) error {
+	draft, err := s.Draft(ctx, scriptID)
+	if err != nil {
+		return err
+	}
+	steps, err := s.scripts.ListSteps(ctx, draft.ID)
+	if err != nil {
+		return err
+	}
+	for _, t := range transitions {
+		t.ScriptID = scriptID
+		t.VersionID = draft.ID
+	}
+	if err := NewGraph(steps, transitions).Validate(); err != nil {
+		return err
+	}
+	return s.scripts.ReplaceTransitions(ctx, draft.ID, transitions)
+}
+
+// Versions lists the versions of the bot's script, newest first.
+func (s *Service) Versions(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string) (*Script, []*Version, error) {
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, nil, err
+	}
+	versions, err := s.versions.List(ctx, sc.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sc, versions, nil
+}
+
+// DraftByName returns the draft of the bot's script, see Draft.
+func (s *Service) DraftByName(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string) (*Version, error) {
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	return s.Draft(ctx, sc.ID)
+}
+
+// Draft returns the script's draft, creating it as a copy of the published
+// version when there is none.
+func (s *Service) Draft(ctx context.Context, scriptID uuid.UUID) (*Version, error) {
+	draft, err := s.versions.GetDraft(ctx, scriptID)
+	if err == nil {
+		return draft, nil
+	}
+	if !errors.Is(err, app_errors.ErrNotFound) {
+		return nil, err
+	}
+	sc, err := s.scripts.GetByID(ctx, scriptID)
+	if err != nil {
+		return nil, err
+	}
+	return s.versions.CreateDraft(ctx, scriptID, sc.PublishedVersionID)
+}
+
+// DraftSteps returns the draft of the bot's script with its steps, see
+// Draft.
+func (s *Service) DraftSteps(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string) (*Version, []*Step, error) {
+	draft, err := s.DraftByName(ctx, bot, scriptName)
+	if err != nil {
+		return nil, nil, err
+	}
+	steps, err := s.scripts.ListSteps(ctx, draft.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return draft, steps, nil
+}
+
+// AddStep appends step to the draft of the bot's script. Steps go to the
+// private chat unless they have a channel.
+func (s *Service) AddStep(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string, step *Step) (*Version, error) {
+	draft, steps, err := s.DraftSteps(ctx, bot, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	if step.Channel == "" {
+		step.Channel = ChannelPrivate
+	}
+	if err := step.ValidateChannel(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScript, err)
+	}
+
+	step.ScriptID = draft.ScriptID
+	step.VersionID = draft.ID
+	step.Order = 1
+	if len(steps) > 0 {
+		step.Order = steps[len(steps)-1].Order + 1
+	}
+	if err := s.scripts.CreateStep(ctx, step); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// MoveStep swaps a step of a draft of the bot's scripts with the one before
+// it, or after it when up is false, and numbers the steps from 1. Steps at
+// either end stay in place. It returns the script and its draft steps in the
+// new order.
+func (s *Service) MoveStep(ctx context.Context, bot *telegram_bot.TelegramBot, stepID uuid.UUID, up bool) (*Script, []*Step, error) {
+	sc, step, err := s.draftStep(ctx, bot, stepID)
+	if err != nil {
+		return nil, nil, err
+	}
+	steps, err := s.scripts.ListSteps(ctx, step.VersionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i := slices.IndexFunc(steps, func(st *Step) bool { return st.ID == stepID })
+	if i < 0 {
+		return nil, nil, fmt.Errorf("step %s: %w", stepID, app_errors.ErrNotFound)
+	}
+	j := i + 1
+	if up {
+		j = i - 1
+	}
+	if j < 0 || j >= len(steps) {
+		return sc, steps, nil
+	}
+	steps[i], steps[j] = steps[j], steps[i]
+	if err := s.renumber(ctx, steps); err != nil {
+		return nil, nil, err
+	}
+	return sc, steps, nil
+}
+
+// DeleteStep removes a step from a draft of the bot's scripts together with
+// the transitions to and from it, and numbers the remaining steps from 1. It
+// returns the script and its remaining draft steps.
+func (s *Service) DeleteStep(ctx context.Context, bot *telegram_bot.TelegramBot, stepID uuid.UUID) (*Script, []*Step, error) {
+	sc, step, err := s.draftStep(ctx, bot, stepID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.scripts.DeleteStep(ctx, stepID); err != nil {
+		return nil, nil, err
+	}
+
+	steps, err := s.scripts.ListSteps(ctx, step.VersionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.renumber(ctx, steps); err != nil {
+		return nil, nil, err
+	}
+	return sc, steps, nil
+}
+
+// renumber numbers steps from 1 in the order given.
+func (s *Service) renumber(ctx context.Context, steps []*Step) error {
+	ids := make([]uuid.UUID, 0, len(steps))
+	for i, st := range steps {
+		st.Order = i + 1
+		ids = append(ids, st.ID)
+	}
+	return s.scripts.ReorderSteps(ctx, ids)
+}
+
+// draftStep returns a step of one of the bot's scripts with its script, and
+// ErrPublishedStep when the step is not in a draft.
+func (s *Service) draftStep(ctx context.Context, bot *telegram_bot.TelegramBot, stepID uuid.UUID) (*Script, *Step, error) {
+	step, err := s.scripts.GetStep(ctx, stepID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sc, err := s.scripts.GetByID(ctx, step.ScriptID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sc.TelegramBotID != bot.ID {
+		return nil, nil, fmt.Errorf("step %s: %w", stepID, app_errors.ErrNotFound)
+	}
+	v, err := s.versions.GetByID(ctx, step.VersionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !v.IsDraft() {
+		return nil, nil, ErrPublishedStep
+	}
+	return sc, step, nil
+}
+
+// Publish validates the draft of the bot's script and makes it the version
+// new users get. Users already going through the script stay on their
+// version.
+func (s *Service) Publish(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string) (*Version, error) {
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := s.versions.GetDraft(ctx, sc.ID)
+	if errors.Is(err, app_errors.ErrNotFound) {
+		return nil, ErrNoDraft
+	}
+	if err != nil {
+		return nil, err
+	}
+	g, err := s.graph(ctx, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	if g.Entry() == nil {
+		return nil, ErrNoSteps
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.versions.Publish(ctx, draft, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// Rollback makes the earlier published version with the given number the
+// one new users get.
+func (s *Service) Rollback(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string, number int) (*Version, error) {
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.versions.GetByNumber(ctx, sc.ID, number)
+	if err != nil {
+		return nil, err
+	}
+	if v.IsDraft() {
+		return nil, ErrDraftVersion
+	}
+	if err := s.versions.SetPublished(ctx, sc.ID, v.ID); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// VariantStats returns the conversions of the A/B test variants of a
+// version of the bot's script, the published one if number is 0.
+func (s *Service) VariantStats(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string, number int) (*Version, []VariantStats, error) {
+	v, err := s.reportVersion(ctx, bot, scriptName, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	stats, err := s.scripts.ListVariantStats(ctx, v.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, stats, nil
+}
+
+// Funnel builds the step funnel of a version of the bot's script, the
+// published one if number is 0, from the runs that match the filter.
+// Preview runs are left out.
+func (s *Service) Funnel(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string, number int, filter FunnelFilter) (*Version, []StepFunnel, error) {
+	v, err := s.reportVersion(ctx, bot, scriptName, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	steps, err := s.scripts.ListSteps(ctx, v.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := s.progress.ListFunnelEntries(ctx, v.ID, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, BuildFunnel(steps, entries), nil
+}
+
+// reportVersion returns the version of the bot's script with the number, or
+// the published one if number is 0.
+func (s *Service) reportVersion(ctx context.Context, bot *telegram_bot.TelegramBot, scriptName string, number int) (*Version, error) {
+	sc, err := s.scripts.GetByName(ctx, bot.ID, scriptName)
+	if err != nil {
+		return nil, err
+	}
+	if number > 0 {
+		return s.versions.GetByNumber(ctx, sc.ID, number)
+	}
+	if sc.PublishedVersionID == nil {
+		return nil, ErrNotPublished
+	}
+	return s.versions.GetByID(ctx, *sc.PublishedVersionID)
+}
+
+func (s *Service) graph(ctx context.Context, versionID uuid.UUID) (*Graph, error) {
+	steps, err := s.scripts.ListSteps(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := s.scripts.ListTransitions(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	return NewGraph(steps, transitions), nil
+}
+
+// Execute delivers a claimed scheduled step and schedules the next one.
+// Steps that became due inside a quiet window are moved to its end.
+func (s *Service) Execute(ctx context.Context, st *ScheduledStep) error {
+	now := time.Now().UTC()
+
+	p, err := s.progress.GetByID(ctx, st.ProgressID)
+	if err != nil {
+		return err
+	}
+	if st.Kind == KindTimeout {
+		return s.timeout(ctx, st, p, now)
+	}
+	if p.Status != ProgressActive {
+		return s.schedule.Cancel(ctx, st.ID)
+	}
+
+	r, err := s.loadRun(ctx, p)
+	if err != nil {
+		return err
+	}
+	if !r.bot.IsActive() {
+		return s.schedule.Defer(ctx, st.ID, now.Add(inactiveBotDelay), "bot is inactive")
+	}
+
+	if !p.Preview {
+		at, err := s.nextAllowedTime(ctx, r, now)
+		if err != nil {
+			return err
+		}
+		if at.After(now) {
+			return s.schedule.Defer(ctx, st.ID, at, "")
+		}
+	}
+
+	step, err := s.scripts.GetStep(ctx, st.StepID)
+	if err != nil {
+		return err
+	}
+
+	// users who are already subscribed pass the gate without seeing it
+	if step.Wait != nil && step.Wait.For == WaitSubscription && s.subscribed(ctx, r.bot, r.user, step.Wait.ChannelID) {
+		if err := s.schedule.MarkSent(ctx, st.ID, now); err != nil {
+			return err
+		}
+		input := Input{Type: InputSubscription, ChannelID: step.Wait.ChannelID}
+		if err := s.progress.CreateInput(ctx, p.ID, step.ID, input); err != nil {
+			return err
+		}
+		return s.advance(ctx, r, p, step, now)
+	}
+
+	if err := s.deliver(ctx, r, p, step, now); err != nil {
+		if !step.SkipOnError {
+			return err
+		}
+		s.logger.Warn("skipping failed step",
+			zap.String("progress_id", p.ID.String()),
+			zap.String("step_id", step.ID.String()),
+			zap.Error(err))
+		if err := s.schedule.MarkFailed(ctx, st.ID, err.Error()); err != nil {
+			return err
+		}
+		return s.advance(ctx, r, p, step, now)
+	}
+
+	if err := s.schedule.MarkSent(ctx, st.ID, now); err != nil {
+		return err
+	}
+	if step.Wait != nil {
+		return s.wait(ctx, p, step, now)
+	}
+	return s.advance(ctx, r, p, step, now)
+}
+
+// HandleInput applies something the user sent to the bot to the step they
+// are waiting on. It reports whether the input met the wait condition and
+// moved the script on. Attributes and tags of a pressed button are applied
+// whether or not the script waits for it.
+func (s *Service) HandleInput(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User, input Input) (bool, error) {
+	if input.Type == InputButton {
+		if err := s.pressButton(ctx, bot, u, input.ButtonID); err != nil {
+			return false, err
+		}
+	}
+
+	p, err := s.progress.GetWaiting(ctx, u.ID, bot.ID)
+	if err != nil {
+		if errors.Is(err, app_errors.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if p.CurrentStepID == nil {
+		return false, nil
+	}
+
+	step, err := s.scripts.GetStep(ctx, *p.CurrentStepID)
+	if err != nil {
+		return false, err
+	}
+	if step.Wait == nil {
+		return false, nil
+	}
+
+	var (
+		buttons   []message.Button
+		buttonIDs []uuid.UUID
+	)
+	if step.Wait.For == WaitButton {
+		msg, err := s.messages.GetByID(ctx, stepMessageID(step, u))
+		if err != nil {
+			return false, err
+		}
+		buttons = msg.Buttons
+		for _, b := range buttons {
+			buttonIDs = append(buttonIDs, b.ID)
+		}
+	}
+	if !step.Wait.Matches(input, buttonIDs) {
+		return false, nil
+	}
+
+	resumed, err := s.progress.Resume(ctx, p.ID)
+	if err != nil || !resumed {
+		return false, err
+	}
+	p.Status = ProgressActive
+	p.WaitingFor = ""
+
+	if err := s.schedule.CancelForProgress(ctx, p.ID); err != nil {
+		return true, err
+	}
+	if err := s.progress.CreateInput(ctx, p.ID, step.ID, input); err != nil {
+		return true, err
+	}
+	if step.SaveAs != "" {
+		if err := s.saveAnswer(ctx, bot, u, step.SaveAs, input.Value(buttons)); err != nil {
+			return true, err
+		}
+	}
+
+	sc, err := s.scripts.GetByID(ctx, p.ScriptID)
+	if err != nil {
+		return true, err
+	}
+	r := &run{script: sc, bot: bot, user: u}
+	return true, s.advance(ctx, r, p, step, time.Now().UTC())
+}
+
+// CheckSubscription checks again whether u subscribed to the channel the
+// script waits on and moves the script on if so. It reports whether the
+// subscription was confirmed.
+func (s *Service) CheckSubscription(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User) (bool, error) {
+	p, err := s.progress.GetWaiting(ctx, u.ID, bot.ID)
+	if err != nil {
+		if errors.Is(err, app_errors.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if p.WaitingFor != WaitSubscription || p.CurrentStepID == nil {
+		return false, nil
+	}
+	step, err := s.scripts.GetStep(ctx, *p.CurrentStepID)
+	if err != nil {
+		return false, err
+	}
+	if step.Wait == nil || step.Wait.For != WaitSubscription {
+		return false, nil
+	}
+
+	member, err := s.members.IsMember(ctx, bot.BotID, step.Wait.ChannelID, u.TelegramID)
+	if err != nil || !member {
+		return false, err
+	}
+	return s.HandleInput(ctx, bot, u, Input{Type: InputSubscription, ChannelID: step.Wait.ChannelID})
+}
+
+// HandleSubscribed moves on the script of a Telegram user who joined a chat
+// of the bot, if the script waits for that subscription.
+func (s *Service) HandleSubscribed(ctx context.Context, bot *telegram_bot.TelegramBot, chatID, telegramUserID int64) error {
+	u, err := s.users.GetByTelegramID(ctx, &telegramUserID)
+	if err != nil {
+		if errors.Is(err, app_errors.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	_, err = s.HandleInput(ctx, bot, u, Input{Type: InputSubscription, ChannelID: chatID})
+	return err
+}
+
+// subscribed reports whether u is a member of the channel. Failed checks,
+// e.g. when the bot is not an administrator of the channel, count as not
+// subscribed so the user is shown the gate.
+func (s *Service) subscribed(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User, channelID int64) bool {
+	member, err := s.members.IsMember(ctx, bot.BotID, channelID, u.TelegramID)
+	if err != nil {
+		s.logger.Warn("failed to check channel subscription",
+			zap.Int64("channel_id", channelID),
+			zap.Int64("telegram_id", u.TelegramID),
+			zap.Error(err))
+		return false
+	}
+	return member
+}
+
+// pressButton records the click and sets the attribute and adds the tag
+// configured on the button. Callback data comes from the client, so buttons
+// that were never delivered to u on this bot are ignored.
+func (s *Service) pressButton(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User, buttonID uuid.UUID) error {
+	delivered, err := s.progress.IsButtonDelivered(ctx, buttonID, u.ID, bot.ID)
+	if err != nil {
+		return err
+	}
+	if !delivered {
+		s.logger.Warn("ignoring press of a button not delivered to the user",
+			zap.String("button_id", buttonID.String()),
+			zap.String("bot_id", bot.ID.String()))
+		return nil
+	}
+
+	b, err := s.messages.GetButton(ctx, buttonID)
+	if err != nil {
+		if errors.Is(err, app_errors.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := s.messages.CreateClick(ctx, &message.Click{ButtonID: b.ID, UserID: u.ID, ClickedAt: time.Now().UTC()}); err != nil {
+		return err
+	}
+	if b.SetAttribute != "" {
+		if err := s.saveAnswer(ctx, bot, u, b.SetAttribute, b.AttributeValue()); err != nil {
+			return err
+		}
+	}
+	if b.AddTag != "" {
+		tag := user.NormalizeKey(b.AddTag)
+		if err := user.ValidateKey(tag); err != nil {
+			s.logger.Warn("ignoring invalid button tag",
+				zap.String("button_id", b.ID.String()),
+				zap.Error(err))
+			return nil
+		}
+		return s.attributes.AddTag(ctx, u.ID, bot.ID, tag)
+	}
+	return nil
+}
+
+// saveAnswer stores value in the user attribute key. Keys that fail
+// validation are logged and skipped so a misconfigured step does not stall
+// the script.
+func (s *Service) saveAnswer(ctx context.Context, bot *telegram_bot.TelegramBot, u *user.User, key, value string) error {
+	key = user.NormalizeKey(key)
+	if err := user.ValidateKey(key); err != nil {
+		s.logger.Warn("ignoring invalid attribute key", zap.Error(err))
+		return nil
+	}
+	if value == "" {
+		return nil
+	}
+	return s.attributes.Set(ctx, u.ID, bot.ID, key, value)
+}
+
+// wait holds the progress on step until the user meets its condition and
+// schedules the timeout, if any.
+func (s *Service) wait(ctx context.Context, p *Progress, step *Step, now time.Time) error {
+	p.Status = ProgressWaiting
+	p.WaitingFor = step.Wait.For
+	if err := s.progress.Update(ctx, p); err != nil {
+		return err
+	}
+	if step.Wait.Timeout <= 0 {
+		return nil
+	}
+	timeout := step.Wait.Timeout
+	if p.Preview {
+		timeout = PreviewDelay(timeout)
+	}
+	return s.schedule.Create(ctx, &ScheduledStep{
+		ProgressID: p.ID,
+		StepID:     step.ID,
+		ExecuteAt:  now.Add(timeout),
+		Kind:       KindTimeout,
+		Status:     ScheduledPending,
+	})
+}
+
+// timeout moves a progress still waiting on the step to the step's fallback.
+func (s *Service) timeout(ctx context.Context, st *ScheduledStep, p *Progress, now time.Time) error {
+	if p.CurrentStepID == nil || *p.CurrentStepID != st.StepID {
+		return s.schedule.Cancel(ctx, st.ID)
+	}
+	resumed, err := s.progress.Resume(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	if !resumed {
+		return s.schedule.Cancel(ctx, st.ID)
+	}
+	p.Status = ProgressActive
+	p.WaitingFor = ""
+
+	if err := s.schedule.MarkSent(ctx, st.ID, now); err != nil {
+		return err
+	}
+
+	step, err := s.scripts.GetStep(ctx, st.StepID)
+	if err != nil {
+		return err
+	}
+	if step.Wait == nil || step.Wait.FallbackStepID == nil {
+		p.Status = ProgressFinished
+		p.FinishedAt = &now
+		return s.progress.Update(ctx, p)
+	}
+
+	fallback, err := s.scripts.GetStep(ctx, *step.Wait.FallbackStepID)
+	if err != nil {
+		return err
+	}
+	r, err := s.loadRun(ctx, p)
+	if err != nil {
+		return err
+	}
+	return s.moveTo(ctx, r, p, fallback, nil, now)
+}
+
+// OverdueSteps counts per bot the scheduled steps still waiting that were due
+// before dueBefore.
+func (s *Service) OverdueSteps(ctx context.Context, dueBefore time.Time) ([]*Overdue, error) {
+	return s.schedule.ListOverdue(ctx, dueBefore.UTC())
+}
+
+// Retry puts a failed step back in the queue at retryAt.
+func (s *Service) Retry(ctx context.Context, st *ScheduledStep, retryAt time.Time, cause error) error {
+	return s.schedule.Reschedule(ctx, st.ID, retryAt.UTC(), cause.Error())
+}
+
+// Fail gives up on a step and stops the user's progress through the script.
+func (s *Service) Fail(ctx context.Context, st *ScheduledStep, cause error) error {
+	if err := s.schedule.MarkFailed(ctx, st.ID, cause.Error()); err != nil {
+		return err
+	}
+	p, err := s.progress.GetByID(ctx, st.ProgressID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	p.Status = ProgressFailed
+	p.FinishedAt = &now
+	return s.progress.Update(ctx, p)
+}
+
+func (s *Service) loadRun(ctx context.Context, p *Progress) (*run, error) {
+	sc, err := s.scripts.GetByID(ctx, p.ScriptID)
+	if err != nil {
+		return nil, err
+	}
+	bot, err := s.bots.GetByID(ctx, sc.TelegramBotID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &run{script: sc, bot: bot, user: u}, nil
+}
+
+// advance moves the progress along the transition that matches the user's
+// answers and deliveries, or finishes it when there is none.
+func (s *Service) advance(ctx context.Context, r *run, p *Progress, step *Step, now time.Time) error {
+	g, err := s.graph(ctx, p.VersionID)
+	if err != nil {
+		return err
+	}
+
+	var state State
+	if g.HasTransitions(step.ID) {
+		state, err = s.state(ctx, r, p)
+		if err != nil {
+			return err
+		}
+	}
+
+	next, transition := g.Next(step, state)
+	if next == nil {
+		p.Status = ProgressFinished
+		p.FinishedAt = &now
+		return s.progress.Update(ctx, p)
+	}
+
+	var transitionID *uuid.UUID
+	if transition != nil {
+		transitionID = &transition.ID
+	}
+	return s.moveTo(ctx, r, p, next, transitionID, now)
+}
+
+func (s *Service) state(ctx context.Context, r *run, p *Progress) (State, error) {
+	answers, err := s.progress.ListAnswers(ctx, p.ID)
+	if err != nil {
+		return State{}, err
+	}
+	delivered, err := s.progress.ListDeliveredSteps(ctx, p.ID)
+	if err != nil {
+		return State{}, err
+	}
+	attrs, err := s.attributes.Get(ctx, r.user.ID, r.bot.ID)
+	if err != nil {
+		return State{}, err
+	}
+	state := NewState(answers, delivered)
+	state.Attributes = attrs.Values
+	state.Tags = attrs.Tags
+	return state, nil
+}
+
+// moveTo makes step the current step of the progress and schedules it.
+func (s *Service) moveTo(ctx context.Context, r *run, p *Progress, step *Step, transitionID *uuid.UUID, now time.Time) error {
+	p.CurrentStepID = &step.ID
+	p.StepStartedAt = &now
+	if err := s.progress.Update(ctx, p); err != nil {
+		return err
+	}
+	if err := s.progress.AddPathStep(ctx, p.ID, step.ID, transitionID, now); err != nil {
+		return err
+	}
+	return s.scheduleStep(ctx, r, p, step, now)
+}
+
+func (s *Service) scheduleStep(ctx context.Context, r *run, p *Progress, step *Step, base time.Time) error {
+	executeAt := base.Add(PreviewDelay(step.Timing))
+	if !p.Preview {
+		var err error
+		executeAt, err = s.nextAllowedTime(ctx, r, base.Add(step.Timing))
+		if err != nil {
+			return err
+		}
+	}
+	return s.schedule.Create(ctx, &ScheduledStep{
+		ProgressID: p.ID,
+		StepID:     step.ID,
+		ExecuteAt:  executeAt,
+		Kind:       KindDeliver,
+		Status:     ScheduledPending,
+	})
+}
+
+// nextAllowedTime shifts t out of the bot's quiet hours, evaluated in the
+// user's timezone or the bot's one when the user's is unknown.
+func (s *Service) nextAllowedTime(ctx context.Context, r *run, t time.Time) (time.Time, error) {
+	windows, err := s.bots.GetQuietHours(ctx, r.bot.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(windows) == 0 {
+		return t, nil
+	}
+	loc := r.user.Location(r.bot.Location())
+	return telegram_bot.NextAllowedTime(t, loc, windows).UTC(), nil
+}
+
+type deliverySnapshot struct {
+	Content   string           `json:"content"`
+	ParseMode string           `json:"parse_mode,omitempty"`
+	Media     string           `json:"media,omitempty"`
+	Buttons   []snapshotButton `json:"buttons"`
+	Variant   string           `json:"variant,omitempty"`
+}
+
+// send delivers out to the chat of the step's channel. Previews of all
+// channels go to the user's private chat.
+func (s *Service) send(ctx context.Context, r *run, p *Progress, step *Step, out telegram.OutgoingMessage) (int, error) {
+	channel := step.Channel
+	if p.Preview {
+		channel = ChannelPrivate
+	}
+	switch channel {
+	case ChannelGroup:
+		out.ChatID = step.TargetChatID
+		return s.sender.SendToGroup(ctx, r.bot.BotID, out, step.TargetThreadID)
+	case ChannelPost:
+		out.ChatID = step.TargetChatID
+		return s.sender.Post(ctx, r.bot.BotID, out)
+	default:
+		out.ChatID = r.user.TelegramID
+		return s.sender.Send(ctx, r.bot.BotID, out)
+	}
+}
+
+type snapshotButton struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+	URL  string    `json:"url,omitempty"`
+}
+
+// templateVars collects the values message templates of the run are
+// rendered with.
+func (s *Service) templateVars(ctx context.Context, r *run, now time.Time) (message.Vars, error) {
+	attrs, err := s.attributes.Get(ctx, r.user.ID, r.bot.ID)
+	if err != nil {
+		return message.Vars{}, err
+	}
+	return message.Vars{
+		FirstName:   r.user.FirstName,
+		LastName:    r.user.LastName,
+		Username:    r.user.Username,
+		BotName:     r.bot.FirstName,
+		BotUsername: r.bot.Username,
+		Attributes:  attrs.Values,
+		Now:         now,
+		Location:    r.user.Location(r.bot.Location()),
+	}, nil
+}
+
+// stepMessageID returns the message u gets on the step: the one of their
+// variant when the step has variants. Shared steps are posted without
+// variants.
+func stepMessageID(step *Step, u *user.User) uuid.UUID {
+	if step.Shared() {
+		return step.MessageID
+	}
+	if v := step.PickVariant(u.ID); v != nil {
+		return v.MessageID
+	}
+	return step.MessageID
+}
+
+// stepMedia loads the file sent with a step message, nil when it has none.
+// Telegram sends one file per message, so only the first one is used.
+func (s *Service) stepMedia(ctx context.Context, messageID uuid.UUID) (*telegram.Media, error) {
+	files, err := s.messages.ListMedia(ctx, messageID)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	data, err := s.media.Get(ctx, files[0].StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media %s: %w", files[0].StorageKey, err)
+	}
+	return &telegram.Media{Kind: files[0].Kind, FileName: files[0].StorageKey, Data: data}, nil
+}
+
+// sharedVars are the values a shared step is rendered with. They leave out
+// everything about the user whose run posts it.
+func sharedVars(r *run, now time.Time) message.Vars {
+	return message.Vars{
+		BotName:     r.bot.FirstName,
+		BotUsername: r.bot.Username,
+		Now:         now,
+		Location:    r.bot.Location(),
+	}
+}
+
+// postShared posts a shared step unless the run of another user already did
+// and returns the Telegram ID of the post.
+func (s *Service) postShared(ctx context.Context, r *run, p *Progress, step *Step, out telegram.OutgoingMessage, now time.Time) (string, error) {
+	claimed, err := s.progress.ClaimStepPost(ctx, step.ID, now)
+	if err != nil {
+		return "", err
+	}
+	if !claimed {
+		post, err := s.progress.GetStepPost(ctx, step.ID)
+		if err != nil {
+			return "", err
+		}
+		return post.TelegramMessageID, nil
+	}
+
+	id, err := s.send(ctx, r, p, step, out)
+	if err != nil {
+		if err := s.progress.ReleaseStepPost(ctx, step.ID); err != nil {
+			s.logger.Error("failed to release step post",
+				zap.String("step_id", step.ID.String()),
+				zap.Error(err))
+		}
+		return "", fmt.Errorf("failed to send step message: %w", err)
+	}
+	telegramMessageID := strconv.Itoa(id)
+	if err := s.progress.SetStepPostMessage(ctx, step.ID, telegramMessageID); err != nil {
+		return "", err
+	}
+	return telegramMessageID, nil
+}
+
+func (s *Service) deliver(ctx context.Context, r *run, p *Progress, step *Step, now time.Time) error {
+	// shared steps are posted once for everybody who reaches them, so they
+	// carry no variant, user variables or personal links
+	shared := step.Shared() && !p.Preview
+	var variant *Variant
+	if !shared {
+		variant = step.PickVariant(r.user.ID)
+	}
+	messageID := step.MessageID
+	if variant != nil {
+		messageID = variant.MessageID
+	}
+	msg, err := s.messages.GetByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	content, err := message.ParseTemplate(msg.Content)
+	if err != nil {
+		return err
+	}
+	vars := sharedVars(r, now)
+	if !shared {
+		vars, err = s.templateVars(ctx, r, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	out := telegram.OutgoingMessage{
+		Text:      content.Render(vars, msg.ParseMode),
+		ParseMode: msg.ParseMode,
+	}
+	if step.Wait != nil && step.Wait.For == WaitContact {
+		out.RequestContact = ContactButtonText
+	}
+	media, err := s.stepMedia(ctx, msg.ID)
+	if err != nil {
+		return err
+	}
+	out.Media = media
+	deliveryID := uuid.New()
+	snapshot := deliverySnapshot{
+		Content:   out.Text,
+		ParseMode: msg.ParseMode,
+		Buttons:   make([]snapshotButton, 0, len(msg.Buttons)),
+	}
+	if media != nil {
+		snapshot.Media = media.FileName
+	}
+	if variant != nil {
+		snapshot.Variant = variant.Name
+	}
+	for _, b := range msg.Buttons {
+		label, err := message.ParseTemplate(b.Text)
+		if err != nil {
+			return fmt.Errorf("button %s: %w", b.ID, err)
+		}
+		// button labels are never formatted
+		text := label.Render(vars, "")
+		url := b.URL
+		// preview clicks are not tracked so they stay out of the stats, and
+		// redirect links name the user so they stay out of shared posts
+		if b.TrackClicks && url != "" && s.redirects != nil && !p.Preview && !shared {
+			url = s.redirects.URL(message.Redirect{
+				ButtonID:   b.ID,
+				UserID:     r.user.ID,
+				StepID:     step.ID,
+				DeliveryID: deliveryID,
+			})
+		}
+		out.Buttons = append(out.Buttons, telegram.Button{
+			Text: text,
+			URL:  url,
+			Data: ButtonCallbackPrefix + b.ID.String(),
+		})
+		snapshot.Buttons = append(snapshot.Buttons, snapshotButton{ID: b.ID, Text: text, URL: b.URL})
+	}
+	if step.Wait != nil && step.Wait.For == WaitSubscription {
+		out.Buttons = append(out.Buttons, telegram.Button{Text: CheckSubscriptionText, Data: CheckSubscriptionCallback})
+		snapshot.Buttons = append(snapshot.Buttons, snapshotButton{Text: CheckSubscriptionText})
+	}
+	if step.GrantsGroupAccess && r.script.PrivateGroupID != nil && !p.Preview && !shared {
+		link, err := s.groups.Invite(ctx, r.bot, *r.script.PrivateGroupID, r.user, p.ID)
+		if err != nil {
+			return fmt.Errorf("failed to issue group invite: %w", err)
+		}
+		out.Buttons = append(out.Buttons, telegram.Button{Text: GroupButtonText, URL: link})
+		snapshot.Buttons = append(snapshot.Buttons, snapshotButton{Text: GroupButtonText, URL: link})
+	}
+
+	var telegramMessageID string
+	if shared {
+		telegramMessageID, err = s.postShared(ctx, r, p, step, out, now)
+		if err != nil {
+			return err
+		}
+	} else {
+		id, err := s.send(ctx, r, p, step, out)
+		if err != nil {
+			return fmt.Errorf("failed to send step message: %w", err)
+		}
+		telegramMessageID = strconv.Itoa(id)
+	}
+
+	// the label follows the message so a retry of a failed send does not
+	// repeat it, and a failed label does not send the message again
+	if p.Preview {
+		label := telegram.OutgoingMessage{ChatID: r.user.TelegramID, Text: step.PreviewLabel()}
+		if variant != nil {
+			label.Text += ", variant " + variant.Name
+		}
+		if _, err := s.sender.Send(ctx, r.bot.BotID, label); err != nil {
+			s.logger.Warn("failed to send preview label",
+				zap.String("progress_id", p.ID.String()),
+				zap.String("step_id", step.ID.String()),
+				zap.Error(err))
+		}
+	}
+
+	rawSnapshot, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery snapshot: %w", err)
+	}
+
+	delivery := &Delivery{
+		ID:                deliveryID,
+		ProgressID:        p.ID,
+		StepID:            step.ID,
+		MessageID:         msg.ID,
+		Channel:           step.Channel,
+		Snapshot:          rawSnapshot,
+		TelegramMessageID: telegramMessageID,
+		SentAt:            now,
+	}
+	if variant != nil {
+		delivery.VariantID = &variant.ID
+	}
+	return s.progress.CreateDelivery(ctx, delivery)
+}
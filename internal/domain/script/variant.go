@@ -0,0 +1,91 @@
+package script
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Variant is one of the weighted messages a step sends in an A/B test.
+// Steps with variants send one of them instead of their own message.
+type Variant struct {
+	ID        uuid.UUID
+	StepID    uuid.UUID
+	MessageID uuid.UUID
+	Name      string
+	Weight    int
+}
+
+// VariantStats are the conversions of a variant in one script version.
+// Preview runs are not counted.
+type VariantStats struct {
+	StepOrder int
+	Name      string
+	Weight    int
+	// Delivered counts the users the variant was sent to, Clicked those of
+	// them who pressed one of its buttons and Advanced those who reached
+	// another step afterwards.
+	Delivered int64
+	Clicked   int64
+	Advanced  int64
+}
+
+// PickVariant returns the variant the user gets on the step, or nil when the
+// step has no variants. The choice depends only on the step and the user, so
+// it is the same on every attempt, and follows the variants' weights across
+// users.
+func (s *Step) PickVariant(userID uuid.UUID) *Variant {
+	total := 0
+	for _, v := range s.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	h := fnv.New64a()
+	h.Write(s.ID[:])
+	h.Write(userID[:])
+	point := int(h.Sum64() % uint64(total))
+	for i := range s.Variants {
+		point -= s.Variants[i].Weight
+		if point < 0 {
+			return &s.Variants[i]
+		}
+	}
+	return nil
+}
+
+// MessageIDs returns the messages the step can send: its own one and those
+// of its variants.
+func (s *Step) MessageIDs() []uuid.UUID {
+	ids := []uuid.UUID{s.MessageID}
+	for _, v := range s.Variants {
+		if v.MessageID != s.MessageID {
+			ids = append(ids, v.MessageID)
+		}
+	}
+	return ids
+}
+
+// ValidateVariants checks that variant names are set and unique and that
+// weights are positive.
+func (s *Step) ValidateVariants() error {
+	names := make(map[string]bool, len(s.Variants))
+	for _, v := range s.Variants {
+		name := strings.TrimSpace(v.Name)
+		if name == "" {
+			return fmt.Errorf("variant name is empty")
+		}
+		if names[name] {
+			return fmt.Errorf("variant %q is repeated", name)
+		}
+		names[name] = true
+		if v.Weight <= 0 {
+			return fmt.Errorf("weight of variant %q must be positive, got %d", name, v.Weight)
+		}
+	}
+	return nil
+}
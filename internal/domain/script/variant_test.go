@@ -0,0 +1,59 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPickVariant(t *testing.T) {
+	step := Step{
+		ID: uuid.New(),
+		Variants: []Variant{
+			{Name: "A", Weight: 3},
+			{Name: "B", Weight: 1},
+		},
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		userID := uuid.New()
+		v := step.PickVariant(userID)
+		if v == nil {
+			t.Fatal("PickVariant() = nil for a step with variants")
+		}
+		if again := step.PickVariant(userID); again.Name != v.Name {
+			t.Fatalf("PickVariant() = %s, then %s for the same user", v.Name, again.Name)
+		}
+		counts[v.Name]++
+	}
+	if counts["A"] < 2700 || counts["A"] > 3300 {
+		t.Errorf("variant A picked %d times out of 4000, want about 3000", counts["A"])
+	}
+
+	if v := (&Step{ID: uuid.New()}).PickVariant(uuid.New()); v != nil {
+		t.Errorf("PickVariant() = %s for a step without variants", v.Name)
+	}
+}
+
+func TestValidateVariants(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []Variant
+		wantErr  bool
+	}{
+		{"none", nil, false},
+		{"valid", []Variant{{Name: "A", Weight: 1}, {Name: "B", Weight: 2}}, false},
+		{"empty name", []Variant{{Name: " ", Weight: 1}}, true},
+		{"repeated name", []Variant{{Name: "A", Weight: 1}, {Name: "A", Weight: 1}}, true},
+		{"zero weight", []Variant{{Name: "A", Weight: 0}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := Step{Variants: tt.variants}
+			if err := step.ValidateVariants(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateVariants() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package telegram_bot
+
+import "errors"
+
+var (
+	ErrNotManager = errors.New("user does not manage this bot")
+	ErrNotOwner   = errors.New("user does not own this bot")
+	ErrAdminBot   = errors.New("the admin bot cannot be disabled or deleted")
+)
@@ -14,10 +14,28 @@ type TelegramBot struct {
 	FirstName  string
 	LastName   string
 	Role       string
+	Timezone   string
 	RevokedAt  *time.Time
 	DisabledAt *time.Time
+	// LastError is the error of the last attempt to connect to the bot,
+	// made at LastCheckedAt.
+	LastError     string
+	LastCheckedAt *time.Time
 }
 
 func (tb *TelegramBot) IsActive() bool {
 	return tb.RevokedAt == nil && tb.DisabledAt == nil
 }
+
+// Location returns the bot's default timezone, used for users whose own
+// timezone is unknown.
+func (tb *TelegramBot) Location() *time.Location {
+	if tb.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(tb.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,101 @@
+package telegram_bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+// QuietWindow is a daily period, in minutes since local midnight, during which
+// the bot must not message users. A window with Start > End wraps midnight.
+type QuietWindow struct {
+	Start int
+	End   int
+}
+
+// ParseQuietWindow parses a window in the form "22:00-08:00".
+func ParseQuietWindow(s string) (QuietWindow, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return QuietWindow{}, fmt.Errorf("quiet window must be HH:MM-HH:MM, got: %q", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietWindow{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietWindow{}, err
+	}
+	w := QuietWindow{Start: start, End: end}
+	if err := w.Validate(); err != nil {
+		return QuietWindow{}, err
+	}
+	return w, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", s, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (w QuietWindow) Validate() error {
+	if w.Start < 0 || w.Start >= minutesPerDay || w.End < 0 || w.End >= minutesPerDay {
+		return fmt.Errorf("quiet window bounds must be within a day, got: %d-%d", w.Start, w.End)
+	}
+	if w.Start == w.End {
+		return fmt.Errorf("quiet window must not be empty")
+	}
+	return nil
+}
+
+func (w QuietWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60)
+}
+
+// contains reports whether minute of the day falls inside the window.
+// The end of the window is exclusive.
+func (w QuietWindow) contains(minute int) bool {
+	if w.Start < w.End {
+		return minute >= w.Start && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+// NextAllowedTime returns t if it is outside every quiet window in loc,
+// otherwise the moment the quiet period covering t ends. Overlapping or
+// adjacent windows are skipped together.
+func NextAllowedTime(t time.Time, loc *time.Location, windows []QuietWindow) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	for range len(windows) + 1 {
+		local := t.In(loc)
+		minute := local.Hour()*60 + local.Minute()
+
+		moved := false
+		for _, w := range windows {
+			if !w.contains(minute) {
+				continue
+			}
+			day := local
+			if w.Start > w.End && minute >= w.Start {
+				day = local.AddDate(0, 0, 1)
+			}
+			end := time.Date(day.Year(), day.Month(), day.Day(), w.End/60, w.End%60, 0, 0, loc)
+			if end.After(t) {
+				t = end
+				moved = true
+			}
+		}
+		if !moved {
+			return t
+		}
+	}
+	return t
+}
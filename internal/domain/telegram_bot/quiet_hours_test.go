@@ -0,0 +1,91 @@
+package telegram_bot
+
+import (
+	"testing"
+	"time"
+)
+
+func mustWindow(t *testing.T, s string) QuietWindow {
+	t.Helper()
+	w, err := ParseQuietWindow(s)
+	if err != nil {
+		t.Fatalf("ParseQuietWindow(%q): %v", s, err)
+	}
+	return w
+}
+
+func TestParseQuietWindow(t *testing.T) {
+	w := mustWindow(t, "22:30-08:00")
+	if w.Start != 22*60+30 || w.End != 8*60 {
+		t.Fatalf("unexpected window: %+v", w)
+	}
+	if w.String() != "22:30-08:00" {
+		t.Fatalf("unexpected string: %s", w.String())
+	}
+
+	for _, s := range []string{"", "22:00", "25:00-08:00", "08:00-08:00", "aa-bb"} {
+		if _, err := ParseQuietWindow(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestNextAllowedTime(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	night := mustWindow(t, "22:00-08:00")
+	lunch := mustWindow(t, "13:00-14:00")
+	windows := []QuietWindow{night, lunch}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{
+			name: "outside windows",
+			at:   time.Date(2025, 1, 10, 10, 0, 0, 0, moscow),
+			want: time.Date(2025, 1, 10, 10, 0, 0, 0, moscow),
+		},
+		{
+			name: "before midnight",
+			at:   time.Date(2025, 1, 10, 23, 15, 0, 0, moscow),
+			want: time.Date(2025, 1, 11, 8, 0, 0, 0, moscow),
+		},
+		{
+			name: "after midnight",
+			at:   time.Date(2025, 1, 11, 3, 0, 0, 0, moscow),
+			want: time.Date(2025, 1, 11, 8, 0, 0, 0, moscow),
+		},
+		{
+			name: "end is exclusive",
+			at:   time.Date(2025, 1, 11, 8, 0, 0, 0, moscow),
+			want: time.Date(2025, 1, 11, 8, 0, 0, 0, moscow),
+		},
+		{
+			name: "daytime window",
+			at:   time.Date(2025, 1, 11, 13, 59, 30, 0, moscow),
+			want: time.Date(2025, 1, 11, 14, 0, 0, 0, moscow),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// pass UTC input to make sure the window is evaluated in loc
+			got := NextAllowedTime(tt.at.UTC(), moscow, windows)
+			if !got.Equal(tt.want) {
+				t.Fatalf("got %v, want %v", got.In(moscow), tt.want)
+			}
+		})
+	}
+}
+
+func TestNextAllowedTimeChainedWindows(t *testing.T) {
+	windows := []QuietWindow{mustWindow(t, "22:00-23:00"), mustWindow(t, "23:00-07:00")}
+	at := time.Date(2025, 1, 10, 22, 30, 0, 0, time.UTC)
+	want := time.Date(2025, 1, 11, 7, 0, 0, 0, time.UTC)
+	if got := NextAllowedTime(at, time.UTC, windows); !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
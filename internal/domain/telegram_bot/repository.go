@@ -9,9 +9,22 @@ type Repository interface {
 	Create(ctx context.Context, bot *TelegramBot) error
 	GetByID(ctx context.Context, id uuid.UUID) (*TelegramBot, error)
 	GetByTelegramID(ctx context.Context, telegramID int64) (*TelegramBot, error)
+	GetByUsername(ctx context.Context, username string) (*TelegramBot, error)
 	Update(ctx context.Context, bot *TelegramBot) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	ListAll(ctx context.Context) ([]*TelegramBot, error)
+	// ListOwned returns the bots userID owns, ordered by username.
+	ListOwned(ctx context.Context, userID uuid.UUID) ([]*TelegramBot, error)
+	// RecordCheck stamps the bot with the time it was last connected to and
+	// the error of that attempt, cleared when checkErr is nil.
+	RecordCheck(ctx context.Context, id uuid.UUID, checkErr error) error
+
+	// GetMemberRole returns the role (owner, admin, viewer) of a user that
+	// manages the bot.
+	GetMemberRole(ctx context.Context, botID, userID uuid.UUID) (string, error)
+
+	GetQuietHours(ctx context.Context, botID uuid.UUID) ([]QuietWindow, error)
+	SetQuietHours(ctx context.Context, botID uuid.UUID, windows []QuietWindow) error
 }
 
 type BotRegistry interface {
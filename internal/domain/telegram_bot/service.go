@@ -2,8 +2,13 @@ package telegram_bot
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/VladKovDev/promo-bot/internal/infrastructure/telegram"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/google/uuid"
 )
 
 type Service struct {
@@ -17,4 +22,161 @@ func NewService(repo Repository, sender telegram.Sender) *Service {
 
 func (s *Service) HandleStart(ctx context.Context, botID, ChatID int64) error {
 	return s.sender.SendMessage(ctx, botID, ChatID, "Welcome to the bot!")
-}
\ No newline at end of file
+}
+
+// GetByUsername returns the bot with the given username, with or without
+// the leading @.
+func (s *Service) GetByUsername(ctx context.Context, username string) (*TelegramBot, error) {
+	return s.repo.GetByUsername(ctx, username)
+}
+
+// GetManaged returns the bot with the given username if userID is its owner
+// or admin.
+func (s *Service) GetManaged(ctx context.Context, username string, userID uuid.UUID) (*TelegramBot, error) {
+	bot, err := s.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.repo.GetMemberRole(ctx, bot.ID, userID)
+	if err != nil {
+		if errors.Is(err, app_errors.ErrNotFound) {
+			return nil, ErrNotManager
+		}
+		return nil, err
+	}
+	if role != "owner" && role != "admin" {
+		return nil, ErrNotManager
+	}
+	return bot, nil
+}
+
+func (s *Service) ListAll(ctx context.Context) ([]*TelegramBot, error) {
+	return s.repo.ListAll(ctx)
+}
+
+// MarkRevoked records that Telegram no longer accepts the bot's token, so the
+// bot is not started anymore.
+func (s *Service) MarkRevoked(ctx context.Context, bot *TelegramBot) error {
+	if bot.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	bot.RevokedAt = &now
+	return s.repo.Update(ctx, bot)
+}
+
+// ListOwned returns the bots userID owns.
+func (s *Service) ListOwned(ctx context.Context, userID uuid.UUID) ([]*TelegramBot, error) {
+	return s.repo.ListOwned(ctx, userID)
+}
+
+// GetOwned returns the bot with the given username if userID is its owner.
+func (s *Service) GetOwned(ctx context.Context, username string, userID uuid.UUID) (*TelegramBot, error) {
+	bot, err := s.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOwner(ctx, bot.ID, userID); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// Disable marks the bot as disabled so it is not started anymore. The admin
+// bot cannot be disabled.
+func (s *Service) Disable(ctx context.Context, id, userID uuid.UUID) (*TelegramBot, error) {
+	bot, err := s.getOwnedByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if bot.Role == "admin" {
+		return nil, ErrAdminBot
+	}
+	if bot.DisabledAt != nil {
+		return bot, nil
+	}
+	now := time.Now().UTC()
+	bot.DisabledAt = &now
+	if err := s.repo.Update(ctx, bot); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// Enable clears the disabled mark of the bot. A bot with a revoked token
+// stays inactive.
+func (s *Service) Enable(ctx context.Context, id, userID uuid.UUID) (*TelegramBot, error) {
+	bot, err := s.getOwnedByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if bot.DisabledAt == nil {
+		return bot, nil
+	}
+	bot.DisabledAt = nil
+	if err := s.repo.Update(ctx, bot); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// Delete removes the bot with its scripts, users' data and settings. The
+// admin bot cannot be deleted.
+func (s *Service) Delete(ctx context.Context, id, userID uuid.UUID) (*TelegramBot, error) {
+	bot, err := s.getOwnedByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if bot.Role == "admin" {
+		return nil, ErrAdminBot
+	}
+	if err := s.repo.Delete(ctx, bot.ID); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+func (s *Service) getOwnedByID(ctx context.Context, id, userID uuid.UUID) (*TelegramBot, error) {
+	if err := s.checkOwner(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) checkOwner(ctx context.Context, botID, userID uuid.UUID) error {
+	role, err := s.repo.GetMemberRole(ctx, botID, userID)
+	if err != nil {
+		if errors.Is(err, app_errors.ErrNotFound) {
+			return ErrNotOwner
+		}
+		return err
+	}
+	if role != "owner" {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+func (s *Service) QuietHours(ctx context.Context, botID uuid.UUID) ([]QuietWindow, error) {
+	return s.repo.GetQuietHours(ctx, botID)
+}
+
+// SetQuietHours replaces the bot's quiet windows. An empty list disables
+// quiet hours.
+func (s *Service) SetQuietHours(ctx context.Context, botID uuid.UUID, windows []QuietWindow) error {
+	for _, w := range windows {
+		if err := w.Validate(); err != nil {
+			return err
+		}
+	}
+	return s.repo.SetQuietHours(ctx, botID, windows)
+}
+
+// SetTimezone sets the timezone used for users whose own timezone is unknown.
+func (s *Service) SetTimezone(ctx context.Context, bot *TelegramBot, timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q: %w", timezone, err)
+	}
+	bot.Timezone = timezone
+	return s.repo.Update(ctx, bot)
+}
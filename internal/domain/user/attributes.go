@@ -0,0 +1,144 @@
+package user
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var keyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// ValidateKey checks an attribute key or a tag: lowercase latin letters,
+// digits and underscores, starting with a letter.
+func ValidateKey(key string) error {
+	if !keyRe.MatchString(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// NormalizeKey lowercases key and trims it, so "Interested_In " and
+// "interested_in" refer to the same attribute.
+func NormalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// Attributes are the custom key/value attributes and tags a user has on a
+// bot, e.g. interested_in=course_b and the tag "vip".
+type Attributes struct {
+	Values map[string]string
+	Tags   map[string]bool
+}
+
+func NewAttributes() *Attributes {
+	return &Attributes{
+		Values: make(map[string]string),
+		Tags:   make(map[string]bool),
+	}
+}
+
+func (a *Attributes) Get(key string) (string, bool) {
+	v, ok := a.Values[key]
+	return v, ok
+}
+
+func (a *Attributes) HasTag(tag string) bool {
+	return a.Tags[tag]
+}
+
+// SortedTags returns the tags in alphabetical order.
+func (a *Attributes) SortedTags() []string {
+	tags := make([]string, 0, len(a.Tags))
+	for tag := range a.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Segment selects the users of a bot that have all Tags, none of
+// ExcludeTags and every attribute in Attributes set to the given value.
+// The zero Segment selects everyone.
+type Segment struct {
+	Tags        []string
+	ExcludeTags []string
+	Attributes  map[string]string
+}
+
+// ParseSegment parses a space-separated filter such as
+// "tag:vip -tag:churned interested_in=course_b".
+func ParseSegment(s string) (Segment, error) {
+	seg := Segment{Attributes: make(map[string]string)}
+	for _, term := range strings.Fields(s) {
+		switch {
+		case strings.HasPrefix(term, "-tag:"):
+			tag := NormalizeKey(strings.TrimPrefix(term, "-tag:"))
+			if err := ValidateKey(tag); err != nil {
+				return Segment{}, err
+			}
+			seg.ExcludeTags = append(seg.ExcludeTags, tag)
+		case strings.HasPrefix(term, "tag:"):
+			tag := NormalizeKey(strings.TrimPrefix(term, "tag:"))
+			if err := ValidateKey(tag); err != nil {
+				return Segment{}, err
+			}
+			seg.Tags = append(seg.Tags, tag)
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(term, "=")
+			key = NormalizeKey(key)
+			if err := ValidateKey(key); err != nil {
+				return Segment{}, err
+			}
+			if value == "" {
+				return Segment{}, fmt.Errorf("attribute %s needs a value", key)
+			}
+			seg.Attributes[key] = value
+		default:
+			return Segment{}, fmt.Errorf("unknown segment term %q, use tag:name, -tag:name or key=value", term)
+		}
+	}
+	return seg, nil
+}
+
+// Matches reports whether a user with attrs belongs to the segment.
+func (s Segment) Matches(attrs *Attributes) bool {
+	for _, tag := range s.Tags {
+		if !attrs.HasTag(tag) {
+			return false
+		}
+	}
+	for _, tag := range s.ExcludeTags {
+		if attrs.HasTag(tag) {
+			return false
+		}
+	}
+	for key, want := range s.Attributes {
+		if v, ok := attrs.Get(key); !ok || v != want {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Segment) String() string {
+	var terms []string
+	for _, tag := range s.Tags {
+		terms = append(terms, "tag:"+tag)
+	}
+	for _, tag := range s.ExcludeTags {
+		terms = append(terms, "-tag:"+tag)
+	}
+	keys := make([]string, 0, len(s.Attributes))
+	for key := range s.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		terms = append(terms, key+"="+s.Attributes[key])
+	}
+	if len(terms) == 0 {
+		return "everyone"
+	}
+	return strings.Join(terms, " ")
+}
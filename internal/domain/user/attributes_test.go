@@ -0,0 +1,54 @@
+package user
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSegment(t *testing.T) {
+	seg, err := ParseSegment("tag:VIP -tag:churned interested_in=course_b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seg.String(); got != "tag:vip -tag:churned interested_in=course_b" {
+		t.Errorf("String() = %q", got)
+	}
+
+	attrs := NewAttributes()
+	attrs.Tags["vip"] = true
+	attrs.Values["interested_in"] = "course_b"
+	if !seg.Matches(attrs) {
+		t.Error("expected user to match")
+	}
+	attrs.Tags["churned"] = true
+	if seg.Matches(attrs) {
+		t.Error("excluded tag must not match")
+	}
+
+	everyone, err := ParseSegment("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !everyone.Matches(NewAttributes()) || everyone.String() != "everyone" {
+		t.Error("empty segment must match everyone")
+	}
+
+	for _, in := range []string{"tag:", "vip", "interested_in=", "1key=value", "tag:has-dash"} {
+		if _, err := ParseSegment(in); err == nil {
+			t.Errorf("ParseSegment(%q): expected error", in)
+		}
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"vip", "interested_in", "step2"} {
+		if err := ValidateKey(key); err != nil {
+			t.Errorf("ValidateKey(%q): %v", key, err)
+		}
+	}
+	for _, key := range []string{"", "VIP", "2fast", "with space", "dash-ed"} {
+		if err := ValidateKey(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ValidateKey(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
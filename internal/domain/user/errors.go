@@ -0,0 +1,7 @@
+package user
+
+import "errors"
+
+var (
+	ErrInvalidKey = errors.New("attribute keys and tags must be lowercase latin letters, digits and underscores")
+)
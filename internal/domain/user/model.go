@@ -1,18 +1,40 @@
 package user
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
+const (
+	TimezoneInferred = "inferred"
+	TimezoneExplicit = "explicit"
+)
+
 type User struct {
-	ID         uuid.UUID
-	TelegramID int64
-	Username   string
-	FirstName  string
-	LastName   string
-	IsActive   bool
+	ID             uuid.UUID
+	TelegramID     int64
+	Username       string
+	FirstName      string
+	LastName       string
+	LanguageCode   string
+	Timezone       string
+	TimezoneSource string
+	IsActive       bool
 }
 
 func (u *User) Validate() error {
 	return nil
 }
+
+// Location returns the user's timezone, or fallback when it is unknown.
+func (u *User) Location(fallback *time.Location) *time.Location {
+	if u.Timezone == "" {
+		return fallback
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
@@ -9,10 +9,25 @@ type Repository interface {
 	Create(ctx context.Context, user *User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*User, error)
 	GetByTelegramID(ctx context.Context, telegramID *int64) (*User, error)
+	GetByUsername(ctx context.Context, username string) (*User, error)
 	Update(ctx context.Context, user *User) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Deactivate(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	SetTimezone(ctx context.Context, id uuid.UUID, timezone, source string) error
 
 	Count(ctx context.Context) (int64, error)
 	ListAll(ctx context.Context, limit, offset int) ([]*User, error)
+	// ListBySegment returns the bot's users in the segment, oldest first, and
+	// their total number. A user belongs to a bot once they started one of
+	// its scripts.
+	ListBySegment(ctx context.Context, telegramBotID uuid.UUID, segment Segment, limit, offset int) ([]*User, int64, error)
+}
+
+// AttributeRepository stores custom attributes and tags of users per bot.
+type AttributeRepository interface {
+	Get(ctx context.Context, userID, telegramBotID uuid.UUID) (*Attributes, error)
+	Set(ctx context.Context, userID, telegramBotID uuid.UUID, key, value string) error
+	Delete(ctx context.Context, userID, telegramBotID uuid.UUID, key string) error
+	AddTag(ctx context.Context, userID, telegramBotID uuid.UUID, tag string) error
+	RemoveTag(ctx context.Context, userID, telegramBotID uuid.UUID, tag string) error
 }
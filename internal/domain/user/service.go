@@ -1 +1,145 @@
-package user
\ No newline at end of file
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	repo       Repository
+	attributes AttributeRepository
+}
+
+func NewService(repo Repository, attributes AttributeRepository) *Service {
+	return &Service{
+		repo:       repo,
+		attributes: attributes,
+	}
+}
+
+// Register returns the stored user with u.TelegramID, creating it on first
+// contact. Profile fields are refreshed from u and the timezone is inferred
+// from the language code unless the user has set one explicitly.
+func (s *Service) Register(ctx context.Context, u *User) (*User, error) {
+	existing, err := s.repo.GetByTelegramID(ctx, &u.TelegramID)
+	if err != nil && !errors.Is(err, app_errors.ErrNotFound) {
+		return nil, err
+	}
+
+	if existing == nil {
+		if tz := InferTimezone(u.LanguageCode); tz != "" {
+			u.Timezone = tz
+			u.TimezoneSource = TimezoneInferred
+		}
+		if err := s.repo.Create(ctx, u); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+
+	if existing.Username != u.Username || existing.FirstName != u.FirstName ||
+		existing.LastName != u.LastName || existing.LanguageCode != u.LanguageCode {
+		existing.Username = u.Username
+		existing.FirstName = u.FirstName
+		existing.LastName = u.LastName
+		existing.LanguageCode = u.LanguageCode
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return nil, err
+		}
+	}
+
+	if existing.TimezoneSource != TimezoneExplicit {
+		if tz := InferTimezone(existing.LanguageCode); tz != "" && tz != existing.Timezone {
+			if err := s.repo.SetTimezone(ctx, existing.ID, tz, TimezoneInferred); err != nil {
+				return nil, err
+			}
+			existing.Timezone = tz
+			existing.TimezoneSource = TimezoneInferred
+		}
+	}
+	return existing, nil
+}
+
+// SetTimezone stores a timezone chosen by the user. It accepts anything
+// ParseTimezone does and returns the normalized name.
+func (s *Service) SetTimezone(ctx context.Context, id uuid.UUID, timezone string) (string, error) {
+	tz, err := ParseTimezone(timezone)
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.SetTimezone(ctx, id, tz, TimezoneExplicit); err != nil {
+		return "", fmt.Errorf("failed to set timezone: %w", err)
+	}
+	return tz, nil
+}
+
+// Find resolves a user by Telegram ID or @username.
+func (s *Service) Find(ctx context.Context, ref string) (*User, error) {
+	ref = strings.TrimSpace(ref)
+	if telegramID, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return s.repo.GetByTelegramID(ctx, &telegramID)
+	}
+	return s.repo.GetByUsername(ctx, ref)
+}
+
+// maxAttributeValueLength caps attribute values, which may come from free
+// text replies.
+const maxAttributeValueLength = 1024
+
+// Attributes returns the user's attributes and tags on the bot.
+func (s *Service) Attributes(ctx context.Context, userID, telegramBotID uuid.UUID) (*Attributes, error) {
+	return s.attributes.Get(ctx, userID, telegramBotID)
+}
+
+// SetAttribute stores an attribute of the user on the bot, replacing the
+// previous value.
+func (s *Service) SetAttribute(ctx context.Context, userID, telegramBotID uuid.UUID, key, value string) error {
+	key = NormalizeKey(key)
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fmt.Errorf("attribute %s needs a value", key)
+	}
+	if len(value) > maxAttributeValueLength {
+		return fmt.Errorf("attribute %s is longer than %d bytes", key, maxAttributeValueLength)
+	}
+	return s.attributes.Set(ctx, userID, telegramBotID, key, value)
+}
+
+func (s *Service) DeleteAttribute(ctx context.Context, userID, telegramBotID uuid.UUID, key string) error {
+	key = NormalizeKey(key)
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	return s.attributes.Delete(ctx, userID, telegramBotID, key)
+}
+
+func (s *Service) AddTag(ctx context.Context, userID, telegramBotID uuid.UUID, tag string) error {
+	tag = NormalizeKey(tag)
+	if err := ValidateKey(tag); err != nil {
+		return err
+	}
+	return s.attributes.AddTag(ctx, userID, telegramBotID, tag)
+}
+
+func (s *Service) RemoveTag(ctx context.Context, userID, telegramBotID uuid.UUID, tag string) error {
+	tag = NormalizeKey(tag)
+	if err := ValidateKey(tag); err != nil {
+		return err
+	}
+	return s.attributes.RemoveTag(ctx, userID, telegramBotID, tag)
+}
+
+// Segment returns a page of the bot's users in the segment and their total
+// number.
+func (s *Service) Segment(ctx context.Context, telegramBotID uuid.UUID, segment Segment, limit, offset int) ([]*User, int64, error) {
+	return s.repo.ListBySegment(ctx, telegramBotID, segment, limit, offset)
+}
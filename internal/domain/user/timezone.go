@@ -0,0 +1,77 @@
+package user
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// languageTimezones maps Telegram language codes to the most likely timezone
+// of their speakers. Regional tags are checked before the bare language.
+var languageTimezones = map[string]string{
+	"ru":    "Europe/Moscow",
+	"uk":    "Europe/Kyiv",
+	"be":    "Europe/Minsk",
+	"kk":    "Asia/Almaty",
+	"uz":    "Asia/Tashkent",
+	"ky":    "Asia/Bishkek",
+	"hy":    "Asia/Yerevan",
+	"ka":    "Asia/Tbilisi",
+	"az":    "Asia/Baku",
+	"de":    "Europe/Berlin",
+	"fr":    "Europe/Paris",
+	"es":    "Europe/Madrid",
+	"it":    "Europe/Rome",
+	"pl":    "Europe/Warsaw",
+	"tr":    "Europe/Istanbul",
+	"pt":    "Europe/Lisbon",
+	"pt-br": "America/Sao_Paulo",
+	"en-gb": "Europe/London",
+	"en-us": "America/New_York",
+}
+
+// InferTimezone guesses a timezone from a Telegram language code.
+// It returns an empty string when there is no reasonable guess.
+func InferTimezone(languageCode string) string {
+	code := strings.ToLower(strings.TrimSpace(languageCode))
+	if code == "" {
+		return ""
+	}
+	if tz, ok := languageTimezones[code]; ok {
+		return tz
+	}
+	lang, _, _ := strings.Cut(code, "-")
+	return languageTimezones[lang]
+}
+
+var utcOffsetRe = regexp.MustCompile(`^(?i:utc|gmt)?\s*([+-])(\d{1,2})(?::?00)?$`)
+
+// ParseTimezone accepts an IANA name ("Europe/Berlin") or a whole-hour UTC
+// offset ("UTC+3", "+03:00") and returns an IANA name.
+func ParseTimezone(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("timezone is empty")
+	}
+	if m := utcOffsetRe.FindStringSubmatch(s); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		if hours > 14 {
+			return "", fmt.Errorf("utc offset out of range: %s", s)
+		}
+		if hours == 0 {
+			return "UTC", nil
+		}
+		// Etc/GMT zones use inverted signs: UTC+3 is Etc/GMT-3.
+		sign := "-"
+		if m[1] == "-" {
+			sign = "+"
+		}
+		return "Etc/GMT" + sign + strconv.Itoa(hours), nil
+	}
+	if _, err := time.LoadLocation(s); err != nil {
+		return "", fmt.Errorf("unknown timezone %q: %w", s, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,45 @@
+package user
+
+import "testing"
+
+func TestInferTimezone(t *testing.T) {
+	tests := map[string]string{
+		"ru":    "Europe/Moscow",
+		"pt-BR": "America/Sao_Paulo",
+		"pt":    "Europe/Lisbon",
+		"de-AT": "Europe/Berlin",
+		"en":    "",
+		"":      "",
+	}
+	for code, want := range tests {
+		if got := InferTimezone(code); got != want {
+			t.Errorf("InferTimezone(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestParseTimezone(t *testing.T) {
+	tests := map[string]string{
+		"Europe/Berlin": "Europe/Berlin",
+		"UTC+3":         "Etc/GMT-3",
+		"+03:00":        "Etc/GMT-3",
+		"gmt-5":         "Etc/GMT+5",
+		"UTC+0":         "UTC",
+	}
+	for in, want := range tests {
+		got, err := ParseTimezone(in)
+		if err != nil {
+			t.Errorf("ParseTimezone(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseTimezone(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "Mars/Olympus", "UTC+15"} {
+		if _, err := ParseTimezone(in); err == nil {
+			t.Errorf("expected error for %q", in)
+		}
+	}
+}
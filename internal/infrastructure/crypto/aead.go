@@ -0,0 +1,45 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+)
+
+// aeadEncryptor implements Encryptor on top of any AEAD cipher. The random
+// nonce is prepended to the ciphertext.
+type aeadEncryptor struct {
+	aead cipher.AEAD
+}
+
+func (e *aeadEncryptor) Encrypt(plainText, associatedData []byte) ([]byte, error) {
+	nonceSize := e.aead.NonceSize()
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, ErrEncryptionFail
+	}
+
+	// Prepend nonce to ciphertext so caller can decrypt
+	cipherText := e.aead.Seal(nil, nonce, plainText, associatedData)
+	out := make([]byte, 0, nonceSize+len(cipherText))
+	out = append(out, nonce...)
+	out = append(out, cipherText...)
+	return out, nil
+}
+
+func (e *aeadEncryptor) Decrypt(cipherText, associatedData []byte) ([]byte, error) {
+	nonceSize := e.aead.NonceSize()
+	if len(cipherText) < nonceSize {
+		return nil, ErrDecryptionFail
+	}
+
+	nonce := cipherText[:nonceSize]
+	ct := cipherText[nonceSize:]
+
+	plain, err := e.aead.Open(nil, nonce, ct, associatedData)
+	if err != nil {
+		return nil, ErrDecryptionFail
+	}
+
+	return plain, nil
+}
@@ -18,7 +18,7 @@ func TestAESEncryptor_EncryptDecrypt(t *testing.T) {
 	}
 
 	plain := []byte("super-secret-token-123")
-	ct, err := enc.Encrypt(plain)
+	ct, err := enc.Encrypt(plain, nil)
 	if err != nil {
 		t.Fatalf("Encrypt error: %v", err)
 	}
@@ -27,7 +27,7 @@ func TestAESEncryptor_EncryptDecrypt(t *testing.T) {
 		t.Fatalf("ciphertext should not equal plaintext")
 	}
 
-	pt, err := enc.Decrypt(ct)
+	pt, err := enc.Decrypt(ct, nil)
 	if err != nil {
 		t.Fatalf("Decrypt error: %v", err)
 	}
@@ -52,7 +52,7 @@ func TestAESEncryptor_WrongKeyDecryptFails(t *testing.T) {
 		t.Fatalf("NewAESEncryptor key1: %v", err)
 	}
 
-	ct, err := enc1.Encrypt([]byte("data-to-encrypt"))
+	ct, err := enc1.Encrypt([]byte("data-to-encrypt"), nil)
 	if err != nil {
 		t.Fatalf("Encrypt error: %v", err)
 	}
@@ -62,7 +62,39 @@ func TestAESEncryptor_WrongKeyDecryptFails(t *testing.T) {
 		t.Fatalf("NewAESEncryptor key2: %v", err)
 	}
 
-	if _, err := enc2.Decrypt(ct); err == nil {
+	if _, err := enc2.Decrypt(ct, nil); err == nil {
 		t.Fatalf("expected decrypt to fail with wrong key, but it succeeded")
 	}
 }
+
+func TestAESEncryptor_AssociatedDataMismatchFails(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to read random key: %v", err)
+	}
+
+	enc, err := NewAESEncryptor(key)
+	if err != nil {
+		t.Fatalf("NewAESEncryptor error: %v", err)
+	}
+
+	ct, err := enc.Encrypt([]byte("token"), []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Encrypt error: %v", err)
+	}
+
+	if _, err := enc.Decrypt(ct, []byte("row-2")); err == nil {
+		t.Fatalf("expected decrypt to fail with different associated data")
+	}
+	if _, err := enc.Decrypt(ct, nil); err == nil {
+		t.Fatalf("expected decrypt to fail without associated data")
+	}
+
+	pt, err := enc.Decrypt(ct, []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Decrypt error: %v", err)
+	}
+	if !bytes.Equal(pt, []byte("token")) {
+		t.Fatalf("decrypted mismatch: got %v", pt)
+	}
+}
@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	AlgorithmAESGCM            = "aes_gcm"
+	AlgorithmXChaCha20Poly1305 = "xchacha20_poly1305"
+)
+
+// EncryptorFactory creates an Encryptor for the given key.
+type EncryptorFactory func(key []byte) (Encryptor, error)
+
+var (
+	algorithmsMu sync.RWMutex
+	algorithms   = map[string]EncryptorFactory{
+		AlgorithmAESGCM:            NewAESEncryptor,
+		AlgorithmXChaCha20Poly1305: NewXChaCha20Poly1305Encryptor,
+	}
+)
+
+// RegisterAlgorithm makes an AEAD algorithm available by name.
+func RegisterAlgorithm(name string, factory EncryptorFactory) {
+	algorithmsMu.Lock()
+	defer algorithmsMu.Unlock()
+	algorithms[name] = factory
+}
+
+// NewEncryptor creates an Encryptor for a registered algorithm.
+func NewEncryptor(algorithm string, key []byte) (Encryptor, error) {
+	algorithmsMu.RLock()
+	factory, ok := algorithms[algorithm]
+	algorithmsMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownAlgorithm
+	}
+	return factory(key)
+}
+
+// Algorithms returns the names of all registered algorithms.
+func Algorithms() []string {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewEncryptor_AllAlgorithmsRoundTrip(t *testing.T) {
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm, func(t *testing.T) {
+			enc, err := NewEncryptor(algorithm, randomKey(t))
+			if err != nil {
+				t.Fatalf("NewEncryptor error: %v", err)
+			}
+
+			ct, err := enc.Encrypt([]byte("token"), []byte("row-1"))
+			if err != nil {
+				t.Fatalf("Encrypt error: %v", err)
+			}
+
+			pt, err := enc.Decrypt(ct, []byte("row-1"))
+			if err != nil {
+				t.Fatalf("Decrypt error: %v", err)
+			}
+			if !bytes.Equal(pt, []byte("token")) {
+				t.Fatalf("decrypted mismatch: got %v", pt)
+			}
+		})
+	}
+}
+
+func TestNewEncryptor_UnknownAlgorithm(t *testing.T) {
+	if _, err := NewEncryptor("rot13", randomKey(t)); err != ErrUnknownAlgorithm {
+		t.Fatalf("expected ErrUnknownAlgorithm, got %v", err)
+	}
+}
+
+func TestKeyStore_DecryptsAfterAlgorithmChange(t *testing.T) {
+	key1, key2 := randomKey(t), randomKey(t)
+
+	ks, err := NewKeyStore(AlgorithmAESGCM, 1, map[int][]byte{1: key1}, nil)
+	if err != nil {
+		t.Fatalf("NewKeyStore error: %v", err)
+	}
+	_, algorithm, enc, err := ks.Current()
+	if err != nil || algorithm != AlgorithmAESGCM {
+		t.Fatalf("Current: got %q, %v want %q", algorithm, err, AlgorithmAESGCM)
+	}
+	ct, err := enc.Encrypt([]byte("token"), nil)
+	if err != nil {
+		t.Fatalf("Encrypt error: %v", err)
+	}
+
+	// Switching algorithms takes a new key version bound to the new one.
+	err = ks.Reload(2, map[int][]byte{1: key1, 2: key2}, map[int]string{2: AlgorithmXChaCha20Poly1305})
+	if err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+	if ver, algorithm, _, _ := ks.Current(); ver != 2 || algorithm != AlgorithmXChaCha20Poly1305 {
+		t.Fatalf("Current after reload: got %d %q", ver, algorithm)
+	}
+
+	old, err := ks.Get(AlgorithmAESGCM, 1)
+	if err != nil {
+		t.Fatalf("Get aes error: %v", err)
+	}
+	pt, err := old.Decrypt(ct, nil)
+	if err != nil || !bytes.Equal(pt, []byte("token")) {
+		t.Fatalf("decrypt of aes ciphertext failed: %v", err)
+	}
+
+	if _, err := ks.Get(AlgorithmXChaCha20Poly1305, 1); err != ErrUnknownKeyVersion {
+		t.Fatalf("key v1 must only be used with aes, got %v", err)
+	}
+	if _, err := ks.Get(AlgorithmAESGCM, 2); err != ErrUnknownKeyVersion {
+		t.Fatalf("key v2 must only be used with xchacha, got %v", err)
+	}
+}
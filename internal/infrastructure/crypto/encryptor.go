@@ -1,6 +1,9 @@
 package crypto
 
+// Encryptor seals data with an AEAD cipher. associatedData is authenticated
+// but not encrypted: decryption fails unless the same value is supplied,
+// which binds a ciphertext to its context (e.g. the database row it belongs to).
 type Encryptor interface {
-	Encrypt(plainText []byte) ([]byte, error)
-	Decrypt(cipherText []byte) ([]byte, error)
-}
\ No newline at end of file
+	Encrypt(plainText, associatedData []byte) ([]byte, error)
+	Decrypt(cipherText, associatedData []byte) ([]byte, error)
+}
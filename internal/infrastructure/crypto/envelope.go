@@ -0,0 +1,146 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"sync"
+	"time"
+)
+
+const dataKeySize = 32
+
+// Sealed is the result of envelope encryption: the ciphertext, its data key
+// wrapped by a master key, the ID of that master key and the algorithm the
+// data key was used with.
+type Sealed struct {
+	CipherText []byte
+	WrappedKey []byte
+	KeyID      string
+	Algorithm  string
+}
+
+// Envelope encrypts each value with a fresh data key and wraps the data key
+// with a KeyProvider, so the stored data is useless without the provider.
+// Unwrapped data keys are cached for a while so reading the same rows again
+// does not call the provider each time.
+type Envelope struct {
+	provider  KeyProvider
+	algorithm string
+	keyTTL    time.Duration
+	now       func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]cachedKey
+}
+
+type cachedKey struct {
+	dataKey   []byte
+	expiresAt time.Time
+}
+
+// NewEnvelope creates an envelope that encrypts data with algorithm and keeps
+// unwrapped data keys for keyTTL. A keyTTL of 0 disables the cache.
+func NewEnvelope(provider KeyProvider, algorithm string, keyTTL time.Duration) *Envelope {
+	return &Envelope{
+		provider:  provider,
+		algorithm: algorithm,
+		keyTTL:    keyTTL,
+		now:       time.Now,
+		keys:      make(map[string]cachedKey),
+	}
+}
+
+// KeyID returns the master key ID used for new seals.
+func (e *Envelope) KeyID() string {
+	return e.provider.KeyID()
+}
+
+// Algorithm returns the algorithm used for new seals.
+func (e *Envelope) Algorithm() string {
+	return e.algorithm
+}
+
+func (e *Envelope) Seal(ctx context.Context, plainText, associatedData []byte) (*Sealed, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, ErrEncryptionFail
+	}
+
+	enc, err := NewEncryptor(e.algorithm, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText, err := enc.Encrypt(plainText, associatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := e.provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	e.cacheKey(e.provider.KeyID(), wrapped, dataKey)
+
+	return &Sealed{
+		CipherText: cipherText,
+		WrappedKey: wrapped,
+		KeyID:      e.provider.KeyID(),
+		Algorithm:  e.algorithm,
+	}, nil
+}
+
+func (e *Envelope) Open(ctx context.Context, sealed *Sealed, associatedData []byte) ([]byte, error) {
+	dataKey, ok := e.cachedKey(sealed.KeyID, sealed.WrappedKey)
+	if !ok {
+		var err error
+		dataKey, err = e.provider.UnwrapKey(ctx, sealed.KeyID, sealed.WrappedKey)
+		if err != nil {
+			return nil, err
+		}
+		e.cacheKey(sealed.KeyID, sealed.WrappedKey, dataKey)
+	}
+
+	enc, err := NewEncryptor(sealed.Algorithm, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return enc.Decrypt(sealed.CipherText, associatedData)
+}
+
+func keyCacheID(keyID string, wrappedKey []byte) string {
+	return keyID + "\x00" + string(wrappedKey)
+}
+
+func (e *Envelope) cachedKey(keyID string, wrappedKey []byte) ([]byte, bool) {
+	if e.keyTTL <= 0 {
+		return nil, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cached, ok := e.keys[keyCacheID(keyID, wrappedKey)]
+	if !ok || !e.now().Before(cached.expiresAt) {
+		return nil, false
+	}
+	return cached.dataKey, true
+}
+
+// cacheKey remembers an unwrapped data key and drops expired ones.
+func (e *Envelope) cacheKey(keyID string, wrappedKey, dataKey []byte) {
+	if e.keyTTL <= 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	for id, cached := range e.keys {
+		if !now.Before(cached.expiresAt) {
+			delete(e.keys, id)
+		}
+	}
+	e.keys[keyCacheID(keyID, wrappedKey)] = cachedKey{dataKey: dataKey, expiresAt: now.Add(e.keyTTL)}
+}
@@ -0,0 +1,175 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeKMS implements the HTTP KMS protocol with one in-memory key per key ID.
+func fakeKMS(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	keys := map[string]Encryptor{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/keys/"), "/")
+		if len(parts) != 2 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		keyID, action := parts[0], parts[1]
+
+		kek, ok := keys[keyID]
+		if !ok {
+			kek, _ = NewAESEncryptor(randomKey(t))
+			keys[keyID] = kek
+		}
+
+		switch action {
+		case "wrap":
+			var req wrapRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			ct, _ := kek.Encrypt(req.Plaintext, nil)
+			_ = json.NewEncoder(w).Encode(wrapResponse{Ciphertext: ct})
+		case "unwrap":
+			var req unwrapRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			pt, err := kek.Decrypt(req.Ciphertext, nil)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(unwrapResponse{Plaintext: pt})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestEnvelope_LocalProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.key")
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(randomKey(t))+"\n"), 0o600); err != nil {
+		t.Fatalf("write master key: %v", err)
+	}
+
+	provider, err := NewLocalKeyProvider(path)
+	if err != nil {
+		t.Fatalf("NewLocalKeyProvider error: %v", err)
+	}
+	env := NewEnvelope(provider, AlgorithmAESGCM, 0)
+
+	sealed, err := env.Seal(context.Background(), []byte("token"), []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Seal error: %v", err)
+	}
+	if sealed.KeyID != provider.KeyID() {
+		t.Fatalf("key id: got %q want %q", sealed.KeyID, provider.KeyID())
+	}
+
+	pt, err := env.Open(context.Background(), sealed, []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if !bytes.Equal(pt, []byte("token")) {
+		t.Fatalf("opened mismatch: got %v", pt)
+	}
+
+	other, err := newLocalKeyProvider(randomKey(t))
+	if err != nil {
+		t.Fatalf("newLocalKeyProvider error: %v", err)
+	}
+	if _, err := NewEnvelope(other, AlgorithmAESGCM, 0).Open(context.Background(), sealed, []byte("row-1")); err == nil {
+		t.Fatalf("expected open with a different master key to fail")
+	}
+}
+
+func TestEnvelope_HTTPProvider(t *testing.T) {
+	srv := fakeKMS(t, "secret")
+	defer srv.Close()
+
+	env := NewEnvelope(NewHTTPKeyProvider(srv.URL, "tokens", "secret", 5*time.Second), AlgorithmXChaCha20Poly1305, 0)
+
+	sealed, err := env.Seal(context.Background(), []byte("token"), []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Seal error: %v", err)
+	}
+
+	pt, err := env.Open(context.Background(), sealed, []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if !bytes.Equal(pt, []byte("token")) {
+		t.Fatalf("opened mismatch: got %v", pt)
+	}
+
+	if _, err := env.Open(context.Background(), sealed, []byte("row-2")); err == nil {
+		t.Fatalf("expected open with different associated data to fail")
+	}
+
+	unauthorized := NewEnvelope(NewHTTPKeyProvider(srv.URL, "tokens", "wrong", 5*time.Second), AlgorithmXChaCha20Poly1305, 0)
+	if _, err := unauthorized.Open(context.Background(), sealed, []byte("row-1")); err == nil {
+		t.Fatalf("expected open with a rejected token to fail")
+	}
+}
+
+// countingProvider counts the data keys a provider unwraps.
+type countingProvider struct {
+	KeyProvider
+	unwraps int
+}
+
+func (p *countingProvider) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	p.unwraps++
+	return p.KeyProvider.UnwrapKey(ctx, keyID, wrappedKey)
+}
+
+func TestEnvelope_KeyCache(t *testing.T) {
+	local, err := newLocalKeyProvider(randomKey(t))
+	if err != nil {
+		t.Fatalf("newLocalKeyProvider error: %v", err)
+	}
+	provider := &countingProvider{KeyProvider: local}
+	writer := NewEnvelope(local, AlgorithmAESGCM, 0)
+	sealed, err := writer.Seal(context.Background(), []byte("token"), []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Seal error: %v", err)
+	}
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	env := NewEnvelope(provider, AlgorithmAESGCM, time.Minute)
+	env.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := env.Open(context.Background(), sealed, []byte("row-1")); err != nil {
+			t.Fatalf("Open error: %v", err)
+		}
+	}
+	if provider.unwraps != 1 {
+		t.Fatalf("unwraps within the ttl: got %d want 1", provider.unwraps)
+	}
+	// a cached key still has to match the associated data
+	if _, err := env.Open(context.Background(), sealed, []byte("row-2")); err == nil {
+		t.Fatalf("expected open with different associated data to fail")
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := env.Open(context.Background(), sealed, []byte("row-1")); err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if provider.unwraps != 2 {
+		t.Fatalf("unwraps after the ttl: got %d want 2", provider.unwraps)
+	}
+}
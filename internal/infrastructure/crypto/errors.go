@@ -3,8 +3,12 @@ package crypto
 import "errors"
 
 var (
-	ErrEncryptionFail = errors.New("encryption failed")
-	ErrInvalidKeySize = errors.New("invalid key size, must be 16, 24, or 32 bytes")
-	ErrDecryptionFail = errors.New("decryption failed")
+	ErrEncryptionFail    = errors.New("encryption failed")
+	ErrInvalidKeySize    = errors.New("invalid key size, must be 16, 24, or 32 bytes")
+	ErrDecryptionFail    = errors.New("decryption failed")
 	ErrUnknownKeyVersion = errors.New("unknown key version")
+	ErrUnknownMasterKey  = errors.New("unknown master key")
+	ErrUnknownAlgorithm  = errors.New("unknown encryption algorithm")
+	ErrKeyWrapFail       = errors.New("data key wrap failed")
+	ErrKeyUnwrapFail     = errors.New("data key unwrap failed")
 )
@@ -0,0 +1,104 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpKeyProvider talks to a KMS-style HTTP service:
+//
+//	POST {endpoint}/v1/keys/{key_id}/wrap   {"plaintext": "<base64>"}  -> {"ciphertext": "<base64>"}
+//	POST {endpoint}/v1/keys/{key_id}/unwrap {"ciphertext": "<base64>"} -> {"plaintext": "<base64>"}
+//
+// Binary values are base64 encoded by encoding/json.
+type httpKeyProvider struct {
+	endpoint string
+	keyID    string
+	token    string
+	client   *http.Client
+}
+
+type wrapRequest struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+type wrapResponse struct {
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type unwrapRequest struct {
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type unwrapResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+func NewHTTPKeyProvider(endpoint, keyID, token string, timeout time.Duration) KeyProvider {
+	return &httpKeyProvider{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		keyID:    keyID,
+		token:    token,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *httpKeyProvider) KeyID() string {
+	return p.keyID
+}
+
+func (p *httpKeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var resp wrapResponse
+	if err := p.call(ctx, p.keyID, "wrap", wrapRequest{Plaintext: dataKey}, &resp); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeyWrapFail, err)
+	}
+	if len(resp.Ciphertext) == 0 {
+		return nil, fmt.Errorf("%w: empty ciphertext", ErrKeyWrapFail)
+	}
+	return resp.Ciphertext, nil
+}
+
+func (p *httpKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	var resp unwrapResponse
+	if err := p.call(ctx, keyID, "unwrap", unwrapRequest{Ciphertext: wrappedKey}, &resp); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeyUnwrapFail, err)
+	}
+	if len(resp.Plaintext) == 0 {
+		return nil, fmt.Errorf("%w: empty plaintext", ErrKeyUnwrapFail)
+	}
+	return resp.Plaintext, nil
+}
+
+func (p *httpKeyProvider) call(ctx context.Context, keyID, action string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/v1/keys/%s/%s", p.endpoint, url.PathEscape(keyID), action)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kms responded with status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,13 @@
+package crypto
+
+import "context"
+
+// KeyProvider wraps data keys with a master (key-encryption) key that never
+// leaves the provider, e.g. a local key file or a remote KMS.
+type KeyProvider interface {
+	// KeyID identifies the master key used for new wraps.
+	KeyID() string
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+	// UnwrapKey unwraps a data key wrapped by the master key keyID.
+	UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
@@ -1,23 +1,96 @@
 package crypto
 
-type KeyStore struct{
-	Encryptors map[int]Encryptor
-	Current int
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// boundKey is a key version together with the only algorithm it is used with.
+type boundKey struct {
+	algorithm string
+	encryptor Encryptor
+}
+
+// KeyStore holds an encryptor per key version. Each version is bound to one
+// algorithm: the one recorded with the key, or the store algorithm for keys
+// without one. Switching algorithms therefore takes a new key version. It is
+// safe for concurrent use and can be reloaded in place when keys are rotated.
+type KeyStore struct {
+	mu        sync.RWMutex
+	algorithm string
+	keys      map[int]boundKey
+	current   int
+}
+
+// NewKeyStore creates a store whose keys use the algorithm recorded in
+// algorithms for their version, or algorithm when none is recorded.
+func NewKeyStore(algorithm string, current int, keys map[int][]byte, algorithms map[int]string) (*KeyStore, error) {
+	if _, err := NewEncryptor(algorithm, make([]byte, dataKeySize)); errors.Is(err, ErrUnknownAlgorithm) {
+		return nil, err
+	}
+
+	ks := &KeyStore{algorithm: algorithm}
+	if err := ks.Reload(current, keys, algorithms); err != nil {
+		return nil, err
+	}
+	return ks, nil
 }
 
 func NewAESKeyStore(current int, keys map[int][]byte) (*KeyStore, error) {
-	ks := &KeyStore{
-		Encryptors: make(map[int]Encryptor),
-		Current: current,
+	return NewKeyStore(AlgorithmAESGCM, current, keys, nil)
+}
+
+// Current returns the version, algorithm and encryptor used for new
+// ciphertexts.
+func (ks *KeyStore) Current() (int, string, Encryptor, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[ks.current]
+	if !ok {
+		return 0, "", nil, ErrUnknownKeyVersion
+	}
+	return ks.current, key.algorithm, key.encryptor, nil
+}
+
+// Get returns the encryptor for the given key version. It fails when the
+// version is bound to another algorithm.
+func (ks *KeyStore) Get(algorithm string, version int) (Encryptor, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[version]
+	if !ok || key.algorithm != algorithm {
+		return nil, ErrUnknownKeyVersion
 	}
+	return key.encryptor, nil
+}
 
+// Reload atomically replaces all keys. The store is left unchanged if any
+// key is invalid for its algorithm or the current version is missing.
+func (ks *KeyStore) Reload(current int, keys map[int][]byte, algorithms map[int]string) error {
+	bound := make(map[int]boundKey, len(keys))
 	for ver, key := range keys {
-		enc, err := NewAESEncryptor(key)
+		algorithm := algorithms[ver]
+		if algorithm == "" {
+			algorithm = ks.algorithm
+		}
+		enc, err := NewEncryptor(algorithm, key)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("key version %d: %w", ver, err)
 		}
-		ks.Encryptors[ver] = enc
+		bound[ver] = boundKey{algorithm: algorithm, encryptor: enc}
 	}
 
-	return ks, nil
-}
\ No newline at end of file
+	// An empty store is allowed when tokens use envelope encryption only.
+	if _, ok := bound[current]; !ok && len(bound) > 0 {
+		return ErrUnknownKeyVersion
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = bound
+	ks.current = current
+	return nil
+}
@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func randomKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to read random key: %v", err)
+	}
+	return key
+}
+
+func TestKeyStore_ReloadKeepsOldVersions(t *testing.T) {
+	key1, key2 := randomKey(t), randomKey(t)
+
+	ks, err := NewAESKeyStore(1, map[int][]byte{1: key1})
+	if err != nil {
+		t.Fatalf("NewAESKeyStore error: %v", err)
+	}
+
+	_, _, enc1, err := ks.Current()
+	if err != nil {
+		t.Fatalf("Current error: %v", err)
+	}
+	ct, err := enc1.Encrypt([]byte("token"), nil)
+	if err != nil {
+		t.Fatalf("Encrypt error: %v", err)
+	}
+
+	if err := ks.Reload(2, map[int][]byte{1: key1, 2: key2}, nil); err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	ver, _, _, err := ks.Current()
+	if err != nil || ver != 2 {
+		t.Fatalf("Current after reload: got %d, %v want 2", ver, err)
+	}
+
+	old, err := ks.Get(AlgorithmAESGCM, 1)
+	if err != nil {
+		t.Fatalf("Get(1) error: %v", err)
+	}
+	pt, err := old.Decrypt(ct, nil)
+	if err != nil || !bytes.Equal(pt, []byte("token")) {
+		t.Fatalf("decrypt with old version failed: %v", err)
+	}
+}
+
+func TestKeyStore_ReloadRejectsMissingCurrent(t *testing.T) {
+	key1 := randomKey(t)
+
+	ks, err := NewAESKeyStore(1, map[int][]byte{1: key1})
+	if err != nil {
+		t.Fatalf("NewAESKeyStore error: %v", err)
+	}
+
+	if err := ks.Reload(3, map[int][]byte{1: key1}, nil); err == nil {
+		t.Fatalf("expected reload with unknown current version to fail")
+	}
+
+	if ver, _, _, err := ks.Current(); err != nil || ver != 1 {
+		t.Fatalf("store should be unchanged after failed reload: got %d, %v", ver, err)
+	}
+}
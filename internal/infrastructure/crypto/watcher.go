@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay groups bursts of file events (a secret update touches
+// several files) into a single reload.
+const reloadDelay = 500 * time.Millisecond
+
+// KeyLoader returns all key versions, the algorithms recorded for them and
+// the current version.
+type KeyLoader func() (map[int][]byte, map[int]string, int, error)
+
+// KeyWatcher reloads a KeyStore when files in the watched directories change,
+// so keys can be rotated without a redeploy.
+type KeyWatcher struct {
+	keyStore *KeyStore
+	load     KeyLoader
+	watcher  *fsnotify.Watcher
+	logger   logger.Logger
+}
+
+func NewKeyWatcher(keyStore *KeyStore, load KeyLoader, dirs []string, logger logger.Logger) (*KeyWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	return &KeyWatcher{
+		keyStore: keyStore,
+		load:     load,
+		watcher:  watcher,
+		logger:   logger,
+	}, nil
+}
+
+// Run processes file events until ctx is cancelled.
+func (w *KeyWatcher) Run(ctx context.Context) {
+	defer w.watcher.Close()
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error("key watcher error", zap.Error(err))
+		case <-timer.C:
+			w.reload()
+		}
+	}
+}
+
+func (w *KeyWatcher) reload() {
+	keys, algorithms, current, err := w.load()
+	if err != nil {
+		w.logger.Error("failed to reload encryption keys", zap.Error(err))
+		return
+	}
+
+	if err := w.keyStore.Reload(current, keys, algorithms); err != nil {
+		w.logger.Error("failed to apply reloaded encryption keys", zap.Error(err))
+		return
+	}
+
+	w.logger.Info("encryption keys reloaded",
+		zap.Int("current_version", current),
+		zap.Int("versions", len(keys)))
+}
@@ -0,0 +1,19 @@
+package crypto
+
+import "golang.org/x/crypto/chacha20poly1305"
+
+// NewXChaCha20Poly1305Encryptor creates an encryptor with a 256-bit key and
+// 192-bit random nonces, which are safe to generate randomly for any number
+// of messages.
+func NewXChaCha20Poly1305Encryptor(key []byte) (Encryptor, error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, ErrInvalidKeySize
+	}
+
+	return &aeadEncryptor{aead: aead}, nil
+}
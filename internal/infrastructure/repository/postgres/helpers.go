@@ -1,13 +1,24 @@
 package postgres
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// notFound maps pgx.ErrNoRows to app_errors.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return app_errors.ErrNotFound
+	}
+	return err
+}
+
 // UUID conversion helpers
 
 // uuidToPgtype converts uuid.UUID to pgtype.UUID.
@@ -95,3 +106,28 @@ func pgtypeToString(s *string) string {
 	}
 	return *s
 }
+
+// uuidPtrToPgtype converts *uuid.UUID to pgtype.UUID, nil maps to SQL NULL.
+func uuidPtrToPgtype(id *uuid.UUID) pgtype.UUID {
+	if id == nil {
+		return pgtype.UUID{Valid: false}
+	}
+	return uuidToPgtype(*id)
+}
+
+// pgtypeToUUIDPtr converts pgtype.UUID to *uuid.UUID, SQL NULL maps to nil.
+func pgtypeToUUIDPtr(pgID pgtype.UUID) *uuid.UUID {
+	if !pgID.Valid {
+		return nil
+	}
+	id := uuid.UUID(pgID.Bytes)
+	return &id
+}
+
+// stringToPgtype converts string to *string, empty maps to SQL NULL.
+func stringToPgtype(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
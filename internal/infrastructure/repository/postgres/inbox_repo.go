@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/inbox"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresInboxRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewPostgresInboxRepository(db *pgxpool.Pool) inbox.Repository {
+	return &PostgresInboxRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresInboxRepository) SaveThread(ctx context.Context, botID, userID uuid.UUID) (uuid.UUID, error) {
+	id, err := r.queries.SaveInboxThread(ctx, sqlc.SaveInboxThreadParams{
+		TelegramBotID: uuidToPgtype(botID),
+		UserID:        uuidToPgtype(userID),
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to save inbox thread: %w", err)
+	}
+	return pgtypeToUUID(id)
+}
+
+func (r *PostgresInboxRepository) AddMessage(ctx context.Context, m *inbox.Message) error {
+	err := r.queries.CreateInboxMessage(ctx, sqlc.CreateInboxMessageParams{
+		ThreadID:    uuidToPgtype(m.ThreadID),
+		Direction:   m.Direction,
+		Text:        m.Text,
+		AdminUserID: uuidPtrToPgtype(m.AdminUserID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create inbox message: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresInboxRepository) AddRelay(ctx context.Context, relay *inbox.Relay) error {
+	err := r.queries.CreateInboxRelay(ctx, sqlc.CreateInboxRelayParams{
+		RelayBotID: relay.RelayBotID,
+		ChatID:     relay.ChatID,
+		MessageID:  int64(relay.MessageID),
+		ThreadID:   uuidToPgtype(relay.ThreadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create inbox relay: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresInboxRepository) GetThreadByRelay(ctx context.Context, relayBotID, chatID int64, messageID int) (*inbox.Thread, error) {
+	row, err := r.queries.GetInboxThreadByRelay(ctx, sqlc.GetInboxThreadByRelayParams{
+		RelayBotID: relayBotID,
+		ChatID:     chatID,
+		MessageID:  int64(messageID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox thread: %w", notFound(err))
+	}
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	botID, err := pgtypeToUUID(row.TelegramBotID)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := pgtypeToUUID(row.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &inbox.Thread{
+		ID:             id,
+		TelegramBotID:  botID,
+		UserID:         userID,
+		BotID:          pgtypeToInt64(row.BotID),
+		UserTelegramID: pgtypeToInt64(row.TelegramID),
+	}, nil
+}
+
+func (r *PostgresInboxRepository) ListRecipients(ctx context.Context, botID uuid.UUID) ([]int64, error) {
+	rows, err := r.queries.ListInboxRecipients(ctx, uuidToPgtype(botID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inbox recipients: %w", err)
+	}
+	ids := make([]int64, 0, len(rows))
+	for _, id := range rows {
+		ids = append(ids, pgtypeToInt64(id))
+	}
+	return ids, nil
+}
+
+func (r *PostgresInboxRepository) AdminBotID(ctx context.Context) (int64, error) {
+	id, err := r.queries.GetAdminTelegramBotID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get admin telegram bot: %w", notFound(err))
+	}
+	return pgtypeToInt64(id), nil
+}
+
+func (r *PostgresInboxRepository) SupportChat(ctx context.Context, botID uuid.UUID) (int64, error) {
+	chatID, err := r.queries.GetTelegramBotSupportChat(ctx, uuidToPgtype(botID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get support chat: %w", notFound(err))
+	}
+	return pgtypeToInt64(chatID), nil
+}
+
+func (r *PostgresInboxRepository) SetSupportChat(ctx context.Context, botID uuid.UUID, chatID int64) error {
+	var supportChatID *int64
+	if chatID != 0 {
+		supportChatID = &chatID
+	}
+	err := r.queries.SetTelegramBotSupportChat(ctx, sqlc.SetTelegramBotSupportChatParams{
+		SupportChatID: supportChatID,
+		ID:            uuidToPgtype(botID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set support chat: %w", err)
+	}
+	return nil
+}
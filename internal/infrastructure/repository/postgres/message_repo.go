@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresMessageRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewPostgresMessageRepository(db *pgxpool.Pool) message.Repository {
+	return &PostgresMessageRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresMessageRepository) GetByID(ctx context.Context, id uuid.UUID) (*message.Message, error) {
+	row, err := r.queries.GetMessageByID(ctx, uuidToPgtype(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message by id: %w", notFound(err))
+	}
+
+	buttons, err := r.queries.ListMessageButtons(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message buttons: %w", err)
+	}
+
+	msg := &message.Message{
+		ID:        id,
+		Content:   pgtypeToString(row.Content),
+		ParseMode: pgtypeToString(row.ParseMode),
+		Buttons:   make([]message.Button, 0, len(buttons)),
+	}
+	for _, b := range buttons {
+		button, err := buttonFromRow(sqlc.GetMessageButtonByIDRow(b))
+		if err != nil {
+			return nil, err
+		}
+		msg.Buttons = append(msg.Buttons, *button)
+	}
+	return msg, nil
+}
+
+func (r *PostgresMessageRepository) GetButton(ctx context.Context, id uuid.UUID) (*message.Button, error) {
+	row, err := r.queries.GetMessageButtonByID(ctx, uuidToPgtype(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message button by id: %w", notFound(err))
+	}
+	return buttonFromRow(row)
+}
+
+func (r *PostgresMessageRepository) Create(ctx context.Context, msg *message.Message) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	rowID, err := q.CreateMessage(ctx, sqlc.CreateMessageParams{
+		Content:   &msg.Content,
+		ParseMode: stringToPgtype(msg.ParseMode),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+	id, err := pgtypeToUUID(rowID)
+	if err != nil {
+		return fmt.Errorf("invalid message ID: %w", err)
+	}
+
+	for i := range msg.Buttons {
+		b := &msg.Buttons[i]
+		buttonID, err := q.CreateMessageButton(ctx, sqlc.CreateMessageButtonParams{
+			MessageID:    rowID,
+			Text:         b.Text,
+			Url:          stringToPgtype(b.URL),
+			SetAttribute: stringToPgtype(b.SetAttribute),
+			SetValue:     stringToPgtype(b.SetValue),
+			AddTag:       stringToPgtype(b.AddTag),
+			TrackClicks:  b.TrackClicks,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create message button: %w", err)
+		}
+		if b.ID, err = pgtypeToUUID(buttonID); err != nil {
+			return fmt.Errorf("invalid message button ID: %w", err)
+		}
+		b.MessageID = id
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit message: %w", err)
+	}
+	msg.ID = id
+	return nil
+}
+
+func (r *PostgresMessageRepository) Update(ctx context.Context, msg *message.Message) error {
+	n, err := r.queries.UpdateMessage(ctx, sqlc.UpdateMessageParams{
+		ID:        uuidToPgtype(msg.ID),
+		Content:   &msg.Content,
+		ParseMode: stringToPgtype(msg.ParseMode),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update message: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("failed to update message: %w", app_errors.ErrNotFound)
+	}
+	return nil
+}
+
+func buttonFromRow(row sqlc.GetMessageButtonByIDRow) (*message.Button, error) {
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message button ID: %w", err)
+	}
+	messageID, err := pgtypeToUUID(row.MessageID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message button message ID: %w", err)
+	}
+	return &message.Button{
+		ID:           id,
+		MessageID:    messageID,
+		Text:         row.Text,
+		URL:          pgtypeToString(row.Url),
+		SetAttribute: pgtypeToString(row.SetAttribute),
+		SetValue:     pgtypeToString(row.SetValue),
+		AddTag:       pgtypeToString(row.AddTag),
+		TrackClicks:  row.TrackClicks,
+	}, nil
+}
+
+func (r *PostgresMessageRepository) IsPublished(ctx context.Context, id uuid.UUID) (bool, error) {
+	n, err := r.queries.CountPublishedMessageSteps(ctx, uuidToPgtype(id))
+	if err != nil {
+		return false, fmt.Errorf("failed to count published message steps: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PostgresMessageRepository) CreateClick(ctx context.Context, click *message.Click) error {
+	err := r.queries.CreateMessageButtonClick(ctx, sqlc.CreateMessageButtonClickParams{
+		ButtonID:   uuidToPgtype(click.ButtonID),
+		UserID:     uuidToPgtype(click.UserID),
+		ClickedAt:  timeToPgtype(click.ClickedAt),
+		StepID:     uuidPtrToPgtype(click.StepID),
+		DeliveryID: uuidPtrToPgtype(click.DeliveryID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message button click: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresMessageRepository) ListMedia(ctx context.Context, messageID uuid.UUID) ([]message.Media, error) {
+	rows, err := r.queries.ListMessageMedia(ctx, uuidToPgtype(messageID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message media: %w", err)
+	}
+	media := make([]message.Media, 0, len(rows))
+	for _, row := range rows {
+		id, err := pgtypeToUUID(row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message media ID: %w", err)
+		}
+		media = append(media, message.Media{
+			ID:         id,
+			MessageID:  messageID,
+			StorageKey: row.StorageKey,
+			Ext:        row.Ext,
+			Size:       row.Size,
+			MimeType:   row.MimeType,
+			Kind:       row.Kind,
+		})
+	}
+	return media, nil
+}
+
+func (r *PostgresMessageRepository) CreateMedia(ctx context.Context, media *message.Media) error {
+	err := r.queries.CreateMessageMedia(ctx, sqlc.CreateMessageMediaParams{
+		MessageID:  uuidToPgtype(media.MessageID),
+		StorageKey: media.StorageKey,
+		Ext:        media.Ext,
+		Size:       media.Size,
+		MimeType:   media.MimeType,
+		Kind:       media.Kind,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message media: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/group"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresPrivateGroupRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewPostgresPrivateGroupRepository(db *pgxpool.Pool) group.Repository {
+	return &PostgresPrivateGroupRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresPrivateGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*group.Group, error) {
+	row, err := r.queries.GetPrivateGroupByID(ctx, uuidToPgtype(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private group by id: %w", notFound(err))
+	}
+	return groupFromRow(row)
+}
+
+func (r *PostgresPrivateGroupRepository) GetByChatID(ctx context.Context, telegramBotID uuid.UUID, chatID int64) (*group.Group, error) {
+	row, err := r.queries.GetPrivateGroupByChatID(ctx, sqlc.GetPrivateGroupByChatIDParams{
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		ChatID:        chatID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private group by chat id: %w", notFound(err))
+	}
+	return groupFromRow(sqlc.GetPrivateGroupByIDRow(row))
+}
+
+func (r *PostgresPrivateGroupRepository) Save(ctx context.Context, g *group.Group) error {
+	rowID, err := r.queries.SavePrivateGroup(ctx, sqlc.SavePrivateGroupParams{
+		TelegramBotID: uuidToPgtype(g.TelegramBotID),
+		ChatID:        g.ChatID,
+		Title:         stringToPgtype(g.Title),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save private group: %w", err)
+	}
+	id, err := pgtypeToUUID(rowID)
+	if err != nil {
+		return fmt.Errorf("invalid private group ID: %w", err)
+	}
+	g.ID = id
+	return nil
+}
+
+func (r *PostgresPrivateGroupRepository) SetPolicy(ctx context.Context, id uuid.UUID, accessTag string, declineUnqualified bool) error {
+	err := r.queries.SetPrivateGroupPolicy(ctx, sqlc.SetPrivateGroupPolicyParams{
+		AccessTag:          stringToPgtype(accessTag),
+		DeclineUnqualified: declineUnqualified,
+		ID:                 uuidToPgtype(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set private group policy: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPrivateGroupRepository) ReachedAccessStep(ctx context.Context, groupID, userID uuid.UUID) (bool, error) {
+	n, err := r.queries.CountGroupAccessDeliveries(ctx, sqlc.CountGroupAccessDeliveriesParams{
+		UserID:         uuidToPgtype(userID),
+		PrivateGroupID: uuidToPgtype(groupID),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to count group access deliveries: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PostgresPrivateGroupRepository) CreateInvite(ctx context.Context, invite *group.Invite) error {
+	rowID, err := r.queries.CreateGroupInvite(ctx, sqlc.CreateGroupInviteParams{
+		PrivateGroupID:   uuidToPgtype(invite.GroupID),
+		UserID:           uuidToPgtype(invite.UserID),
+		ScriptProgressID: uuidPtrToPgtype(invite.ProgressID),
+		InviteLink:       invite.Link,
+		ExpiresAt:        timeToPgtype(invite.ExpiresAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create group invite: %w", err)
+	}
+	id, err := pgtypeToUUID(rowID)
+	if err != nil {
+		return fmt.Errorf("invalid group invite ID: %w", err)
+	}
+	invite.ID = id
+	return nil
+}
+
+func (r *PostgresPrivateGroupRepository) GetPendingInvite(ctx context.Context, groupID, userID uuid.UUID, now time.Time) (*group.Invite, error) {
+	row, err := r.queries.GetPendingGroupInvite(ctx, sqlc.GetPendingGroupInviteParams{
+		PrivateGroupID: uuidToPgtype(groupID),
+		UserID:         uuidToPgtype(userID),
+		Now:            timeToPgtype(now),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending group invite: %w", notFound(err))
+	}
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group invite ID: %w", err)
+	}
+	return &group.Invite{
+		ID:        id,
+		GroupID:   groupID,
+		UserID:    userID,
+		Link:      row.InviteLink,
+		ExpiresAt: pgtypeToTime(row.ExpiresAt),
+	}, nil
+}
+
+func (r *PostgresPrivateGroupRepository) JoinInvites(ctx context.Context, groupID, userID uuid.UUID, joinedAt time.Time) (int64, error) {
+	n, err := r.queries.JoinGroupInvites(ctx, sqlc.JoinGroupInvitesParams{
+		JoinedAt:       timeToPgtype(joinedAt),
+		PrivateGroupID: uuidToPgtype(groupID),
+		UserID:         uuidToPgtype(userID),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark group invites joined: %w", err)
+	}
+	return n, nil
+}
+
+func (r *PostgresPrivateGroupRepository) ListExpiredInvites(ctx context.Context, now time.Time, limit int) ([]*group.ExpiredInvite, error) {
+	rows, err := r.queries.ListExpiredGroupInvites(ctx, sqlc.ListExpiredGroupInvitesParams{
+		Now:      timeToPgtype(now),
+		LimitVal: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired group invites: %w", err)
+	}
+	invites := make([]*group.ExpiredInvite, 0, len(rows))
+	for _, row := range rows {
+		id, err := pgtypeToUUID(row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group invite ID: %w", err)
+		}
+		invites = append(invites, &group.ExpiredInvite{
+			ID:     id,
+			Link:   row.InviteLink,
+			ChatID: row.ChatID,
+			BotID:  pgtypeToInt64(row.BotID),
+		})
+	}
+	return invites, nil
+}
+
+func (r *PostgresPrivateGroupRepository) RevokeInvite(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	err := r.queries.RevokeGroupInvite(ctx, sqlc.RevokeGroupInviteParams{
+		ID:        uuidToPgtype(id),
+		RevokedAt: timeToPgtype(revokedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke group invite: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPrivateGroupRepository) SaveJoinRequest(ctx context.Context, groupID, userID uuid.UUID, requestedAt time.Time) error {
+	err := r.queries.SaveGroupJoinRequest(ctx, sqlc.SaveGroupJoinRequestParams{
+		PrivateGroupID: uuidToPgtype(groupID),
+		UserID:         uuidToPgtype(userID),
+		RequestedAt:    timeToPgtype(requestedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save group join request: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPrivateGroupRepository) DecideJoinRequest(ctx context.Context, groupID, userID uuid.UUID, status string, decidedAt time.Time) error {
+	err := r.queries.DecideGroupJoinRequest(ctx, sqlc.DecideGroupJoinRequestParams{
+		Status:         status,
+		DecidedAt:      timeToPgtype(decidedAt),
+		PrivateGroupID: uuidToPgtype(groupID),
+		UserID:         uuidToPgtype(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decide group join request: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPrivateGroupRepository) ListPendingJoinRequests(ctx context.Context, after *group.JoinRequest, limit int) ([]*group.JoinRequest, error) {
+	// the first page starts after the zero cursor, which sorts before any row
+	params := sqlc.ListPendingGroupJoinRequestsParams{
+		AfterRequestedAt: pgtype.Timestamp{Valid: true},
+		AfterID:          uuidToPgtype(uuid.Nil),
+		LimitVal:         int32(limit),
+	}
+	if after != nil {
+		params.AfterRequestedAt = pgtype.Timestamp{Time: after.RequestedAt, Valid: true}
+		params.AfterID = uuidToPgtype(after.ID)
+	}
+	rows, err := r.queries.ListPendingGroupJoinRequests(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending group join requests: %w", err)
+	}
+	requests := make([]*group.JoinRequest, 0, len(rows))
+	for _, row := range rows {
+		requests = append(requests, &group.JoinRequest{
+			ID:          uuid.UUID(row.ID.Bytes),
+			GroupID:     uuid.UUID(row.PrivateGroupID.Bytes),
+			UserID:      uuid.UUID(row.UserID.Bytes),
+			TelegramID:  pgtypeToInt64(row.TelegramID),
+			ChatID:      row.ChatID,
+			BotID:       pgtypeToInt64(row.BotID),
+			RequestedAt: pgtypeToTime(row.RequestedAt),
+		})
+	}
+	return requests, nil
+}
+
+func groupFromRow(row sqlc.GetPrivateGroupByIDRow) (*group.Group, error) {
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private group ID: %w", err)
+	}
+	return &group.Group{
+		ID:                 id,
+		TelegramBotID:      uuid.UUID(row.TelegramBotID.Bytes),
+		ChatID:             row.ChatID,
+		Title:              pgtypeToString(row.Title),
+		AccessTag:          pgtypeToString(row.AccessTag),
+		DeclineUnqualified: row.DeclineUnqualified,
+	}, nil
+}
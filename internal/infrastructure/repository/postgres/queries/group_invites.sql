@@ -0,0 +1,73 @@
+-- name: CreateGroupInvite :one
+INSERT INTO
+    group_invites (
+        private_group_id,
+        user_id,
+        script_progress_id,
+        invite_link,
+        expires_at
+    )
+VALUES
+    (
+        @private_group_id,
+        @user_id,
+        @script_progress_id,
+        @invite_link,
+        @expires_at
+    ) RETURNING id;
+
+-- name: GetPendingGroupInvite :one
+SELECT
+    id,
+    invite_link,
+    expires_at
+FROM
+    group_invites
+WHERE
+    private_group_id = @private_group_id
+    AND user_id = @user_id
+    AND "status" = 'pending'
+    AND expires_at > @now
+ORDER BY
+    expires_at DESC
+LIMIT
+    1;
+
+-- name: JoinGroupInvites :execrows
+UPDATE
+    group_invites
+SET
+    "status" = 'joined',
+    joined_at = @joined_at
+WHERE
+    private_group_id = @private_group_id
+    AND user_id = @user_id
+    AND "status" = 'pending';
+
+-- name: ListExpiredGroupInvites :many
+SELECT
+    gi.id,
+    gi.invite_link,
+    g.chat_id,
+    b.bot_id
+FROM
+    group_invites gi
+    JOIN private_groups g ON g.id = gi.private_group_id
+    JOIN telegram_bots b ON b.id = g.telegram_bot_id
+WHERE
+    gi."status" = 'pending'
+    AND gi.expires_at <= @now
+ORDER BY
+    gi.expires_at
+LIMIT
+    @limit_val;
+
+-- name: RevokeGroupInvite :exec
+UPDATE
+    group_invites
+SET
+    "status" = 'revoked',
+    revoked_at = @revoked_at
+WHERE
+    id = @id
+    AND "status" = 'pending';
\ No newline at end of file
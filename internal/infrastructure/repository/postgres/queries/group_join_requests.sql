@@ -0,0 +1,49 @@
+-- name: SaveGroupJoinRequest :exec
+INSERT INTO
+    group_join_requests (private_group_id, user_id, requested_at)
+VALUES
+    (@private_group_id, @user_id, @requested_at) ON CONFLICT (private_group_id, user_id) DO
+UPDATE
+SET
+    "status" = 'pending',
+    requested_at = EXCLUDED.requested_at,
+    decided_at = NULL;
+
+-- name: DecideGroupJoinRequest :exec
+UPDATE
+    group_join_requests
+SET
+    "status" = @status,
+    decided_at = @decided_at
+WHERE
+    private_group_id = @private_group_id
+    AND user_id = @user_id;
+
+-- name: ListPendingGroupJoinRequests :many
+SELECT
+    r.id,
+    r.requested_at,
+    r.private_group_id,
+    r.user_id,
+    u.telegram_id,
+    g.chat_id,
+    b.bot_id
+FROM
+    group_join_requests r
+    JOIN users u ON u.id = r.user_id
+    JOIN private_groups g ON g.id = r.private_group_id
+    JOIN telegram_bots b ON b.id = g.telegram_bot_id
+WHERE
+    r."status" = 'pending'
+    AND (
+        r.requested_at > @after_requested_at
+        OR (
+            r.requested_at = @after_requested_at
+            AND r.id > @after_id
+        )
+    )
+ORDER BY
+    r.requested_at,
+    r.id
+LIMIT
+    @limit_val
\ No newline at end of file
@@ -0,0 +1,65 @@
+-- name: SaveInboxThread :one
+INSERT INTO
+    inbox_threads (telegram_bot_id, user_id)
+VALUES
+    (@telegram_bot_id, @user_id) ON CONFLICT (telegram_bot_id, user_id) DO
+UPDATE
+SET
+    updated_at = NOW() RETURNING id;
+
+-- name: CreateInboxMessage :exec
+INSERT INTO
+    inbox_messages (thread_id, direction, "text", admin_user_id)
+VALUES
+    (@thread_id, @direction, @text, @admin_user_id);
+
+-- name: CreateInboxRelay :exec
+INSERT INTO
+    inbox_relays (relay_bot_id, chat_id, message_id, thread_id)
+VALUES
+    (@relay_bot_id, @chat_id, @message_id, @thread_id);
+
+-- name: GetInboxThreadByRelay :one
+SELECT
+    t.id,
+    t.telegram_bot_id,
+    t.user_id,
+    tb.bot_id,
+    u.telegram_id
+FROM
+    inbox_relays r
+    JOIN inbox_threads t ON t.id = r.thread_id
+    JOIN telegram_bots tb ON tb.id = t.telegram_bot_id
+    JOIN users u ON u.id = t.user_id
+WHERE
+    r.relay_bot_id = @relay_bot_id
+    AND r.chat_id = @chat_id
+    AND r.message_id = @message_id;
+
+-- name: ListInboxRecipients :many
+SELECT
+    u.telegram_id
+FROM
+    user_telegram_bots ub
+    JOIN users u ON u.id = ub.user_id
+WHERE
+    ub.telegram_bot_id = @telegram_bot_id
+    AND ub."role" IN ('owner', 'admin')
+    AND u.telegram_id IS NOT NULL;
+
+-- name: GetTelegramBotSupportChat :one
+SELECT
+    support_chat_id
+FROM
+    telegram_bots
+WHERE
+    id = @id;
+
+-- name: SetTelegramBotSupportChat :exec
+UPDATE
+    telegram_bots
+SET
+    support_chat_id = @support_chat_id,
+    updated_at = NOW()
+WHERE
+    id = @id;
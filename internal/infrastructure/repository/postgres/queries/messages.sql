@@ -0,0 +1,164 @@
+-- name: GetMessageByID :one
+SELECT
+    id,
+    content,
+    parse_mode
+FROM
+    messages
+WHERE
+    id = @id
+    AND deleted_at IS NULL;
+
+-- name: ListMessageButtons :many
+SELECT
+    id,
+    message_id,
+    "text",
+    "url",
+    set_attribute,
+    set_value,
+    add_tag,
+    track_clicks
+FROM
+    message_buttons
+WHERE
+    message_id = @message_id
+    AND deleted_at IS NULL
+ORDER BY
+    created_at;
+
+
+-- name: GetMessageButtonByID :one
+SELECT
+    id,
+    message_id,
+    "text",
+    "url",
+    set_attribute,
+    set_value,
+    add_tag,
+    track_clicks
+FROM
+    message_buttons
+WHERE
+    id = @id
+    AND deleted_at IS NULL;
+
+-- name: CreateMessage :one
+INSERT INTO
+    messages (content, parse_mode)
+VALUES
+    (@content, @parse_mode) RETURNING id;
+
+-- name: UpdateMessage :execrows
+UPDATE
+    messages
+SET
+    content = @content,
+    parse_mode = @parse_mode,
+    updated_at = NOW()
+WHERE
+    id = @id
+    AND deleted_at IS NULL;
+
+-- name: CreateMessageButton :one
+INSERT INTO
+    message_buttons (
+        message_id,
+        "text",
+        "url",
+        set_attribute,
+        set_value,
+        add_tag,
+        track_clicks
+    )
+VALUES
+    (
+        @message_id,
+        @text,
+        @url,
+        @set_attribute,
+        @set_value,
+        @add_tag,
+        @track_clicks
+    ) RETURNING id;
+
+-- name: CopyMessageMedia :exec
+INSERT INTO
+    message_media (
+        uploaded_by,
+        message_id,
+        storage_key,
+        ext,
+        "size",
+        mime_type,
+        kind
+    )
+SELECT
+    uploaded_by,
+    @to_message_id::uuid,
+    storage_key,
+    ext,
+    "size",
+    mime_type,
+    kind
+FROM
+    message_media
+WHERE
+    message_id = @from_message_id
+    AND deleted_at IS NULL;
+
+-- name: CreateMessageMedia :exec
+INSERT INTO
+    message_media (
+        message_id,
+        storage_key,
+        ext,
+        "size",
+        mime_type,
+        kind
+    )
+VALUES
+    (
+        @message_id,
+        @storage_key,
+        @ext,
+        @size,
+        @mime_type,
+        @kind
+    );
+
+-- name: ListMessageMedia :many
+SELECT
+    id,
+    message_id,
+    storage_key,
+    ext,
+    "size",
+    mime_type,
+    kind
+FROM
+    message_media
+WHERE
+    message_id = @message_id
+    AND deleted_at IS NULL
+ORDER BY
+    created_at;
+
+-- name: CreateMessageButtonClick :exec
+INSERT INTO
+    message_button_clicks (
+        button_id,
+        user_id,
+        clicked_at,
+        step_id,
+        delivery_id
+    )
+VALUES
+    (
+        @button_id,
+        @user_id,
+        @clicked_at,
+        @step_id,
+        @delivery_id
+    )
\ No newline at end of file
@@ -0,0 +1,58 @@
+-- name: GetPrivateGroupByID :one
+SELECT
+    id,
+    telegram_bot_id,
+    chat_id,
+    title,
+    access_tag,
+    decline_unqualified
+FROM
+    private_groups
+WHERE
+    id = @id;
+
+-- name: GetPrivateGroupByChatID :one
+SELECT
+    id,
+    telegram_bot_id,
+    chat_id,
+    title,
+    access_tag,
+    decline_unqualified
+FROM
+    private_groups
+WHERE
+    telegram_bot_id = @telegram_bot_id
+    AND chat_id = @chat_id;
+
+-- name: SavePrivateGroup :one
+INSERT INTO
+    private_groups (telegram_bot_id, chat_id, title)
+VALUES
+    (@telegram_bot_id, @chat_id, @title) ON CONFLICT (telegram_bot_id, chat_id) DO
+UPDATE
+SET
+    title = EXCLUDED.title RETURNING id;
+
+-- name: SetPrivateGroupPolicy :exec
+UPDATE
+    private_groups
+SET
+    access_tag = @access_tag,
+    decline_unqualified = @decline_unqualified
+WHERE
+    id = @id;
+
+-- name: CountGroupAccessDeliveries :one
+SELECT
+    COUNT(*)
+FROM
+    script_progress_delivery d
+    JOIN script_progress p ON p.id = d.script_progress_id
+    JOIN script_steps s ON s.id = d.step_id
+    JOIN scripts sc ON sc.id = p.script_id
+WHERE
+    p.user_id = @user_id
+    AND sc.private_group_id = @private_group_id
+    AND s.grants_group_access = TRUE
+    AND NOT p.preview
\ No newline at end of file
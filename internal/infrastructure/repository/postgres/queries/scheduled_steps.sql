@@ -0,0 +1,133 @@
+-- name: CreateScheduledStep :one
+INSERT INTO
+    scheduled_steps (
+        script_progress_id,
+        step_id,
+        execute_at,
+        "status",
+        kind
+    )
+VALUES
+    (
+        @script_progress_id,
+        @step_id,
+        @execute_at,
+        'pending',
+        @kind
+    ) RETURNING id,
+    script_progress_id,
+    step_id,
+    execute_at,
+    "status",
+    attempts,
+    last_error,
+    kind;
+
+-- name: ClaimDueScheduledSteps :many
+UPDATE
+    scheduled_steps
+SET
+    "status" = 'processing',
+    send_at = @now,
+    attempts = attempts + 1
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            scheduled_steps
+        WHERE
+            (
+                "status" = 'pending'
+                AND execute_at <= @now
+            )
+            OR (
+                "status" = 'processing'
+                AND send_at <= @stale_before
+            )
+        ORDER BY
+            execute_at
+        LIMIT
+            @limit_val FOR
+        UPDATE
+            SKIP LOCKED
+    ) RETURNING id,
+    script_progress_id,
+    step_id,
+    execute_at,
+    "status",
+    attempts,
+    last_error,
+    kind;
+
+-- name: MarkScheduledStepSent :exec
+UPDATE
+    scheduled_steps
+SET
+    "status" = 'sent',
+    sent_at = @sent_at
+WHERE
+    id = @id;
+
+-- name: MarkScheduledStepFailed :exec
+UPDATE
+    scheduled_steps
+SET
+    "status" = 'failed',
+    last_error = @last_error
+WHERE
+    id = @id;
+
+-- name: CancelScheduledStep :exec
+UPDATE
+    scheduled_steps
+SET
+    "status" = 'cancelled'
+WHERE
+    id = @id;
+
+-- name: RescheduleScheduledStep :exec
+UPDATE
+    scheduled_steps
+SET
+    "status" = 'pending',
+    execute_at = @execute_at,
+    last_error = @last_error
+WHERE
+    id = @id;
+
+-- name: DeferScheduledStep :exec
+UPDATE
+    scheduled_steps
+SET
+    "status" = 'pending',
+    execute_at = @execute_at,
+    last_error = @last_error,
+    attempts = GREATEST(attempts - 1, 0)
+WHERE
+    id = @id;
+
+-- name: CancelScheduledStepsForProgress :exec
+UPDATE
+    scheduled_steps
+SET
+    "status" = 'cancelled'
+WHERE
+    script_progress_id = @script_progress_id
+    AND "status" IN ('pending', 'processing');
+
+-- name: ListOverdueScheduledSteps :many
+SELECT
+    sc.telegram_bot_id,
+    COUNT(*) AS overdue,
+    MIN(ss.execute_at)::timestamp AS oldest
+FROM
+    scheduled_steps ss
+    JOIN script_progress p ON p.id = ss.script_progress_id
+    JOIN scripts sc ON sc.id = p.script_id
+WHERE
+    ss."status" = 'pending'
+    AND ss.execute_at < @due_before
+    AND NOT p.preview
+GROUP BY
+    sc.telegram_bot_id;
@@ -0,0 +1,43 @@
+-- name: GetScriptDeepLink :one
+SELECT
+    id,
+    telegram_bot_id,
+    payload,
+    script_id,
+    "source"
+FROM
+    script_deep_links
+WHERE
+    telegram_bot_id = @telegram_bot_id
+    AND payload = @payload;
+
+-- name: ListScriptDeepLinks :many
+SELECT
+    id,
+    telegram_bot_id,
+    payload,
+    script_id,
+    "source"
+FROM
+    script_deep_links
+WHERE
+    telegram_bot_id = @telegram_bot_id
+ORDER BY
+    payload;
+
+-- name: SaveScriptDeepLink :one
+INSERT INTO
+    script_deep_links (telegram_bot_id, payload, script_id, "source")
+VALUES
+    (@telegram_bot_id, @payload, @script_id, @source) ON CONFLICT (telegram_bot_id, payload) DO
+UPDATE
+SET
+    script_id = EXCLUDED.script_id,
+    "source" = EXCLUDED."source" RETURNING id;
+
+-- name: DeleteScriptDeepLink :execrows
+DELETE FROM
+    script_deep_links
+WHERE
+    telegram_bot_id = @telegram_bot_id
+    AND payload = @payload;
\ No newline at end of file
@@ -0,0 +1,293 @@
+-- name: CreateScriptProgress :one
+INSERT INTO
+    script_progress (
+        user_id,
+        script_id,
+        current_step_id,
+        "status",
+        step_started_at,
+        started_at,
+        "source",
+        script_version_id,
+        preview
+    )
+VALUES
+    (
+        @user_id,
+        @script_id,
+        @current_step_id,
+        @status,
+        @step_started_at,
+        @started_at,
+        @source,
+        @script_version_id,
+        @preview
+    ) RETURNING id,
+    user_id,
+    script_id,
+    current_step_id,
+    "status",
+    step_started_at,
+    started_at,
+    finished_at,
+    waiting_for,
+    "source",
+    script_version_id,
+    preview;
+
+-- name: GetScriptProgressByID :one
+SELECT
+    id,
+    user_id,
+    script_id,
+    current_step_id,
+    "status",
+    step_started_at,
+    started_at,
+    finished_at,
+    waiting_for,
+    "source",
+    script_version_id,
+    preview
+FROM
+    script_progress
+WHERE
+    id = @id;
+
+-- name: GetActiveScriptProgress :one
+SELECT
+    id,
+    user_id,
+    script_id,
+    current_step_id,
+    "status",
+    step_started_at,
+    started_at,
+    finished_at,
+    waiting_for,
+    "source",
+    script_version_id,
+    preview
+FROM
+    script_progress
+WHERE
+    user_id = @user_id
+    AND script_id = @script_id
+    AND "status" IN ('active', 'waiting')
+    AND NOT preview
+ORDER BY
+    started_at DESC
+LIMIT
+    1;
+
+-- name: GetWaitingScriptProgressForBot :one
+SELECT
+    sp.id,
+    sp.user_id,
+    sp.script_id,
+    sp.current_step_id,
+    sp."status",
+    sp.step_started_at,
+    sp.started_at,
+    sp.finished_at,
+    sp.waiting_for,
+    sp."source",
+    sp.script_version_id,
+    sp.preview
+FROM
+    script_progress sp
+    JOIN scripts s ON s.id = sp.script_id
+WHERE
+    sp.user_id = @user_id
+    AND s.telegram_bot_id = @telegram_bot_id
+    AND sp."status" = 'waiting'
+ORDER BY
+    sp.step_started_at DESC
+LIMIT
+    1;
+
+-- name: UpdateScriptProgress :exec
+UPDATE
+    script_progress
+SET
+    current_step_id = @current_step_id,
+    "status" = @status,
+    step_started_at = @step_started_at,
+    finished_at = @finished_at,
+    waiting_for = @waiting_for
+WHERE
+    id = @id;
+
+-- name: ResumeScriptProgress :execrows
+UPDATE
+    script_progress
+SET
+    "status" = 'active',
+    waiting_for = NULL
+WHERE
+    id = @id
+    AND "status" = 'waiting';
+
+-- name: CreateScriptProgressDelivery :exec
+INSERT INTO
+    script_progress_delivery (
+        id,
+        message_id,
+        step_id,
+        script_progress_id,
+        sent_at,
+        channel,
+        "snapshot",
+        telegram_message_id,
+        variant_id
+    )
+VALUES
+    (
+        @id,
+        @message_id,
+        @step_id,
+        @script_progress_id,
+        @sent_at,
+        @channel,
+        @snapshot,
+        @telegram_message_id,
+        @variant_id
+    );
+
+-- name: CreateScriptProgressInput :exec
+INSERT INTO
+    script_progress_inputs (
+        script_progress_id,
+        step_id,
+        "type",
+        "value",
+        button_id
+    )
+VALUES
+    (
+        @script_progress_id,
+        @step_id,
+        @type,
+        @value,
+        @button_id
+    );
+
+-- name: ListScriptProgressInputs :many
+SELECT
+    step_id,
+    "type",
+    "value",
+    button_id
+FROM
+    script_progress_inputs
+WHERE
+    script_progress_id = @script_progress_id
+ORDER BY
+    created_at;
+
+-- name: ListScriptProgressDeliveredSteps :many
+SELECT
+    step_id
+FROM
+    script_progress_delivery
+WHERE
+    script_progress_id = @script_progress_id
+    AND step_id IS NOT NULL;
+
+-- name: CreateScriptProgressStep :exec
+INSERT INTO
+    script_progress_steps (
+        script_progress_id,
+        step_id,
+        transition_id,
+        entered_at
+    )
+VALUES
+    (
+        @script_progress_id,
+        @step_id,
+        @transition_id,
+        @entered_at
+    );
+
+-- name: FinishScriptPreviews :exec
+UPDATE
+    script_progress
+SET
+    "status" = 'finished',
+    waiting_for = NULL,
+    finished_at = @finished_at
+WHERE
+    user_id = @user_id
+    AND script_id = @script_id
+    AND preview = TRUE
+    AND "status" IN ('active', 'waiting');
+
+-- name: ListScriptFunnelEntries :many
+SELECT
+    ps.script_progress_id,
+    ps.step_id,
+    ps.entered_at,
+    p."status",
+    EXISTS (
+        SELECT
+            1
+        FROM
+            script_progress_delivery d
+        WHERE
+            d.script_progress_id = ps.script_progress_id
+            AND d.step_id = ps.step_id
+    ) AS sent,
+    EXISTS (
+        SELECT
+            1
+        FROM
+            message_button_clicks c
+            JOIN message_buttons b ON b.id = c.button_id
+        WHERE
+            c.user_id = p.user_id
+            AND c.clicked_at >= ps.entered_at
+            AND (
+                b.message_id = s.message_id
+                OR b.message_id IN (
+                    SELECT
+                        v.message_id
+                    FROM
+                        script_step_variants v
+                    WHERE
+                        v.step_id = s.id
+                )
+            )
+    ) AS clicked
+FROM
+    script_progress_steps ps
+    JOIN script_progress p ON p.id = ps.script_progress_id
+    JOIN script_steps s ON s.id = ps.step_id
+WHERE
+    p.script_version_id = @script_version_id
+    AND NOT p.preview
+    AND p.started_at >= @started_from
+    AND p.started_at < @started_to
+    AND (
+        @source::text = ''
+        OR p."source" = @source
+    )
+ORDER BY
+    ps.script_progress_id,
+    ps.entered_at;
+
+-- name: IsButtonDeliveredToUser :one
+SELECT
+    EXISTS(
+        SELECT
+            1
+        FROM
+            script_progress_delivery d
+            JOIN script_progress sp ON sp.id = d.script_progress_id
+            JOIN scripts s ON s.id = sp.script_id
+            JOIN message_buttons mb ON mb.message_id = d.message_id
+        WHERE
+            mb.id = @button_id
+            AND sp.user_id = @user_id
+            AND s.telegram_bot_id = @telegram_bot_id
+    );
@@ -0,0 +1,29 @@
+-- name: ClaimScriptStepPost :execrows
+INSERT INTO
+    script_step_posts (step_id, posted_at)
+VALUES
+    (@step_id, @posted_at) ON CONFLICT (step_id) DO NOTHING;
+
+-- name: GetScriptStepPost :one
+SELECT
+    step_id,
+    telegram_message_id,
+    posted_at
+FROM
+    script_step_posts
+WHERE
+    step_id = @step_id;
+
+-- name: SetScriptStepPostMessage :exec
+UPDATE
+    script_step_posts
+SET
+    telegram_message_id = @telegram_message_id
+WHERE
+    step_id = @step_id;
+
+-- name: DeleteScriptStepPost :exec
+DELETE FROM
+    script_step_posts
+WHERE
+    step_id = @step_id;
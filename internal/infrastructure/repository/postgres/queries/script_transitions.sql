@@ -0,0 +1,52 @@
+-- name: ListScriptTransitions :many
+SELECT
+    id,
+    script_id,
+    script_version_id,
+    from_step_id,
+    to_step_id,
+    "condition",
+    "value",
+    priority
+FROM
+    script_transitions
+WHERE
+    script_version_id = @script_version_id
+ORDER BY
+    priority,
+    created_at;
+
+-- name: CreateScriptTransition :exec
+INSERT INTO
+    script_transitions (
+        script_id,
+        script_version_id,
+        from_step_id,
+        to_step_id,
+        "condition",
+        "value",
+        priority
+    )
+VALUES
+    (
+        @script_id,
+        @script_version_id,
+        @from_step_id,
+        @to_step_id,
+        @condition,
+        @value,
+        @priority
+    );
+
+-- name: DeleteScriptTransitions :exec
+DELETE FROM
+    script_transitions
+WHERE
+    script_version_id = @script_version_id;
+
+-- name: DeleteScriptStepTransitions :exec
+DELETE FROM
+    script_transitions
+WHERE
+    from_step_id = @step_id
+    OR to_step_id = @step_id;
@@ -0,0 +1,89 @@
+-- name: CreateScriptVersion :one
+INSERT INTO
+    script_versions (script_id, "number")
+VALUES
+    (@script_id, @number) RETURNING id,
+    created_at;
+
+-- name: GetScriptVersionByID :one
+SELECT
+    id,
+    script_id,
+    "number",
+    created_at,
+    published_at
+FROM
+    script_versions
+WHERE
+    id = @id;
+
+-- name: GetDraftScriptVersion :one
+SELECT
+    id,
+    script_id,
+    "number",
+    created_at,
+    published_at
+FROM
+    script_versions
+WHERE
+    script_id = @script_id
+    AND published_at IS NULL;
+
+-- name: GetScriptVersionByNumber :one
+SELECT
+    id,
+    script_id,
+    "number",
+    created_at,
+    published_at
+FROM
+    script_versions
+WHERE
+    script_id = @script_id
+    AND "number" = @number;
+
+-- name: ListScriptVersions :many
+SELECT
+    id,
+    script_id,
+    "number",
+    created_at,
+    published_at
+FROM
+    script_versions
+WHERE
+    script_id = @script_id
+ORDER BY
+    "number" DESC;
+
+-- name: PublishScriptVersion :execrows
+UPDATE
+    script_versions
+SET
+    published_at = @published_at
+WHERE
+    id = @id
+    AND published_at IS NULL;
+
+-- name: CountPublishedMessageSteps :one
+SELECT
+    COUNT(*)
+FROM
+    script_steps st
+    JOIN script_versions v ON v.id = st.script_version_id
+WHERE
+    (
+        st.message_id = @message_id
+        OR EXISTS (
+            SELECT
+                1
+            FROM
+                script_step_variants sv
+            WHERE
+                sv.step_id = st.id
+                AND sv.message_id = @message_id
+        )
+    )
+    AND st.deleted_at IS NULL
+    AND v.published_at IS NOT NULL
\ No newline at end of file
@@ -0,0 +1,303 @@
+-- name: GetScriptByID :one
+SELECT
+    id,
+    telegram_bot_id,
+    "name",
+    is_active,
+    private_group_id,
+    published_version_id
+FROM
+    scripts
+WHERE
+    id = @id
+    AND deleted_at IS NULL;
+
+-- name: GetDefaultScriptForBot :one
+SELECT
+    id,
+    telegram_bot_id,
+    "name",
+    is_active,
+    private_group_id,
+    published_version_id
+FROM
+    scripts
+WHERE
+    telegram_bot_id = @telegram_bot_id
+    AND is_active = TRUE
+    AND deleted_at IS NULL
+ORDER BY
+    created_at
+LIMIT
+    1;
+
+-- name: ListScriptSteps :many
+SELECT
+    id,
+    script_id,
+    script_version_id,
+    message_id,
+    "order",
+    channel,
+    target_chat_id,
+    target_thread_id,
+    timing,
+    skip_on_error,
+    wait_for,
+    wait_keywords,
+    wait_timeout,
+    fallback_step_id,
+    wait_channel_id,
+    save_as,
+    grants_group_access
+FROM
+    script_steps
+WHERE
+    script_version_id = @script_version_id
+    AND deleted_at IS NULL
+ORDER BY
+    "order";
+
+-- name: GetScriptStepByID :one
+SELECT
+    id,
+    script_id,
+    script_version_id,
+    message_id,
+    "order",
+    channel,
+    target_chat_id,
+    target_thread_id,
+    timing,
+    skip_on_error,
+    wait_for,
+    wait_keywords,
+    wait_timeout,
+    fallback_step_id,
+    wait_channel_id,
+    save_as,
+    grants_group_access
+FROM
+    script_steps
+WHERE
+    id = @id;
+
+
+-- name: GetScriptByName :one
+SELECT
+    id,
+    telegram_bot_id,
+    "name",
+    is_active,
+    private_group_id,
+    published_version_id
+FROM
+    scripts
+WHERE
+    telegram_bot_id = @telegram_bot_id
+    AND "name" = @name
+    AND deleted_at IS NULL;
+
+-- name: SetScriptPrivateGroup :exec
+UPDATE
+    scripts
+SET
+    private_group_id = @private_group_id,
+    updated_at = NOW()
+WHERE
+    id = @id;
+
+-- name: SetScriptPublishedVersion :exec
+UPDATE
+    scripts
+SET
+    published_version_id = @published_version_id,
+    updated_at = NOW()
+WHERE
+    id = @id;
+
+-- name: CreateScriptStep :one
+INSERT INTO
+    script_steps (
+        script_id,
+        script_version_id,
+        message_id,
+        "order",
+        channel,
+        target_chat_id,
+        target_thread_id,
+        timing,
+        skip_on_error,
+        wait_for,
+        wait_keywords,
+        wait_timeout,
+        wait_channel_id,
+        save_as,
+        grants_group_access
+    )
+VALUES
+    (
+        @script_id,
+        @script_version_id,
+        @message_id,
+        @order,
+        @channel,
+        @target_chat_id,
+        @target_thread_id,
+        @timing,
+        @skip_on_error,
+        @wait_for,
+        @wait_keywords,
+        @wait_timeout,
+        @wait_channel_id,
+        @save_as,
+        @grants_group_access
+    ) RETURNING id;
+
+-- name: SetScriptStepFallback :exec
+UPDATE
+    script_steps
+SET
+    fallback_step_id = @fallback_step_id,
+    updated_at = NOW()
+WHERE
+    id = @id;
+
+-- name: CreateScript :one
+INSERT INTO
+    scripts (telegram_bot_id, "name")
+VALUES
+    (@telegram_bot_id, @name) RETURNING id;
+
+-- name: DeleteScriptVersionSteps :exec
+UPDATE
+    script_steps
+SET
+    deleted_at = NOW()
+WHERE
+    script_version_id = @script_version_id
+    AND deleted_at IS NULL;
+
+-- name: ListScriptStepVariants :many
+SELECT
+    id,
+    step_id,
+    message_id,
+    "name",
+    weight
+FROM
+    script_step_variants
+WHERE
+    step_id = @step_id
+ORDER BY
+    "name";
+
+-- name: ListScriptVersionVariants :many
+SELECT
+    v.id,
+    v.step_id,
+    v.message_id,
+    v."name",
+    v.weight
+FROM
+    script_step_variants v
+    JOIN script_steps s ON s.id = v.step_id
+WHERE
+    s.script_version_id = @script_version_id
+    AND s.deleted_at IS NULL
+ORDER BY
+    s."order",
+    v."name";
+
+-- name: CreateScriptStepVariant :exec
+INSERT INTO
+    script_step_variants (step_id, message_id, "name", weight)
+VALUES
+    (@step_id, @message_id, @name, @weight);
+
+-- name: ListScriptVariantStats :many
+SELECT
+    s."order",
+    v."name",
+    v.weight,
+    COUNT(d.script_progress_id) AS delivered,
+    COUNT(d.script_progress_id) FILTER (
+        WHERE
+            EXISTS (
+                SELECT
+                    1
+                FROM
+                    message_button_clicks c
+                    JOIN message_buttons b ON b.id = c.button_id
+                WHERE
+                    b.message_id = v.message_id
+                    AND c.user_id = d.user_id
+                    AND c.clicked_at >= d.sent_at
+            )
+    ) AS clicked,
+    COUNT(d.script_progress_id) FILTER (
+        WHERE
+            EXISTS (
+                SELECT
+                    1
+                FROM
+                    script_progress_steps ps
+                WHERE
+                    ps.script_progress_id = d.script_progress_id
+                    AND ps.step_id <> v.step_id
+                    AND ps.entered_at >= d.sent_at
+            )
+    ) AS advanced
+FROM
+    script_step_variants v
+    JOIN script_steps s ON s.id = v.step_id
+    LEFT JOIN (
+        SELECT
+            pd.variant_id,
+            pd.script_progress_id,
+            pd.sent_at,
+            p.user_id
+        FROM
+            script_progress_delivery pd
+            JOIN script_progress p ON p.id = pd.script_progress_id
+        WHERE
+            NOT p.preview
+    ) d ON d.variant_id = v.id
+WHERE
+    s.script_version_id = @script_version_id
+    AND s.deleted_at IS NULL
+GROUP BY
+    s."order",
+    v.id,
+    v."name",
+    v.weight
+ORDER BY
+    s."order",
+    v."name";
+
+-- name: SetScriptStepOrder :exec
+UPDATE
+    script_steps
+SET
+    "order" = @order,
+    updated_at = NOW()
+WHERE
+    id = @id;
+
+-- name: DeleteScriptStep :execrows
+UPDATE
+    script_steps
+SET
+    deleted_at = NOW()
+WHERE
+    id = @id
+    AND deleted_at IS NULL;
+
+-- name: ClearScriptStepFallbacks :exec
+UPDATE
+    script_steps
+SET
+    fallback_step_id = NULL,
+    updated_at = NOW()
+WHERE
+    fallback_step_id = @fallback_step_id
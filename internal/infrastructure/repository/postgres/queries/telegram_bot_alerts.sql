@@ -0,0 +1,63 @@
+-- name: CreateTelegramBotAlert :exec
+INSERT INTO
+    telegram_bot_alerts (telegram_bot_id, kind, "text", created_at)
+VALUES
+    (@telegram_bot_id, @kind, @text, @created_at);
+
+-- name: CountTelegramBotAlertsSince :one
+SELECT
+    COUNT(*)
+FROM
+    telegram_bot_alerts
+WHERE
+    telegram_bot_id = @telegram_bot_id
+    AND created_at >= @since;
+
+-- name: CountTelegramBotAlertsOfKindSince :one
+SELECT
+    COUNT(*)
+FROM
+    telegram_bot_alerts
+WHERE
+    telegram_bot_id = @telegram_bot_id
+    AND kind = @kind
+    AND created_at >= @since;
+
+-- name: ListTelegramBotAlertRecipients :many
+SELECT
+    u.telegram_id
+FROM
+    user_telegram_bots ub
+    JOIN users u ON u.id = ub.user_id
+    LEFT JOIN telegram_bot_alert_mutes m ON m.user_id = ub.user_id
+    AND m.telegram_bot_id = ub.telegram_bot_id
+WHERE
+    ub.telegram_bot_id = @telegram_bot_id
+    AND ub."role" = 'owner'
+    AND u.telegram_id IS NOT NULL
+    AND m.user_id IS NULL;
+
+-- name: MuteTelegramBotAlerts :exec
+INSERT INTO
+    telegram_bot_alert_mutes (user_id, telegram_bot_id)
+VALUES
+    (@user_id, @telegram_bot_id) ON CONFLICT (user_id, telegram_bot_id) DO NOTHING;
+
+-- name: UnmuteTelegramBotAlerts :exec
+DELETE FROM
+    telegram_bot_alert_mutes
+WHERE
+    user_id = @user_id
+    AND telegram_bot_id = @telegram_bot_id;
+
+-- name: TelegramBotAlertsMuted :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            telegram_bot_alert_mutes
+        WHERE
+            user_id = @user_id
+            AND telegram_bot_id = @telegram_bot_id
+    );
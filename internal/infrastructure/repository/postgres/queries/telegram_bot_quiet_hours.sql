@@ -0,0 +1,30 @@
+-- name: ListTelegramBotQuietHours :many
+SELECT
+    start_minute,
+    end_minute
+FROM
+    telegram_bot_quiet_hours
+WHERE
+    telegram_bot_id = @telegram_bot_id
+ORDER BY
+    start_minute;
+
+-- name: CreateTelegramBotQuietHours :exec
+INSERT INTO
+    telegram_bot_quiet_hours (
+        telegram_bot_id,
+        start_minute,
+        end_minute
+    )
+VALUES
+    (
+        @telegram_bot_id,
+        @start_minute,
+        @end_minute
+    );
+
+-- name: DeleteTelegramBotQuietHours :exec
+DELETE FROM
+    telegram_bot_quiet_hours
+WHERE
+    telegram_bot_id = @telegram_bot_id;
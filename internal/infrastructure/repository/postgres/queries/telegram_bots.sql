@@ -1,31 +1,45 @@
 -- name: CreateTelegramBot :one
 INSERT INTO
     telegram_bots (
+        id,
         bot_id,
         username,
         first_name,
         last_name,
         encrypted_token,
         encryption_version,
+        encryption_algorithm,
         "role",
         last_error,
         last_checked_at,
         revoked_at,
-        disabled_at
+        disabled_at,
+        token_bound,
+        token_algorithm_bound,
+        wrapped_data_key,
+        kek_id,
+        timezone
     )
 VALUES
     (
+        @id,
         @bot_id,
         @username,
         @first_name,
         @last_name,
         @encrypted_token,
         @encryption_version,
+        @encryption_algorithm,
         @role,
         @last_error,
         @last_checked_at,
         @revoked_at,
-        @disabled_at
+        @disabled_at,
+        @token_bound,
+        @token_algorithm_bound,
+        @wrapped_data_key,
+        @kek_id,
+        @timezone
     ) RETURNING id,
     bot_id,
     username,
@@ -33,11 +47,17 @@ VALUES
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at;
 
@@ -50,11 +70,17 @@ SELECT
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at
 FROM
@@ -71,11 +97,17 @@ SELECT
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at
 FROM
@@ -83,6 +115,33 @@ FROM
 WHERE
     bot_id = @bot_id;
 
+-- name: GetTelegramBotByUsername :one
+SELECT
+    id,
+    bot_id,
+    username,
+    first_name,
+    last_name,
+    encrypted_token,
+    encryption_version,
+    encryption_algorithm,
+    "role",
+    last_error,
+    last_checked_at,
+    disabled_at,
+    revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
+    created_at,
+    updated_at
+FROM
+    telegram_bots
+WHERE
+    username = @username;
+
 -- name: UpdateTelegramBot :one
 UPDATE
     telegram_bots
@@ -93,25 +152,37 @@ SET
     last_name = @last_name,
     encrypted_token = @encrypted_token,
     encryption_version = @encryption_version,
+    encryption_algorithm = @encryption_algorithm,
     "role" = @role,
     last_error = @last_error,
     last_checked_at = @last_checked_at,
     revoked_at = @revoked_at,
     disabled_at = @disabled_at,
+    token_bound = @token_bound,
+    token_algorithm_bound = @token_algorithm_bound,
+    wrapped_data_key = @wrapped_data_key,
+    kek_id = @kek_id,
+    timezone = @timezone,
     updated_at = NOW()
 WHERE
-    bot_id = @bot_id RETURNING id,
+    id = @id RETURNING id,
     bot_id,
     username,
     first_name,
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at;
 
@@ -130,14 +201,119 @@ SELECT
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at
 FROM
     telegram_bots
 ORDER BY
-    created_at DESC;
\ No newline at end of file
+    created_at DESC;
+
+-- name: ListTelegramBotTokensForReseal :many
+SELECT
+    id,
+    encrypted_token,
+    encryption_version,
+    encryption_algorithm,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id
+FROM
+    telegram_bots
+WHERE
+    token_bound = FALSE
+    OR token_algorithm_bound = FALSE
+    OR encryption_version <> @current_version
+    OR encryption_algorithm <> @current_algorithm
+    OR kek_id IS DISTINCT FROM sqlc.narg('kek_id');
+
+-- name: ResealTelegramBotToken :execrows
+UPDATE
+    telegram_bots
+SET
+    encrypted_token = @encrypted_token,
+    encryption_version = @encryption_version,
+    encryption_algorithm = @encryption_algorithm,
+    token_bound = TRUE,
+    token_algorithm_bound = TRUE,
+    wrapped_data_key = @wrapped_data_key,
+    kek_id = @kek_id,
+    updated_at = NOW()
+WHERE
+    id = @id
+    AND encrypted_token = @old_encrypted_token;
+
+-- name: GetTelegramBotMemberRole :one
+SELECT
+    "role"
+FROM
+    user_telegram_bots
+WHERE
+    telegram_bot_id = @telegram_bot_id
+    AND user_id = @user_id;
+
+-- name: ListTelegramBotsByOwner :many
+SELECT
+    tb.id,
+    tb.bot_id,
+    tb.username,
+    tb.first_name,
+    tb.last_name,
+    tb.encrypted_token,
+    tb.encryption_version,
+    tb.encryption_algorithm,
+    tb."role",
+    tb.last_error,
+    tb.last_checked_at,
+    tb.disabled_at,
+    tb.revoked_at,
+    tb.token_bound,
+    tb.token_algorithm_bound,
+    tb.wrapped_data_key,
+    tb.kek_id,
+    tb.timezone,
+    tb.created_at,
+    tb.updated_at
+FROM
+    telegram_bots tb
+    JOIN user_telegram_bots ub ON ub.telegram_bot_id = tb.id
+WHERE
+    ub.user_id = @user_id
+    AND ub."role" = 'owner'
+ORDER BY
+    tb.username;
+
+-- name: SetTelegramBotCheck :exec
+UPDATE
+    telegram_bots
+SET
+    last_error = @last_error,
+    last_checked_at = NOW()
+WHERE
+    id = @id;
+
+-- name: GetAdminTelegramBotID :one
+SELECT
+    bot_id
+FROM
+    telegram_bots
+WHERE
+    "role" = 'admin'
+    AND bot_id IS NOT NULL
+    AND disabled_at IS NULL
+    AND revoked_at IS NULL
+ORDER BY
+    created_at
+LIMIT
+    1;
@@ -0,0 +1,50 @@
+-- name: ListUserAttributes :many
+SELECT
+    "key",
+    "value"
+FROM
+    user_attributes
+WHERE
+    user_id = @user_id
+    AND telegram_bot_id = @telegram_bot_id;
+
+-- name: SetUserAttribute :exec
+INSERT INTO
+    user_attributes (user_id, telegram_bot_id, "key", "value")
+VALUES
+    (@user_id, @telegram_bot_id, @key, @value) ON CONFLICT (user_id, telegram_bot_id, "key") DO
+UPDATE
+SET
+    "value" = EXCLUDED."value",
+    updated_at = NOW();
+
+-- name: DeleteUserAttribute :exec
+DELETE FROM
+    user_attributes
+WHERE
+    user_id = @user_id
+    AND telegram_bot_id = @telegram_bot_id
+    AND "key" = @key;
+
+-- name: ListUserTags :many
+SELECT
+    tag
+FROM
+    user_tags
+WHERE
+    user_id = @user_id
+    AND telegram_bot_id = @telegram_bot_id;
+
+-- name: AddUserTag :exec
+INSERT INTO
+    user_tags (user_id, telegram_bot_id, tag)
+VALUES
+    (@user_id, @telegram_bot_id, @tag) ON CONFLICT DO NOTHING;
+
+-- name: RemoveUserTag :exec
+DELETE FROM
+    user_tags
+WHERE
+    user_id = @user_id
+    AND telegram_bot_id = @telegram_bot_id
+    AND tag = @tag;
\ No newline at end of file
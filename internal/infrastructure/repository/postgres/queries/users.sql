@@ -4,14 +4,20 @@ INSERT INTO
         telegram_id,
         username,
         first_name,
-        last_name
+        last_name,
+        language_code,
+        timezone,
+        timezone_source
     )
 VALUES
     (
         @telegram_id,
         @username,
         @first_name,
-        @last_name
+        @last_name,
+        @language_code,
+        @timezone,
+        @timezone_source
     ) RETURNING id,
     telegram_id,
     username,
@@ -19,7 +25,10 @@ VALUES
     last_name,
     created_at,
     is_active,
-    blocked_at;
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source;
 
 -- name: GetUserByID :one
 SELECT
@@ -30,7 +39,10 @@ SELECT
     last_name,
     created_at,
     is_active,
-    blocked_at
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
 FROM
     users
 WHERE
@@ -46,13 +58,39 @@ SELECT
     last_name,
     created_at,
     is_active,
-    blocked_at
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
 FROM
     users
 WHERE
     telegram_id = @telegram_id
     AND is_active = TRUE;
 
+-- name: GetUserByUsername :one
+SELECT
+    id,
+    telegram_id,
+    username,
+    first_name,
+    last_name,
+    created_at,
+    is_active,
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
+FROM
+    users
+WHERE
+    LOWER(username) = LOWER(@username::text)
+    AND is_active = TRUE
+ORDER BY
+    created_at DESC
+LIMIT
+    1;
+
 -- name: UpdateUser :one
 UPDATE
     users
@@ -60,7 +98,8 @@ SET
     telegram_id = @telegram_id,
     username = @username,
     first_name = @first_name,
-    last_name = @last_name
+    last_name = @last_name,
+    language_code = @language_code
 WHERE
     id = @id
     AND is_active = TRUE RETURNING id,
@@ -70,7 +109,19 @@ WHERE
     last_name,
     created_at,
     is_active,
-    blocked_at;
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source;
+
+-- name: SetUserTimezone :exec
+UPDATE
+    users
+SET
+    timezone = @timezone,
+    timezone_source = @timezone_source
+WHERE
+    id = @id;
 
 -- name: DeactivateUser :one
 UPDATE
@@ -97,7 +148,10 @@ SELECT
     last_name,
     created_at,
     is_active,
-    blocked_at
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
 FROM
     users
 WHERE
@@ -125,4 +179,144 @@ SELECT
         WHERE
             telegram_id = @telegram_id
             AND is_active = TRUE
+    );
+
+-- name: ListUsersBySegment :many
+SELECT
+    u.id,
+    u.telegram_id,
+    u.username,
+    u.first_name,
+    u.last_name,
+    u.created_at,
+    u.is_active,
+    u.blocked_at,
+    u.language_code,
+    u.timezone,
+    u.timezone_source
+FROM
+    users u
+WHERE
+    u.is_active = TRUE
+    AND EXISTS (
+        SELECT
+            1
+        FROM
+            script_progress p
+            JOIN scripts s ON s.id = p.script_id
+        WHERE
+            p.user_id = u.id
+            AND s.telegram_bot_id = @telegram_bot_id
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(@tags::text[]) AS want(tag)
+        WHERE
+            NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    user_tags t
+                WHERE
+                    t.user_id = u.id
+                    AND t.telegram_bot_id = @telegram_bot_id
+                    AND t.tag = want.tag
+            )
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            user_tags t
+        WHERE
+            t.user_id = u.id
+            AND t.telegram_bot_id = @telegram_bot_id
+            AND t.tag = ANY(@exclude_tags::text[])
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(@attribute_keys::text[], @attribute_values::text[]) AS want("key", "value")
+        WHERE
+            NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    user_attributes a
+                WHERE
+                    a.user_id = u.id
+                    AND a.telegram_bot_id = @telegram_bot_id
+                    AND a."key" = want."key"
+                    AND a."value" = want."value"
+            )
+    )
+ORDER BY
+    u.created_at
+LIMIT
+    @limit_val OFFSET @offset_val;
+
+-- name: CountUsersBySegment :one
+SELECT
+    COUNT(*)
+FROM
+    users u
+WHERE
+    u.is_active = TRUE
+    AND EXISTS (
+        SELECT
+            1
+        FROM
+            script_progress p
+            JOIN scripts s ON s.id = p.script_id
+        WHERE
+            p.user_id = u.id
+            AND s.telegram_bot_id = @telegram_bot_id
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(@tags::text[]) AS want(tag)
+        WHERE
+            NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    user_tags t
+                WHERE
+                    t.user_id = u.id
+                    AND t.telegram_bot_id = @telegram_bot_id
+                    AND t.tag = want.tag
+            )
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            user_tags t
+        WHERE
+            t.user_id = u.id
+            AND t.telegram_bot_id = @telegram_bot_id
+            AND t.tag = ANY(@exclude_tags::text[])
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(@attribute_keys::text[], @attribute_values::text[]) AS want("key", "value")
+        WHERE
+            NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    user_attributes a
+                WHERE
+                    a.user_id = u.id
+                    AND a.telegram_bot_id = @telegram_bot_id
+                    AND a."key" = want."key"
+                    AND a."value" = want."value"
+            )
     );
\ No newline at end of file
@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresScheduledStepRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewPostgresScheduledStepRepository(db *pgxpool.Pool) script.ScheduleRepository {
+	return &PostgresScheduledStepRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresScheduledStepRepository) Create(ctx context.Context, step *script.ScheduledStep) error {
+	row, err := r.queries.CreateScheduledStep(ctx, sqlc.CreateScheduledStepParams{
+		ScriptProgressID: uuidToPgtype(step.ProgressID),
+		StepID:           uuidToPgtype(step.StepID),
+		ExecuteAt:        timeToPgtype(step.ExecuteAt),
+		Kind:             scheduledStepKind(step.Kind),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled step: %w", err)
+	}
+
+	created, err := scheduledStepFromRow(sqlc.ClaimDueScheduledStepsRow(row))
+	if err != nil {
+		return err
+	}
+	*step = *created
+	return nil
+}
+
+func (r *PostgresScheduledStepRepository) ClaimDue(ctx context.Context, now, staleBefore time.Time, limit int) ([]*script.ScheduledStep, error) {
+	rows, err := r.queries.ClaimDueScheduledSteps(ctx, sqlc.ClaimDueScheduledStepsParams{
+		Now:         timeToPgtype(now),
+		StaleBefore: timeToPgtype(staleBefore),
+		LimitVal:    int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due scheduled steps: %w", err)
+	}
+	steps := make([]*script.ScheduledStep, 0, len(rows))
+	for _, row := range rows {
+		step, err := scheduledStepFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (r *PostgresScheduledStepRepository) MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	err := r.queries.MarkScheduledStepSent(ctx, sqlc.MarkScheduledStepSentParams{
+		ID:     uuidToPgtype(id),
+		SentAt: timeToPgtype(sentAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark scheduled step sent: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScheduledStepRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error {
+	err := r.queries.MarkScheduledStepFailed(ctx, sqlc.MarkScheduledStepFailedParams{
+		ID:        uuidToPgtype(id),
+		LastError: stringToPgtype(lastError),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark scheduled step failed: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScheduledStepRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	if err := r.queries.CancelScheduledStep(ctx, uuidToPgtype(id)); err != nil {
+		return fmt.Errorf("failed to cancel scheduled step: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScheduledStepRepository) Reschedule(ctx context.Context, id uuid.UUID, executeAt time.Time, lastError string) error {
+	err := r.queries.RescheduleScheduledStep(ctx, sqlc.RescheduleScheduledStepParams{
+		ID:        uuidToPgtype(id),
+		ExecuteAt: timeToPgtype(executeAt),
+		LastError: stringToPgtype(lastError),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule scheduled step: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScheduledStepRepository) Defer(ctx context.Context, id uuid.UUID, executeAt time.Time, reason string) error {
+	err := r.queries.DeferScheduledStep(ctx, sqlc.DeferScheduledStepParams{
+		ID:        uuidToPgtype(id),
+		ExecuteAt: timeToPgtype(executeAt),
+		LastError: stringToPgtype(reason),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to defer scheduled step: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScheduledStepRepository) CancelForProgress(ctx context.Context, progressID uuid.UUID) error {
+	if err := r.queries.CancelScheduledStepsForProgress(ctx, uuidToPgtype(progressID)); err != nil {
+		return fmt.Errorf("failed to cancel scheduled steps: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScheduledStepRepository) ListOverdue(ctx context.Context, dueBefore time.Time) ([]*script.Overdue, error) {
+	rows, err := r.queries.ListOverdueScheduledSteps(ctx, timeToPgtype(dueBefore))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overdue scheduled steps: %w", err)
+	}
+	overdue := make([]*script.Overdue, 0, len(rows))
+	for _, row := range rows {
+		botID, err := pgtypeToUUID(row.TelegramBotID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid overdue telegram bot ID: %w", err)
+		}
+		overdue = append(overdue, &script.Overdue{
+			TelegramBotID: botID,
+			Steps:         int(row.Overdue),
+			OldestDueAt:   pgtypeToTime(row.Oldest),
+		})
+	}
+	return overdue, nil
+}
+
+func scheduledStepFromRow(row sqlc.ClaimDueScheduledStepsRow) (*script.ScheduledStep, error) {
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduled step ID: %w", err)
+	}
+	progressID, err := pgtypeToUUID(row.ScriptProgressID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduled step progress ID: %w", err)
+	}
+	stepID, err := pgtypeToUUID(row.StepID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduled step step ID: %w", err)
+	}
+	return &script.ScheduledStep{
+		ID:         id,
+		ProgressID: progressID,
+		StepID:     stepID,
+		ExecuteAt:  pgtypeToTime(row.ExecuteAt),
+		Kind:       row.Kind,
+		Status:     row.Status,
+		Attempts:   int(row.Attempts),
+		LastError:  pgtypeToString(row.LastError),
+	}, nil
+}
+
+func scheduledStepKind(kind string) string {
+	if kind == "" {
+		return script.KindDeliver
+	}
+	return kind
+}
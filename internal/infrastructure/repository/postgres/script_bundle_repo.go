@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/bundle"
+	"github.com/VladKovDev/promo-bot/internal/domain/message"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresScriptBundleRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewPostgresScriptBundleRepository(db *pgxpool.Pool) bundle.Repository {
+	return &PostgresScriptBundleRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresScriptBundleRepository) Import(ctx context.Context, telegramBotID uuid.UUID, b *bundle.Bundle) (*script.Version, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	scriptID, err := importScript(ctx, q, telegramBotID, b.Script)
+	if err != nil {
+		return nil, err
+	}
+	version, err := importDraft(ctx, q, scriptID)
+	if err != nil {
+		return nil, err
+	}
+
+	messageIDs := make(map[uuid.UUID]uuid.UUID, len(b.Messages))
+	buttonIDs := make(map[uuid.UUID]uuid.UUID)
+	for _, m := range b.Messages {
+		id, err := q.CreateMessage(ctx, sqlc.CreateMessageParams{
+			Content:   stringToPgtype(m.Content),
+			ParseMode: stringToPgtype(m.ParseMode),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create message: %w", err)
+		}
+		messageIDs[m.ID] = uuid.UUID(id.Bytes)
+
+		for _, btn := range m.Buttons {
+			buttonID, err := q.CreateMessageButton(ctx, sqlc.CreateMessageButtonParams{
+				MessageID:    id,
+				Text:         btn.Text,
+				Url:          stringToPgtype(btn.URL),
+				SetAttribute: stringToPgtype(btn.SetAttribute),
+				SetValue:     stringToPgtype(btn.SetValue),
+				AddTag:       stringToPgtype(btn.AddTag),
+				TrackClicks:  btn.TrackClicks,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create message button: %w", err)
+			}
+			buttonIDs[btn.ID] = uuid.UUID(buttonID.Bytes)
+		}
+		for _, md := range m.Media {
+			kind := md.Kind
+			if kind == "" {
+				kind = message.MediaDocument
+			}
+			err := q.CreateMessageMedia(ctx, sqlc.CreateMessageMediaParams{
+				MessageID:  id,
+				StorageKey: md.Key,
+				Ext:        md.Ext,
+				Size:       md.Size,
+				MimeType:   md.MimeType,
+				Kind:       kind,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create message media: %w", err)
+			}
+		}
+	}
+
+	steps := b.ScriptSteps()
+	stepIDs := make(map[uuid.UUID]uuid.UUID, len(steps))
+	for _, st := range steps {
+		params := stepParams(st)
+		params.ScriptID = uuidToPgtype(scriptID)
+		params.ScriptVersionID = uuidToPgtype(version.ID)
+		params.MessageID = uuidToPgtype(messageIDs[st.MessageID])
+		id, err := q.CreateScriptStep(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create script step: %w", err)
+		}
+		stepIDs[st.ID] = uuid.UUID(id.Bytes)
+
+		for _, v := range st.Variants {
+			err := q.CreateScriptStepVariant(ctx, sqlc.CreateScriptStepVariantParams{
+				StepID:    id,
+				MessageID: uuidToPgtype(messageIDs[v.MessageID]),
+				Name:      v.Name,
+				Weight:    int32(v.Weight),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create script step variant: %w", err)
+			}
+		}
+	}
+	for _, st := range steps {
+		if st.Wait == nil || st.Wait.FallbackStepID == nil {
+			continue
+		}
+		err := q.SetScriptStepFallback(ctx, sqlc.SetScriptStepFallbackParams{
+			FallbackStepID: uuidToPgtype(stepIDs[*st.Wait.FallbackStepID]),
+			ID:             uuidToPgtype(stepIDs[st.ID]),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to set script step fallback: %w", err)
+		}
+	}
+
+	for _, t := range b.ScriptTransitions() {
+		remapped := t.Remap(stepIDs, buttonIDs)
+		err := q.CreateScriptTransition(ctx, sqlc.CreateScriptTransitionParams{
+			ScriptID:        uuidToPgtype(scriptID),
+			ScriptVersionID: uuidToPgtype(version.ID),
+			FromStepID:      uuidToPgtype(remapped.FromStepID),
+			ToStepID:        uuidToPgtype(remapped.ToStepID),
+			Condition:       remapped.Condition,
+			Value:           stringToPgtype(remapped.Value),
+			Priority:        int32(remapped.Priority),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create script transition: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit script bundle: %w", err)
+	}
+	return version, nil
+}
+
+// importScript returns the ID of the bot's script with the name, creating
+// the script when there is none.
+func importScript(ctx context.Context, q *sqlc.Queries, telegramBotID uuid.UUID, name string) (uuid.UUID, error) {
+	row, err := q.GetScriptByName(ctx, sqlc.GetScriptByNameParams{
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Name:          name,
+	})
+	if err == nil {
+		return uuid.UUID(row.ID.Bytes), nil
+	}
+	if !errors.Is(notFound(err), app_errors.ErrNotFound) {
+		return uuid.Nil, fmt.Errorf("failed to get script by name: %w", err)
+	}
+	id, err := q.CreateScript(ctx, sqlc.CreateScriptParams{
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Name:          name,
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create script: %w", err)
+	}
+	return uuid.UUID(id.Bytes), nil
+}
+
+// importDraft returns the script's draft emptied of steps and transitions,
+// or a new empty draft.
+func importDraft(ctx context.Context, q *sqlc.Queries, scriptID uuid.UUID) (*script.Version, error) {
+	row, err := q.GetDraftScriptVersion(ctx, uuidToPgtype(scriptID))
+	if err == nil {
+		if err := q.DeleteScriptTransitions(ctx, row.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete script transitions: %w", err)
+		}
+		if err := q.DeleteScriptVersionSteps(ctx, row.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete script steps: %w", err)
+		}
+		return versionFromRow(row)
+	}
+	if !errors.Is(notFound(err), app_errors.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get draft script version: %w", err)
+	}
+
+	versions, err := q.ListScriptVersions(ctx, uuidToPgtype(scriptID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script versions: %w", err)
+	}
+	number := int32(1)
+	if len(versions) > 0 {
+		number = versions[0].Number + 1
+	}
+	created, err := q.CreateScriptVersion(ctx, sqlc.CreateScriptVersionParams{
+		ScriptID: uuidToPgtype(scriptID),
+		Number:   number,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create script version: %w", err)
+	}
+	return versionFromRow(sqlc.ScriptVersion{
+		ID:        created.ID,
+		ScriptID:  uuidToPgtype(scriptID),
+		Number:    number,
+		CreatedAt: created.CreatedAt,
+	})
+}
+
+// stepParams converts the settings of a step to insert parameters; the
+// script, version and message IDs are left to the caller.
+func stepParams(st *script.Step) sqlc.CreateScriptStepParams {
+	timing := int32(st.Timing.Seconds())
+	params := sqlc.CreateScriptStepParams{
+		Order:             int32(st.Order),
+		Channel:           st.Channel,
+		Timing:            &timing,
+		SkipOnError:       st.SkipOnError,
+		SaveAs:            stringToPgtype(st.SaveAs),
+		GrantsGroupAccess: st.GrantsGroupAccess,
+	}
+	if params.Channel == "" {
+		params.Channel = script.ChannelPrivate
+	}
+	if st.TargetChatID != 0 {
+		params.TargetChatID = &st.TargetChatID
+	}
+	if st.TargetThreadID != 0 {
+		thread := int32(st.TargetThreadID)
+		params.TargetThreadID = &thread
+	}
+	if st.Wait != nil {
+		params.WaitFor = &st.Wait.For
+		params.WaitKeywords = st.Wait.Keywords
+		if st.Wait.Timeout > 0 {
+			timeout := int32(st.Wait.Timeout.Seconds())
+			params.WaitTimeout = &timeout
+		}
+		if st.Wait.ChannelID != 0 {
+			params.WaitChannelID = &st.Wait.ChannelID
+		}
+	}
+	return params
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresScriptDeepLinkRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewPostgresScriptDeepLinkRepository(db *pgxpool.Pool) script.DeepLinkRepository {
+	return &PostgresScriptDeepLinkRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresScriptDeepLinkRepository) Get(ctx context.Context, telegramBotID uuid.UUID, payload string) (*script.DeepLink, error) {
+	row, err := r.queries.GetScriptDeepLink(ctx, sqlc.GetScriptDeepLinkParams{
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Payload:       payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get script deep link: %w", notFound(err))
+	}
+	return deepLinkFromRow(sqlc.ListScriptDeepLinksRow(row))
+}
+
+func (r *PostgresScriptDeepLinkRepository) List(ctx context.Context, telegramBotID uuid.UUID) ([]*script.DeepLink, error) {
+	rows, err := r.queries.ListScriptDeepLinks(ctx, uuidToPgtype(telegramBotID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script deep links: %w", err)
+	}
+	links := make([]*script.DeepLink, 0, len(rows))
+	for _, row := range rows {
+		link, err := deepLinkFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func (r *PostgresScriptDeepLinkRepository) Save(ctx context.Context, link *script.DeepLink) error {
+	rowID, err := r.queries.SaveScriptDeepLink(ctx, sqlc.SaveScriptDeepLinkParams{
+		TelegramBotID: uuidToPgtype(link.TelegramBotID),
+		Payload:       link.Payload,
+		ScriptID:      uuidToPgtype(link.ScriptID),
+		Source:        stringToPgtype(link.Source),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save script deep link: %w", err)
+	}
+	id, err := pgtypeToUUID(rowID)
+	if err != nil {
+		return fmt.Errorf("invalid script deep link ID: %w", err)
+	}
+	link.ID = id
+	return nil
+}
+
+func (r *PostgresScriptDeepLinkRepository) Delete(ctx context.Context, telegramBotID uuid.UUID, payload string) error {
+	n, err := r.queries.DeleteScriptDeepLink(ctx, sqlc.DeleteScriptDeepLinkParams{
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Payload:       payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete script deep link: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("failed to delete script deep link: %w", app_errors.ErrNotFound)
+	}
+	return nil
+}
+
+func deepLinkFromRow(row sqlc.ListScriptDeepLinksRow) (*script.DeepLink, error) {
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script deep link ID: %w", err)
+	}
+	return &script.DeepLink{
+		ID:            id,
+		TelegramBotID: uuid.UUID(row.TelegramBotID.Bytes),
+		Payload:       row.Payload,
+		ScriptID:      uuid.UUID(row.ScriptID.Bytes),
+		Source:        pgtypeToString(row.Source),
+	}, nil
+}
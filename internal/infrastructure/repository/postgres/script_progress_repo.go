@@ -0,0 +1,312 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresScriptProgressRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewPostgresScriptProgressRepository(db *pgxpool.Pool) script.ProgressRepository {
+	return &PostgresScriptProgressRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresScriptProgressRepository) Create(ctx context.Context, progress *script.Progress) error {
+	row, err := r.queries.CreateScriptProgress(ctx, sqlc.CreateScriptProgressParams{
+		UserID:          uuidToPgtype(progress.UserID),
+		ScriptID:        uuidToPgtype(progress.ScriptID),
+		CurrentStepID:   uuidPtrToPgtype(progress.CurrentStepID),
+		Status:          progress.Status,
+		StepStartedAt:   timePtrToPgtype(progress.StepStartedAt),
+		StartedAt:       timeToPgtype(progress.StartedAt),
+		Source:          stringToPgtype(progress.Source),
+		ScriptVersionID: uuidToPgtype(progress.VersionID),
+		Preview:         progress.Preview,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create script progress: %w", err)
+	}
+
+	created, err := progressFromRow(row)
+	if err != nil {
+		return err
+	}
+	*progress = *created
+	return nil
+}
+
+func (r *PostgresScriptProgressRepository) GetByID(ctx context.Context, id uuid.UUID) (*script.Progress, error) {
+	row, err := r.queries.GetScriptProgressByID(ctx, uuidToPgtype(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get script progress by id: %w", notFound(err))
+	}
+	return progressFromRow(row)
+}
+
+func (r *PostgresScriptProgressRepository) GetActive(ctx context.Context, userID, scriptID uuid.UUID) (*script.Progress, error) {
+	row, err := r.queries.GetActiveScriptProgress(ctx, sqlc.GetActiveScriptProgressParams{
+		UserID:   uuidToPgtype(userID),
+		ScriptID: uuidToPgtype(scriptID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active script progress: %w", notFound(err))
+	}
+	return progressFromRow(row)
+}
+
+func (r *PostgresScriptProgressRepository) GetWaiting(ctx context.Context, userID, telegramBotID uuid.UUID) (*script.Progress, error) {
+	row, err := r.queries.GetWaitingScriptProgressForBot(ctx, sqlc.GetWaitingScriptProgressForBotParams{
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(telegramBotID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting script progress: %w", notFound(err))
+	}
+	return progressFromRow(sqlc.ScriptProgress(row))
+}
+
+func (r *PostgresScriptProgressRepository) Update(ctx context.Context, progress *script.Progress) error {
+	err := r.queries.UpdateScriptProgress(ctx, sqlc.UpdateScriptProgressParams{
+		ID:            uuidToPgtype(progress.ID),
+		CurrentStepID: uuidPtrToPgtype(progress.CurrentStepID),
+		Status:        progress.Status,
+		StepStartedAt: timePtrToPgtype(progress.StepStartedAt),
+		FinishedAt:    timePtrToPgtype(progress.FinishedAt),
+		WaitingFor:    stringToPgtype(progress.WaitingFor),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update script progress: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptProgressRepository) Resume(ctx context.Context, id uuid.UUID) (bool, error) {
+	n, err := r.queries.ResumeScriptProgress(ctx, uuidToPgtype(id))
+	if err != nil {
+		return false, fmt.Errorf("failed to resume script progress: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PostgresScriptProgressRepository) CreateDelivery(ctx context.Context, delivery *script.Delivery) error {
+	err := r.queries.CreateScriptProgressDelivery(ctx, sqlc.CreateScriptProgressDeliveryParams{
+		ID:                uuidToPgtype(delivery.ID),
+		MessageID:         uuidToPgtype(delivery.MessageID),
+		StepID:            uuidToPgtype(delivery.StepID),
+		ScriptProgressID:  uuidToPgtype(delivery.ProgressID),
+		SentAt:            timeToPgtype(delivery.SentAt),
+		Channel:           delivery.Channel,
+		Snapshot:          delivery.Snapshot,
+		TelegramMessageID: delivery.TelegramMessageID,
+		VariantID:         uuidPtrToPgtype(delivery.VariantID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create script progress delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptProgressRepository) CreateInput(ctx context.Context, progressID, stepID uuid.UUID, input script.Input) error {
+	value := input.Text
+	switch input.Type {
+	case script.InputContact:
+		value = input.Phone
+	case script.InputSubscription:
+		value = strconv.FormatInt(input.ChannelID, 10)
+	}
+	var buttonID uuid.UUID
+	if input.Type == script.InputButton {
+		buttonID = input.ButtonID
+	}
+
+	err := r.queries.CreateScriptProgressInput(ctx, sqlc.CreateScriptProgressInputParams{
+		ScriptProgressID: uuidToPgtype(progressID),
+		StepID:           uuidToPgtype(stepID),
+		Type:             input.Type,
+		Value:            stringToPgtype(value),
+		ButtonID:         uuidToPgtype(buttonID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create script progress input: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptProgressRepository) ListAnswers(ctx context.Context, progressID uuid.UUID) ([]script.Answer, error) {
+	rows, err := r.queries.ListScriptProgressInputs(ctx, uuidToPgtype(progressID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script progress inputs: %w", err)
+	}
+	answers := make([]script.Answer, 0, len(rows))
+	for _, row := range rows {
+		if !row.StepID.Valid {
+			continue
+		}
+		input := script.Input{Type: row.Type}
+		switch row.Type {
+		case script.InputContact:
+			input.Phone = pgtypeToString(row.Value)
+		case script.InputButton:
+			input.ButtonID = uuid.UUID(row.ButtonID.Bytes)
+		case script.InputSubscription:
+			input.ChannelID, _ = strconv.ParseInt(pgtypeToString(row.Value), 10, 64)
+		default:
+			input.Text = pgtypeToString(row.Value)
+		}
+		answers = append(answers, script.Answer{StepID: uuid.UUID(row.StepID.Bytes), Input: input})
+	}
+	return answers, nil
+}
+
+func (r *PostgresScriptProgressRepository) ListDeliveredSteps(ctx context.Context, progressID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.queries.ListScriptProgressDeliveredSteps(ctx, uuidToPgtype(progressID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivered steps: %w", err)
+	}
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, uuid.UUID(row.Bytes))
+	}
+	return ids, nil
+}
+
+func (r *PostgresScriptProgressRepository) ClaimStepPost(ctx context.Context, stepID uuid.UUID, postedAt time.Time) (bool, error) {
+	n, err := r.queries.ClaimScriptStepPost(ctx, sqlc.ClaimScriptStepPostParams{
+		StepID:   uuidToPgtype(stepID),
+		PostedAt: timeToPgtype(postedAt),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim script step post: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PostgresScriptProgressRepository) GetStepPost(ctx context.Context, stepID uuid.UUID) (*script.StepPost, error) {
+	row, err := r.queries.GetScriptStepPost(ctx, uuidToPgtype(stepID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get script step post: %w", notFound(err))
+	}
+	return &script.StepPost{
+		StepID:            uuid.UUID(row.StepID.Bytes),
+		TelegramMessageID: row.TelegramMessageID,
+		PostedAt:          pgtypeToTime(row.PostedAt),
+	}, nil
+}
+
+func (r *PostgresScriptProgressRepository) SetStepPostMessage(ctx context.Context, stepID uuid.UUID, telegramMessageID string) error {
+	err := r.queries.SetScriptStepPostMessage(ctx, sqlc.SetScriptStepPostMessageParams{
+		TelegramMessageID: telegramMessageID,
+		StepID:            uuidToPgtype(stepID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set script step post message: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptProgressRepository) ReleaseStepPost(ctx context.Context, stepID uuid.UUID) error {
+	if err := r.queries.DeleteScriptStepPost(ctx, uuidToPgtype(stepID)); err != nil {
+		return fmt.Errorf("failed to release script step post: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptProgressRepository) IsButtonDelivered(ctx context.Context, buttonID, userID, telegramBotID uuid.UUID) (bool, error) {
+	delivered, err := r.queries.IsButtonDeliveredToUser(ctx, sqlc.IsButtonDeliveredToUserParams{
+		ButtonID:      uuidToPgtype(buttonID),
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(telegramBotID),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check button delivery: %w", err)
+	}
+	return delivered, nil
+}
+
+func (r *PostgresScriptProgressRepository) AddPathStep(ctx context.Context, progressID, stepID uuid.UUID, transitionID *uuid.UUID, enteredAt time.Time) error {
+	err := r.queries.CreateScriptProgressStep(ctx, sqlc.CreateScriptProgressStepParams{
+		ScriptProgressID: uuidToPgtype(progressID),
+		StepID:           uuidToPgtype(stepID),
+		TransitionID:     uuidPtrToPgtype(transitionID),
+		EnteredAt:        timeToPgtype(enteredAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record script progress step: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptProgressRepository) FinishPreviews(ctx context.Context, userID, scriptID uuid.UUID, finishedAt time.Time) error {
+	err := r.queries.FinishScriptPreviews(ctx, sqlc.FinishScriptPreviewsParams{
+		FinishedAt: timeToPgtype(finishedAt),
+		UserID:     uuidToPgtype(userID),
+		ScriptID:   uuidToPgtype(scriptID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to finish script previews: %w", err)
+	}
+	return nil
+}
+
+// funnelEnd stands in for an open end of the date range of a funnel filter.
+var funnelEnd = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (r *PostgresScriptProgressRepository) ListFunnelEntries(ctx context.Context, versionID uuid.UUID, filter script.FunnelFilter) ([]script.FunnelEntry, error) {
+	to := filter.To
+	if to.IsZero() {
+		to = funnelEnd
+	}
+	rows, err := r.queries.ListScriptFunnelEntries(ctx, sqlc.ListScriptFunnelEntriesParams{
+		ScriptVersionID: uuidToPgtype(versionID),
+		StartedFrom:     timeToPgtype(filter.From),
+		StartedTo:       timeToPgtype(to),
+		Source:          filter.Source,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script funnel entries: %w", err)
+	}
+	entries := make([]script.FunnelEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, script.FunnelEntry{
+			ProgressID: uuid.UUID(row.ScriptProgressID.Bytes),
+			StepID:     uuid.UUID(row.StepID.Bytes),
+			EnteredAt:  pgtypeToTime(row.EnteredAt),
+			Finished:   row.Status == script.ProgressFinished,
+			Sent:       row.Sent,
+			Clicked:    row.Clicked,
+		})
+	}
+	return entries, nil
+}
+
+func progressFromRow(row sqlc.ScriptProgress) (*script.Progress, error) {
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script progress ID: %w", err)
+	}
+	return &script.Progress{
+		ID:            id,
+		UserID:        uuid.UUID(row.UserID.Bytes),
+		ScriptID:      uuid.UUID(row.ScriptID.Bytes),
+		VersionID:     uuid.UUID(row.ScriptVersionID.Bytes),
+		CurrentStepID: pgtypeToUUIDPtr(row.CurrentStepID),
+		Status:        row.Status,
+		StepStartedAt: pgtypeToTimePtr(row.StepStartedAt),
+		StartedAt:     pgtypeToTime(row.StartedAt),
+		FinishedAt:    pgtypeToTimePtr(row.FinishedAt),
+		WaitingFor:    pgtypeToString(row.WaitingFor),
+		Source:        pgtypeToString(row.Source),
+		Preview:       row.Preview,
+	}, nil
+}
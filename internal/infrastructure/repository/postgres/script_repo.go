@@ -0,0 +1,328 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresScriptRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewPostgresScriptRepository(db *pgxpool.Pool) script.Repository {
+	return &PostgresScriptRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresScriptRepository) GetByID(ctx context.Context, id uuid.UUID) (*script.Script, error) {
+	row, err := r.queries.GetScriptByID(ctx, uuidToPgtype(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get script by id: %w", notFound(err))
+	}
+	return scriptFromRow(row)
+}
+
+func (r *PostgresScriptRepository) GetDefaultForBot(ctx context.Context, telegramBotID uuid.UUID) (*script.Script, error) {
+	row, err := r.queries.GetDefaultScriptForBot(ctx, uuidToPgtype(telegramBotID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default script for bot: %w", notFound(err))
+	}
+	return scriptFromRow(sqlc.GetScriptByIDRow(row))
+}
+
+func (r *PostgresScriptRepository) GetByName(ctx context.Context, telegramBotID uuid.UUID, name string) (*script.Script, error) {
+	row, err := r.queries.GetScriptByName(ctx, sqlc.GetScriptByNameParams{
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Name:          name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get script by name: %w", notFound(err))
+	}
+	return scriptFromRow(sqlc.GetScriptByIDRow(row))
+}
+
+func (r *PostgresScriptRepository) ListSteps(ctx context.Context, versionID uuid.UUID) ([]*script.Step, error) {
+	rows, err := r.queries.ListScriptSteps(ctx, uuidToPgtype(versionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script steps: %w", err)
+	}
+	variants, err := r.queries.ListScriptVersionVariants(ctx, uuidToPgtype(versionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script version variants: %w", err)
+	}
+	byStep := make(map[uuid.UUID][]script.Variant)
+	for _, row := range variants {
+		v := variantFromRow(sqlc.ListScriptStepVariantsRow(row))
+		byStep[v.StepID] = append(byStep[v.StepID], v)
+	}
+
+	steps := make([]*script.Step, 0, len(rows))
+	for _, row := range rows {
+		step, err := stepFromRow(sqlc.GetScriptStepByIDRow(row))
+		if err != nil {
+			return nil, err
+		}
+		step.Variants = byStep[step.ID]
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (r *PostgresScriptRepository) GetStep(ctx context.Context, id uuid.UUID) (*script.Step, error) {
+	row, err := r.queries.GetScriptStepByID(ctx, uuidToPgtype(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get script step by id: %w", notFound(err))
+	}
+	step, err := stepFromRow(row)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := r.queries.ListScriptStepVariants(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script step variants: %w", err)
+	}
+	for _, v := range variants {
+		step.Variants = append(step.Variants, variantFromRow(v))
+	}
+	return step, nil
+}
+
+func (r *PostgresScriptRepository) CreateStep(ctx context.Context, step *script.Step) error {
+	params := stepParams(step)
+	params.ScriptID = uuidToPgtype(step.ScriptID)
+	params.ScriptVersionID = uuidToPgtype(step.VersionID)
+	params.MessageID = uuidToPgtype(step.MessageID)
+	id, err := r.queries.CreateScriptStep(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to create script step: %w", err)
+	}
+	if step.ID, err = pgtypeToUUID(id); err != nil {
+		return fmt.Errorf("invalid script step ID: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptRepository) ReorderSteps(ctx context.Context, ids []uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	for i, id := range ids {
+		err := q.SetScriptStepOrder(ctx, sqlc.SetScriptStepOrderParams{
+			Order: int32(i + 1),
+			ID:    uuidToPgtype(id),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set script step order: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit script step order: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptRepository) DeleteStep(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	n, err := q.DeleteScriptStep(ctx, uuidToPgtype(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete script step: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("failed to delete script step: %w", app_errors.ErrNotFound)
+	}
+	if err := q.DeleteScriptStepTransitions(ctx, uuidToPgtype(id)); err != nil {
+		return fmt.Errorf("failed to delete script step transitions: %w", err)
+	}
+	if err := q.ClearScriptStepFallbacks(ctx, uuidToPgtype(id)); err != nil {
+		return fmt.Errorf("failed to clear script step fallbacks: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit script step deletion: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptRepository) SetPrivateGroup(ctx context.Context, id uuid.UUID, groupID *uuid.UUID) error {
+	err := r.queries.SetScriptPrivateGroup(ctx, sqlc.SetScriptPrivateGroupParams{
+		PrivateGroupID: uuidPtrToPgtype(groupID),
+		ID:             uuidToPgtype(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set script private group: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptRepository) ListTransitions(ctx context.Context, versionID uuid.UUID) ([]*script.Transition, error) {
+	rows, err := r.queries.ListScriptTransitions(ctx, uuidToPgtype(versionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script transitions: %w", err)
+	}
+	transitions := make([]*script.Transition, 0, len(rows))
+	for _, row := range rows {
+		id, err := pgtypeToUUID(row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid script transition ID: %w", err)
+		}
+		transitions = append(transitions, &script.Transition{
+			ID:         id,
+			ScriptID:   uuid.UUID(row.ScriptID.Bytes),
+			VersionID:  uuid.UUID(row.ScriptVersionID.Bytes),
+			FromStepID: uuid.UUID(row.FromStepID.Bytes),
+			ToStepID:   uuid.UUID(row.ToStepID.Bytes),
+			Condition:  row.Condition,
+			Value:      pgtypeToString(row.Value),
+			Priority:   int(row.Priority),
+		})
+	}
+	return transitions, nil
+}
+
+func (r *PostgresScriptRepository) ReplaceTransitions(ctx context.Context, versionID uuid.UUID, transitions []*script.Transition) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	if err := q.DeleteScriptTransitions(ctx, uuidToPgtype(versionID)); err != nil {
+		return fmt.Errorf("failed to delete script transitions: %w", err)
+	}
+	for _, t := range transitions {
+		err := q.CreateScriptTransition(ctx, sqlc.CreateScriptTransitionParams{
+			ScriptID:        uuidToPgtype(t.ScriptID),
+			ScriptVersionID: uuidToPgtype(versionID),
+			FromStepID:      uuidToPgtype(t.FromStepID),
+			ToStepID:        uuidToPgtype(t.ToStepID),
+			Condition:       t.Condition,
+			Value:           stringToPgtype(t.Value),
+			Priority:        int32(t.Priority),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create script transition: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit script transitions: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresScriptRepository) ListVariantStats(ctx context.Context, versionID uuid.UUID) ([]script.VariantStats, error) {
+	rows, err := r.queries.ListScriptVariantStats(ctx, uuidToPgtype(versionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script variant stats: %w", err)
+	}
+	stats := make([]script.VariantStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, script.VariantStats{
+			StepOrder: int(row.Order),
+			Name:      row.Name,
+			Weight:    int(row.Weight),
+			Delivered: row.Delivered,
+			Clicked:   row.Clicked,
+			Advanced:  row.Advanced,
+		})
+	}
+	return stats, nil
+}
+
+func scriptFromRow(row sqlc.GetScriptByIDRow) (*script.Script, error) {
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script ID: %w", err)
+	}
+	botID, err := pgtypeToUUID(row.TelegramBotID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script telegram bot ID: %w", err)
+	}
+	return &script.Script{
+		ID:                 id,
+		TelegramBotID:      botID,
+		Name:               row.Name,
+		IsActive:           row.IsActive,
+		PrivateGroupID:     pgtypeToUUIDPtr(row.PrivateGroupID),
+		PublishedVersionID: pgtypeToUUIDPtr(row.PublishedVersionID),
+	}, nil
+}
+
+func stepFromRow(row sqlc.GetScriptStepByIDRow) (*script.Step, error) {
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script step ID: %w", err)
+	}
+	scriptID, err := pgtypeToUUID(row.ScriptID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script step script ID: %w", err)
+	}
+	messageID, err := pgtypeToUUID(row.MessageID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script step message ID: %w", err)
+	}
+
+	var timing time.Duration
+	if row.Timing != nil {
+		timing = time.Duration(*row.Timing) * time.Second
+	}
+
+	step := &script.Step{
+		ID:                id,
+		ScriptID:          scriptID,
+		VersionID:         uuid.UUID(row.ScriptVersionID.Bytes),
+		MessageID:         messageID,
+		Order:             int(row.Order),
+		Channel:           row.Channel,
+		TargetChatID:      pgtypeToInt64(row.TargetChatID),
+		Timing:            timing,
+		SkipOnError:       row.SkipOnError,
+		SaveAs:            pgtypeToString(row.SaveAs),
+		GrantsGroupAccess: row.GrantsGroupAccess,
+	}
+	if row.TargetThreadID != nil {
+		step.TargetThreadID = int(*row.TargetThreadID)
+	}
+	if row.WaitFor != nil {
+		step.Wait = &script.Wait{
+			For:            *row.WaitFor,
+			Keywords:       row.WaitKeywords,
+			FallbackStepID: pgtypeToUUIDPtr(row.FallbackStepID),
+			ChannelID:      pgtypeToInt64(row.WaitChannelID),
+		}
+		if row.WaitTimeout != nil {
+			step.Wait.Timeout = time.Duration(*row.WaitTimeout) * time.Second
+		}
+	}
+	return step, nil
+}
+
+func variantFromRow(row sqlc.ListScriptStepVariantsRow) script.Variant {
+	return script.Variant{
+		ID:        uuid.UUID(row.ID.Bytes),
+		StepID:    uuid.UUID(row.StepID.Bytes),
+		MessageID: uuid.UUID(row.MessageID.Bytes),
+		Name:      row.Name,
+		Weight:    int(row.Weight),
+	}
+}
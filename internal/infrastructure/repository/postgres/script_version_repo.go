@@ -0,0 +1,324 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresScriptVersionRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewPostgresScriptVersionRepository(db *pgxpool.Pool) script.VersionRepository {
+	return &PostgresScriptVersionRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresScriptVersionRepository) GetByID(ctx context.Context, id uuid.UUID) (*script.Version, error) {
+	row, err := r.queries.GetScriptVersionByID(ctx, uuidToPgtype(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get script version by id: %w", notFound(err))
+	}
+	return versionFromRow(row)
+}
+
+func (r *PostgresScriptVersionRepository) GetDraft(ctx context.Context, scriptID uuid.UUID) (*script.Version, error) {
+	row, err := r.queries.GetDraftScriptVersion(ctx, uuidToPgtype(scriptID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft script version: %w", notFound(err))
+	}
+	return versionFromRow(row)
+}
+
+func (r *PostgresScriptVersionRepository) GetByNumber(ctx context.Context, scriptID uuid.UUID, number int) (*script.Version, error) {
+	row, err := r.queries.GetScriptVersionByNumber(ctx, sqlc.GetScriptVersionByNumberParams{
+		ScriptID: uuidToPgtype(scriptID),
+		Number:   int32(number),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get script version by number: %w", notFound(err))
+	}
+	return versionFromRow(row)
+}
+
+func (r *PostgresScriptVersionRepository) List(ctx context.Context, scriptID uuid.UUID) ([]*script.Version, error) {
+	rows, err := r.queries.ListScriptVersions(ctx, uuidToPgtype(scriptID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script versions: %w", err)
+	}
+	versions := make([]*script.Version, 0, len(rows))
+	for _, row := range rows {
+		v, err := versionFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (r *PostgresScriptVersionRepository) CreateDraft(ctx context.Context, scriptID uuid.UUID, from *uuid.UUID) (*script.Version, error) {
+	versions, err := r.List(ctx, scriptID)
+	if err != nil {
+		return nil, err
+	}
+	number := 1
+	if len(versions) > 0 {
+		number = versions[0].Number + 1
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	row, err := q.CreateScriptVersion(ctx, sqlc.CreateScriptVersionParams{
+		ScriptID: uuidToPgtype(scriptID),
+		Number:   int32(number),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create script version: %w", err)
+	}
+	version := &script.Version{
+		ID:        uuid.UUID(row.ID.Bytes),
+		ScriptID:  scriptID,
+		Number:    number,
+		CreatedAt: pgtypeToTime(row.CreatedAt),
+	}
+	if from != nil {
+		if err := copyVersion(ctx, q, uuidToPgtype(*from), row.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit script version: %w", err)
+	}
+	return version, nil
+}
+
+// copyVersion copies the steps, their variants and the transitions of
+// version from into version to. Messages are copied too, so editing the
+// draft never changes what a published version sends.
+func copyVersion(ctx context.Context, q *sqlc.Queries, from, to pgtype.UUID) error {
+	steps, err := q.ListScriptSteps(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to list script steps: %w", err)
+	}
+
+	messageIDs := make(map[uuid.UUID]uuid.UUID)
+	buttonIDs := make(map[uuid.UUID]uuid.UUID)
+	stepIDs := make(map[uuid.UUID]uuid.UUID, len(steps))
+	for _, st := range steps {
+		messageID := uuid.UUID(st.MessageID.Bytes)
+		if _, ok := messageIDs[messageID]; !ok {
+			copied, err := copyMessage(ctx, q, st.MessageID, buttonIDs)
+			if err != nil {
+				return err
+			}
+			messageIDs[messageID] = copied
+		}
+
+		id, err := q.CreateScriptStep(ctx, sqlc.CreateScriptStepParams{
+			ScriptID:          st.ScriptID,
+			ScriptVersionID:   to,
+			MessageID:         uuidToPgtype(messageIDs[messageID]),
+			Order:             st.Order,
+			Channel:           st.Channel,
+			TargetChatID:      st.TargetChatID,
+			TargetThreadID:    st.TargetThreadID,
+			Timing:            st.Timing,
+			SkipOnError:       st.SkipOnError,
+			WaitFor:           st.WaitFor,
+			WaitKeywords:      st.WaitKeywords,
+			WaitTimeout:       st.WaitTimeout,
+			WaitChannelID:     st.WaitChannelID,
+			SaveAs:            st.SaveAs,
+			GrantsGroupAccess: st.GrantsGroupAccess,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create script step: %w", err)
+		}
+		stepIDs[uuid.UUID(st.ID.Bytes)] = uuid.UUID(id.Bytes)
+	}
+
+	variants, err := q.ListScriptVersionVariants(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to list script version variants: %w", err)
+	}
+	for _, v := range variants {
+		messageID := uuid.UUID(v.MessageID.Bytes)
+		if _, ok := messageIDs[messageID]; !ok {
+			copied, err := copyMessage(ctx, q, v.MessageID, buttonIDs)
+			if err != nil {
+				return err
+			}
+			messageIDs[messageID] = copied
+		}
+		err := q.CreateScriptStepVariant(ctx, sqlc.CreateScriptStepVariantParams{
+			StepID:    uuidToPgtype(stepIDs[uuid.UUID(v.StepID.Bytes)]),
+			MessageID: uuidToPgtype(messageIDs[messageID]),
+			Name:      v.Name,
+			Weight:    v.Weight,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create script step variant: %w", err)
+		}
+	}
+
+	// fallbacks may point at later steps, so they are set once all steps exist
+	for _, st := range steps {
+		if !st.FallbackStepID.Valid {
+			continue
+		}
+		err := q.SetScriptStepFallback(ctx, sqlc.SetScriptStepFallbackParams{
+			FallbackStepID: uuidToPgtype(stepIDs[uuid.UUID(st.FallbackStepID.Bytes)]),
+			ID:             uuidToPgtype(stepIDs[uuid.UUID(st.ID.Bytes)]),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set script step fallback: %w", err)
+		}
+	}
+
+	transitions, err := q.ListScriptTransitions(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to list script transitions: %w", err)
+	}
+	for _, row := range transitions {
+		t := script.Transition{
+			FromStepID: uuid.UUID(row.FromStepID.Bytes),
+			ToStepID:   uuid.UUID(row.ToStepID.Bytes),
+			Condition:  row.Condition,
+			Value:      pgtypeToString(row.Value),
+		}
+		t = t.Remap(stepIDs, buttonIDs)
+		err := q.CreateScriptTransition(ctx, sqlc.CreateScriptTransitionParams{
+			ScriptID:        row.ScriptID,
+			ScriptVersionID: to,
+			FromStepID:      uuidToPgtype(t.FromStepID),
+			ToStepID:        uuidToPgtype(t.ToStepID),
+			Condition:       t.Condition,
+			Value:           stringToPgtype(t.Value),
+			Priority:        row.Priority,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create script transition: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyMessage copies the message with its buttons and media and records the
+// new button IDs in buttonIDs.
+func copyMessage(ctx context.Context, q *sqlc.Queries, id pgtype.UUID, buttonIDs map[uuid.UUID]uuid.UUID) (uuid.UUID, error) {
+	msg, err := q.GetMessageByID(ctx, id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get message by id: %w", notFound(err))
+	}
+	copied, err := q.CreateMessage(ctx, sqlc.CreateMessageParams{
+		Content:   msg.Content,
+		ParseMode: msg.ParseMode,
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create message: %w", err)
+	}
+
+	buttons, err := q.ListMessageButtons(ctx, id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to list message buttons: %w", err)
+	}
+	for _, b := range buttons {
+		buttonID, err := q.CreateMessageButton(ctx, sqlc.CreateMessageButtonParams{
+			MessageID:    copied,
+			Text:         b.Text,
+			Url:          b.Url,
+			SetAttribute: b.SetAttribute,
+			SetValue:     b.SetValue,
+			AddTag:       b.AddTag,
+			TrackClicks:  b.TrackClicks,
+		})
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to create message button: %w", err)
+		}
+		buttonIDs[uuid.UUID(b.ID.Bytes)] = uuid.UUID(buttonID.Bytes)
+	}
+
+	err = q.CopyMessageMedia(ctx, sqlc.CopyMessageMediaParams{
+		ToMessageID:   copied,
+		FromMessageID: id,
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to copy message media: %w", err)
+	}
+	return uuid.UUID(copied.Bytes), nil
+}
+
+func (r *PostgresScriptVersionRepository) Publish(ctx context.Context, version *script.Version, publishedAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	n, err := q.PublishScriptVersion(ctx, sqlc.PublishScriptVersionParams{
+		PublishedAt: timeToPgtype(publishedAt),
+		ID:          uuidToPgtype(version.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish script version: %w", err)
+	}
+	if n == 0 {
+		return script.ErrNoDraft
+	}
+	err = q.SetScriptPublishedVersion(ctx, sqlc.SetScriptPublishedVersionParams{
+		PublishedVersionID: uuidToPgtype(version.ID),
+		ID:                 uuidToPgtype(version.ScriptID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set script published version: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit script version: %w", err)
+	}
+	version.PublishedAt = &publishedAt
+	return nil
+}
+
+func (r *PostgresScriptVersionRepository) SetPublished(ctx context.Context, scriptID, versionID uuid.UUID) error {
+	err := r.queries.SetScriptPublishedVersion(ctx, sqlc.SetScriptPublishedVersionParams{
+		PublishedVersionID: uuidToPgtype(versionID),
+		ID:                 uuidToPgtype(scriptID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set script published version: %w", err)
+	}
+	return nil
+}
+
+func versionFromRow(row sqlc.ScriptVersion) (*script.Version, error) {
+	id, err := pgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script version ID: %w", err)
+	}
+	return &script.Version{
+		ID:          id,
+		ScriptID:    uuid.UUID(row.ScriptID.Bytes),
+		Number:      int(row.Number),
+		CreatedAt:   pgtypeToTime(row.CreatedAt),
+		PublishedAt: pgtypeToTimePtr(row.PublishedAt),
+	}, nil
+}
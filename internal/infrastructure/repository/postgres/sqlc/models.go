@@ -8,6 +8,28 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type GroupInvite struct {
+	ID               pgtype.UUID      `json:"id"`
+	PrivateGroupID   pgtype.UUID      `json:"private_group_id"`
+	UserID           pgtype.UUID      `json:"user_id"`
+	ScriptProgressID pgtype.UUID      `json:"script_progress_id"`
+	InviteLink       string           `json:"invite_link"`
+	Status           string           `json:"status"`
+	ExpiresAt        pgtype.Timestamp `json:"expires_at"`
+	JoinedAt         pgtype.Timestamp `json:"joined_at"`
+	RevokedAt        pgtype.Timestamp `json:"revoked_at"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+}
+
+type GroupJoinRequest struct {
+	ID             pgtype.UUID      `json:"id"`
+	PrivateGroupID pgtype.UUID      `json:"private_group_id"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	Status         string           `json:"status"`
+	RequestedAt    pgtype.Timestamp `json:"requested_at"`
+	DecidedAt      pgtype.Timestamp `json:"decided_at"`
+}
+
 type History struct {
 	ID          pgtype.UUID      `json:"id"`
 	EntityID    pgtype.UUID      `json:"entity_id"`
@@ -19,6 +41,31 @@ type History struct {
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 }
 
+type InboxMessage struct {
+	ID          pgtype.UUID      `json:"id"`
+	ThreadID    pgtype.UUID      `json:"thread_id"`
+	Direction   string           `json:"direction"`
+	Text        string           `json:"text"`
+	AdminUserID pgtype.UUID      `json:"admin_user_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type InboxRelay struct {
+	RelayBotID int64            `json:"relay_bot_id"`
+	ChatID     int64            `json:"chat_id"`
+	MessageID  int64            `json:"message_id"`
+	ThreadID   pgtype.UUID      `json:"thread_id"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type InboxThread struct {
+	ID            pgtype.UUID      `json:"id"`
+	TelegramBotID pgtype.UUID      `json:"telegram_bot_id"`
+	UserID        pgtype.UUID      `json:"user_id"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
 type Message struct {
 	ID        pgtype.UUID      `json:"id"`
 	Content   *string          `json:"content"`
@@ -26,16 +73,30 @@ type Message struct {
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 	DeletedAt pgtype.Timestamp `json:"deleted_at"`
 	NoScript  bool             `json:"no_script"`
+	ParseMode *string          `json:"parse_mode"`
 }
 
 type MessageButton struct {
-	ID        pgtype.UUID      `json:"id"`
-	MessageID pgtype.UUID      `json:"message_id"`
-	Text      string           `json:"text"`
-	Url       *string          `json:"url"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
-	UpdatedAt pgtype.Timestamp `json:"updated_at"`
-	DeletedAt pgtype.Timestamp `json:"deleted_at"`
+	ID           pgtype.UUID      `json:"id"`
+	MessageID    pgtype.UUID      `json:"message_id"`
+	Text         string           `json:"text"`
+	Url          *string          `json:"url"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+	DeletedAt    pgtype.Timestamp `json:"deleted_at"`
+	SetAttribute *string          `json:"set_attribute"`
+	SetValue     *string          `json:"set_value"`
+	AddTag       *string          `json:"add_tag"`
+	TrackClicks  bool             `json:"track_clicks"`
+}
+
+type MessageButtonClick struct {
+	ID         pgtype.UUID      `json:"id"`
+	ButtonID   pgtype.UUID      `json:"button_id"`
+	UserID     pgtype.UUID      `json:"user_id"`
+	ClickedAt  pgtype.Timestamp `json:"clicked_at"`
+	StepID     pgtype.UUID      `json:"step_id"`
+	DeliveryID pgtype.UUID      `json:"delivery_id"`
 }
 
 type MessageMedium struct {
@@ -48,6 +109,17 @@ type MessageMedium struct {
 	MimeType   string           `json:"mime_type"`
 	CreatedAt  pgtype.Timestamp `json:"created_at"`
 	DeletedAt  pgtype.Timestamp `json:"deleted_at"`
+	Kind       string           `json:"kind"`
+}
+
+type PrivateGroup struct {
+	ID                 pgtype.UUID      `json:"id"`
+	TelegramBotID      pgtype.UUID      `json:"telegram_bot_id"`
+	ChatID             int64            `json:"chat_id"`
+	Title              *string          `json:"title"`
+	CreatedAt          pgtype.Timestamp `json:"created_at"`
+	AccessTag          *string          `json:"access_tag"`
+	DeclineUnqualified bool             `json:"decline_unqualified"`
 }
 
 type ScheduledStep struct {
@@ -60,28 +132,44 @@ type ScheduledStep struct {
 	Attempts         int32            `json:"attempts"`
 	LastError        *string          `json:"last_error"`
 	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	StepID           pgtype.UUID      `json:"step_id"`
+	Kind             string           `json:"kind"`
 }
 
 type Script struct {
-	ID             pgtype.UUID      `json:"id"`
-	TelegramBotID  pgtype.UUID      `json:"telegram_bot_id"`
-	Name           string           `json:"name"`
-	IsActive       bool             `json:"is_active"`
-	PrivateGroupID pgtype.UUID      `json:"private_group_id"`
-	CreatedAt      pgtype.Timestamp `json:"created_at"`
-	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
-	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+	ID                 pgtype.UUID      `json:"id"`
+	TelegramBotID      pgtype.UUID      `json:"telegram_bot_id"`
+	Name               string           `json:"name"`
+	IsActive           bool             `json:"is_active"`
+	PrivateGroupID     pgtype.UUID      `json:"private_group_id"`
+	CreatedAt          pgtype.Timestamp `json:"created_at"`
+	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+	DeletedAt          pgtype.Timestamp `json:"deleted_at"`
+	PublishedVersionID pgtype.UUID      `json:"published_version_id"`
 }
 
-type ScriptProgress struct {
+type ScriptDeepLink struct {
 	ID            pgtype.UUID      `json:"id"`
-	UserID        pgtype.UUID      `json:"user_id"`
+	TelegramBotID pgtype.UUID      `json:"telegram_bot_id"`
+	Payload       string           `json:"payload"`
 	ScriptID      pgtype.UUID      `json:"script_id"`
-	CurrentStepID pgtype.UUID      `json:"current_step_id"`
-	Status        string           `json:"status"`
-	StepStartedAt pgtype.Timestamp `json:"step_started_at"`
-	StartedAt     pgtype.Timestamp `json:"started_at"`
-	FinishedAt    pgtype.Timestamp `json:"finished_at"`
+	Source        *string          `json:"source"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type ScriptProgress struct {
+	ID              pgtype.UUID      `json:"id"`
+	UserID          pgtype.UUID      `json:"user_id"`
+	ScriptID        pgtype.UUID      `json:"script_id"`
+	CurrentStepID   pgtype.UUID      `json:"current_step_id"`
+	Status          string           `json:"status"`
+	StepStartedAt   pgtype.Timestamp `json:"step_started_at"`
+	StartedAt       pgtype.Timestamp `json:"started_at"`
+	FinishedAt      pgtype.Timestamp `json:"finished_at"`
+	WaitingFor      *string          `json:"waiting_for"`
+	Source          *string          `json:"source"`
+	ScriptVersionID pgtype.UUID      `json:"script_version_id"`
+	Preview         bool             `json:"preview"`
 }
 
 type ScriptProgressDelivery struct {
@@ -93,47 +181,158 @@ type ScriptProgressDelivery struct {
 	Channel           string           `json:"channel"`
 	Snapshot          []byte           `json:"snapshot"`
 	TelegramMessageID string           `json:"telegram_message_id"`
+	VariantID         pgtype.UUID      `json:"variant_id"`
+}
+
+type ScriptProgressInput struct {
+	ID               pgtype.UUID      `json:"id"`
+	ScriptProgressID pgtype.UUID      `json:"script_progress_id"`
+	StepID           pgtype.UUID      `json:"step_id"`
+	Type             string           `json:"type"`
+	Value            *string          `json:"value"`
+	ButtonID         pgtype.UUID      `json:"button_id"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+}
+
+type ScriptProgressStep struct {
+	ID               pgtype.UUID      `json:"id"`
+	ScriptProgressID pgtype.UUID      `json:"script_progress_id"`
+	StepID           pgtype.UUID      `json:"step_id"`
+	TransitionID     pgtype.UUID      `json:"transition_id"`
+	EnteredAt        pgtype.Timestamp `json:"entered_at"`
 }
 
 type ScriptStep struct {
+	ID                pgtype.UUID      `json:"id"`
+	ScriptID          pgtype.UUID      `json:"script_id"`
+	MessageID         pgtype.UUID      `json:"message_id"`
+	Order             int32            `json:"order"`
+	Channel           string           `json:"channel"`
+	Timing            *int32           `json:"timing"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+	DeletedAt         pgtype.Timestamp `json:"deleted_at"`
+	SkipOnError       bool             `json:"skip_on_error"`
+	WaitFor           *string          `json:"wait_for"`
+	WaitKeywords      []string         `json:"wait_keywords"`
+	WaitTimeout       *int32           `json:"wait_timeout"`
+	FallbackStepID    pgtype.UUID      `json:"fallback_step_id"`
+	SaveAs            *string          `json:"save_as"`
+	GrantsGroupAccess bool             `json:"grants_group_access"`
+	WaitChannelID     *int64           `json:"wait_channel_id"`
+	TargetChatID      *int64           `json:"target_chat_id"`
+	TargetThreadID    *int32           `json:"target_thread_id"`
+	ScriptVersionID   pgtype.UUID      `json:"script_version_id"`
+}
+
+type ScriptStepPost struct {
+	StepID            pgtype.UUID      `json:"step_id"`
+	TelegramMessageID string           `json:"telegram_message_id"`
+	PostedAt          pgtype.Timestamp `json:"posted_at"`
+}
+
+type ScriptStepVariant struct {
+	ID        pgtype.UUID      `json:"id"`
+	StepID    pgtype.UUID      `json:"step_id"`
+	MessageID pgtype.UUID      `json:"message_id"`
+	Name      string           `json:"name"`
+	Weight    int32            `json:"weight"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type ScriptTransition struct {
+	ID              pgtype.UUID      `json:"id"`
+	ScriptID        pgtype.UUID      `json:"script_id"`
+	FromStepID      pgtype.UUID      `json:"from_step_id"`
+	ToStepID        pgtype.UUID      `json:"to_step_id"`
+	Condition       string           `json:"condition"`
+	Value           *string          `json:"value"`
+	Priority        int32            `json:"priority"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	ScriptVersionID pgtype.UUID      `json:"script_version_id"`
+}
+
+type ScriptVersion struct {
 	ID          pgtype.UUID      `json:"id"`
 	ScriptID    pgtype.UUID      `json:"script_id"`
-	MessageID   pgtype.UUID      `json:"message_id"`
-	Order       int32            `json:"order"`
-	Channel     string           `json:"channel"`
-	Timing      *int32           `json:"timing"`
+	Number      int32            `json:"number"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
-	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
-	SkipOnError bool             `json:"skip_on_error"`
+	PublishedAt pgtype.Timestamp `json:"published_at"`
 }
 
 type TelegramBot struct {
-	ID                pgtype.UUID      `json:"id"`
-	BotID             *int64           `json:"bot_id"`
-	Username          string           `json:"username"`
-	FirstName         *string          `json:"first_name"`
-	LastName          *string          `json:"last_name"`
-	Role              string           `json:"role"`
-	EncryptedToken    []byte           `json:"encrypted_token"`
-	EncryptionVersion int32            `json:"encryption_version"`
-	LastError         *string          `json:"last_error"`
-	LastCheckedAt     pgtype.Timestamp `json:"last_checked_at"`
-	DisabledAt        pgtype.Timestamp `json:"disabled_at"`
-	RevokedAt         pgtype.Timestamp `json:"revoked_at"`
-	CreatedAt         pgtype.Timestamp `json:"created_at"`
-	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	Role                string           `json:"role"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
+	TokenBound          bool             `json:"token_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Timezone            string           `json:"timezone"`
+	SupportChatID       *int64           `json:"support_chat_id"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+}
+
+type TelegramBotAlert struct {
+	ID            pgtype.UUID      `json:"id"`
+	TelegramBotID pgtype.UUID      `json:"telegram_bot_id"`
+	Kind          string           `json:"kind"`
+	Text          string           `json:"text"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type TelegramBotAlertMute struct {
+	UserID        pgtype.UUID      `json:"user_id"`
+	TelegramBotID pgtype.UUID      `json:"telegram_bot_id"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type TelegramBotQuietHour struct {
+	ID            pgtype.UUID      `json:"id"`
+	TelegramBotID pgtype.UUID      `json:"telegram_bot_id"`
+	StartMinute   int32            `json:"start_minute"`
+	EndMinute     int32            `json:"end_minute"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
 }
 
 type User struct {
-	ID         pgtype.UUID      `json:"id"`
-	TelegramID *int64           `json:"telegram_id"`
-	Username   *string          `json:"username"`
-	FirstName  *string          `json:"first_name"`
-	LastName   *string          `json:"last_name"`
-	CreatedAt  pgtype.Timestamp `json:"created_at"`
-	IsActive   bool             `json:"is_active"`
-	BlockedAt  pgtype.Timestamp `json:"blocked_at"`
+	ID             pgtype.UUID      `json:"id"`
+	TelegramID     *int64           `json:"telegram_id"`
+	Username       *string          `json:"username"`
+	FirstName      *string          `json:"first_name"`
+	LastName       *string          `json:"last_name"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	IsActive       bool             `json:"is_active"`
+	BlockedAt      pgtype.Timestamp `json:"blocked_at"`
+	LanguageCode   *string          `json:"language_code"`
+	Timezone       *string          `json:"timezone"`
+	TimezoneSource *string          `json:"timezone_source"`
+}
+
+type UserAttribute struct {
+	UserID        pgtype.UUID      `json:"user_id"`
+	TelegramBotID pgtype.UUID      `json:"telegram_bot_id"`
+	Key           string           `json:"key"`
+	Value         string           `json:"value"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type UserTag struct {
+	UserID        pgtype.UUID      `json:"user_id"`
+	TelegramBotID pgtype.UUID      `json:"telegram_bot_id"`
+	Tag           string           `json:"tag"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
 }
 
 type UserTelegramBot struct {
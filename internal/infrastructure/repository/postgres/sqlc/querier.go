@@ -11,18 +11,135 @@ import (
 )
 
 type Querier interface {
+	AddUserTag(ctx context.Context, arg AddUserTagParams) error
+	CancelScheduledStep(ctx context.Context, id pgtype.UUID) error
+	CancelScheduledStepsForProgress(ctx context.Context, scriptProgressID pgtype.UUID) error
+	ClaimDueScheduledSteps(ctx context.Context, arg ClaimDueScheduledStepsParams) ([]ClaimDueScheduledStepsRow, error)
+	ClaimScriptStepPost(ctx context.Context, arg ClaimScriptStepPostParams) (int64, error)
+	ClearScriptStepFallbacks(ctx context.Context, fallbackStepID pgtype.UUID) error
+	CopyMessageMedia(ctx context.Context, arg CopyMessageMediaParams) error
+	CountGroupAccessDeliveries(ctx context.Context, arg CountGroupAccessDeliveriesParams) (int64, error)
+	CountPublishedMessageSteps(ctx context.Context, messageID pgtype.UUID) (int64, error)
+	CountTelegramBotAlertsOfKindSince(ctx context.Context, arg CountTelegramBotAlertsOfKindSinceParams) (int64, error)
+	CountTelegramBotAlertsSince(ctx context.Context, arg CountTelegramBotAlertsSinceParams) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
+	CountUsersBySegment(ctx context.Context, arg CountUsersBySegmentParams) (int64, error)
+	CreateGroupInvite(ctx context.Context, arg CreateGroupInviteParams) (pgtype.UUID, error)
+	CreateInboxMessage(ctx context.Context, arg CreateInboxMessageParams) error
+	CreateInboxRelay(ctx context.Context, arg CreateInboxRelayParams) error
+	CreateMessage(ctx context.Context, arg CreateMessageParams) (pgtype.UUID, error)
+	CreateMessageButton(ctx context.Context, arg CreateMessageButtonParams) (pgtype.UUID, error)
+	CreateMessageButtonClick(ctx context.Context, arg CreateMessageButtonClickParams) error
+	CreateMessageMedia(ctx context.Context, arg CreateMessageMediaParams) error
+	CreateScheduledStep(ctx context.Context, arg CreateScheduledStepParams) (CreateScheduledStepRow, error)
+	CreateScript(ctx context.Context, arg CreateScriptParams) (pgtype.UUID, error)
+	CreateScriptProgress(ctx context.Context, arg CreateScriptProgressParams) (ScriptProgress, error)
+	CreateScriptProgressDelivery(ctx context.Context, arg CreateScriptProgressDeliveryParams) error
+	CreateScriptProgressInput(ctx context.Context, arg CreateScriptProgressInputParams) error
+	CreateScriptProgressStep(ctx context.Context, arg CreateScriptProgressStepParams) error
+	CreateScriptStep(ctx context.Context, arg CreateScriptStepParams) (pgtype.UUID, error)
+	CreateScriptStepVariant(ctx context.Context, arg CreateScriptStepVariantParams) error
+	CreateScriptTransition(ctx context.Context, arg CreateScriptTransitionParams) error
+	CreateScriptVersion(ctx context.Context, arg CreateScriptVersionParams) (CreateScriptVersionRow, error)
 	CreateTelegramBot(ctx context.Context, arg CreateTelegramBotParams) (CreateTelegramBotRow, error)
+	CreateTelegramBotAlert(ctx context.Context, arg CreateTelegramBotAlertParams) error
+	CreateTelegramBotQuietHours(ctx context.Context, arg CreateTelegramBotQuietHoursParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeactivateUser(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error)
+	DecideGroupJoinRequest(ctx context.Context, arg DecideGroupJoinRequestParams) error
+	DeferScheduledStep(ctx context.Context, arg DeferScheduledStepParams) error
+	DeleteScriptDeepLink(ctx context.Context, arg DeleteScriptDeepLinkParams) (int64, error)
+	DeleteScriptStep(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteScriptStepPost(ctx context.Context, stepID pgtype.UUID) error
+	DeleteScriptStepTransitions(ctx context.Context, stepID pgtype.UUID) error
+	DeleteScriptTransitions(ctx context.Context, scriptVersionID pgtype.UUID) error
+	DeleteScriptVersionSteps(ctx context.Context, scriptVersionID pgtype.UUID) error
 	DeleteTelegramBot(ctx context.Context, id pgtype.UUID) error
+	DeleteTelegramBotQuietHours(ctx context.Context, telegramBotID pgtype.UUID) error
 	DeleteUser(ctx context.Context, id pgtype.UUID) error
+	DeleteUserAttribute(ctx context.Context, arg DeleteUserAttributeParams) error
+	FinishScriptPreviews(ctx context.Context, arg FinishScriptPreviewsParams) error
+	GetActiveScriptProgress(ctx context.Context, arg GetActiveScriptProgressParams) (ScriptProgress, error)
+	GetAdminTelegramBotID(ctx context.Context) (*int64, error)
+	GetDefaultScriptForBot(ctx context.Context, telegramBotID pgtype.UUID) (GetDefaultScriptForBotRow, error)
+	GetDraftScriptVersion(ctx context.Context, scriptID pgtype.UUID) (ScriptVersion, error)
+	GetInboxThreadByRelay(ctx context.Context, arg GetInboxThreadByRelayParams) (GetInboxThreadByRelayRow, error)
+	GetMessageButtonByID(ctx context.Context, id pgtype.UUID) (GetMessageButtonByIDRow, error)
+	GetMessageByID(ctx context.Context, id pgtype.UUID) (GetMessageByIDRow, error)
+	GetPendingGroupInvite(ctx context.Context, arg GetPendingGroupInviteParams) (GetPendingGroupInviteRow, error)
+	GetPrivateGroupByChatID(ctx context.Context, arg GetPrivateGroupByChatIDParams) (GetPrivateGroupByChatIDRow, error)
+	GetPrivateGroupByID(ctx context.Context, id pgtype.UUID) (GetPrivateGroupByIDRow, error)
+	GetScriptByID(ctx context.Context, id pgtype.UUID) (GetScriptByIDRow, error)
+	GetScriptByName(ctx context.Context, arg GetScriptByNameParams) (GetScriptByNameRow, error)
+	GetScriptDeepLink(ctx context.Context, arg GetScriptDeepLinkParams) (GetScriptDeepLinkRow, error)
+	GetScriptProgressByID(ctx context.Context, id pgtype.UUID) (ScriptProgress, error)
+	GetScriptStepByID(ctx context.Context, id pgtype.UUID) (GetScriptStepByIDRow, error)
+	GetScriptStepPost(ctx context.Context, stepID pgtype.UUID) (ScriptStepPost, error)
+	GetScriptVersionByID(ctx context.Context, id pgtype.UUID) (ScriptVersion, error)
+	GetScriptVersionByNumber(ctx context.Context, arg GetScriptVersionByNumberParams) (ScriptVersion, error)
 	GetTelegramBotByBotID(ctx context.Context, botID *int64) (GetTelegramBotByBotIDRow, error)
 	GetTelegramBotByID(ctx context.Context, id pgtype.UUID) (GetTelegramBotByIDRow, error)
+	GetTelegramBotByUsername(ctx context.Context, username string) (GetTelegramBotByUsernameRow, error)
+	GetTelegramBotMemberRole(ctx context.Context, arg GetTelegramBotMemberRoleParams) (string, error)
+	GetTelegramBotSupportChat(ctx context.Context, id pgtype.UUID) (*int64, error)
 	GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
 	GetUserByTelegramID(ctx context.Context, telegramID *int64) (User, error)
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetWaitingScriptProgressForBot(ctx context.Context, arg GetWaitingScriptProgressForBotParams) (GetWaitingScriptProgressForBotRow, error)
+	IsButtonDeliveredToUser(ctx context.Context, arg IsButtonDeliveredToUserParams) (bool, error)
+	JoinGroupInvites(ctx context.Context, arg JoinGroupInvitesParams) (int64, error)
+	ListExpiredGroupInvites(ctx context.Context, arg ListExpiredGroupInvitesParams) ([]ListExpiredGroupInvitesRow, error)
+	ListInboxRecipients(ctx context.Context, telegramBotID pgtype.UUID) ([]*int64, error)
+	ListMessageButtons(ctx context.Context, messageID pgtype.UUID) ([]ListMessageButtonsRow, error)
+	ListMessageMedia(ctx context.Context, messageID pgtype.UUID) ([]ListMessageMediaRow, error)
+	ListOverdueScheduledSteps(ctx context.Context, dueBefore pgtype.Timestamp) ([]ListOverdueScheduledStepsRow, error)
+	ListPendingGroupJoinRequests(ctx context.Context, arg ListPendingGroupJoinRequestsParams) ([]ListPendingGroupJoinRequestsRow, error)
+	ListScriptDeepLinks(ctx context.Context, telegramBotID pgtype.UUID) ([]ListScriptDeepLinksRow, error)
+	ListScriptFunnelEntries(ctx context.Context, arg ListScriptFunnelEntriesParams) ([]ListScriptFunnelEntriesRow, error)
+	ListScriptProgressDeliveredSteps(ctx context.Context, scriptProgressID pgtype.UUID) ([]pgtype.UUID, error)
+	ListScriptProgressInputs(ctx context.Context, scriptProgressID pgtype.UUID) ([]ListScriptProgressInputsRow, error)
+	ListScriptStepVariants(ctx context.Context, stepID pgtype.UUID) ([]ListScriptStepVariantsRow, error)
+	ListScriptSteps(ctx context.Context, scriptVersionID pgtype.UUID) ([]ListScriptStepsRow, error)
+	ListScriptTransitions(ctx context.Context, scriptVersionID pgtype.UUID) ([]ListScriptTransitionsRow, error)
+	ListScriptVariantStats(ctx context.Context, scriptVersionID pgtype.UUID) ([]ListScriptVariantStatsRow, error)
+	ListScriptVersionVariants(ctx context.Context, scriptVersionID pgtype.UUID) ([]ListScriptVersionVariantsRow, error)
+	ListScriptVersions(ctx context.Context, scriptID pgtype.UUID) ([]ScriptVersion, error)
+	ListTelegramBotAlertRecipients(ctx context.Context, telegramBotID pgtype.UUID) ([]*int64, error)
+	ListTelegramBotQuietHours(ctx context.Context, telegramBotID pgtype.UUID) ([]ListTelegramBotQuietHoursRow, error)
+	ListTelegramBotTokensForReseal(ctx context.Context, arg ListTelegramBotTokensForResealParams) ([]ListTelegramBotTokensForResealRow, error)
 	ListTelegramBots(ctx context.Context) ([]ListTelegramBotsRow, error)
+	ListTelegramBotsByOwner(ctx context.Context, userID pgtype.UUID) ([]ListTelegramBotsByOwnerRow, error)
+	ListUserAttributes(ctx context.Context, arg ListUserAttributesParams) ([]ListUserAttributesRow, error)
+	ListUserTags(ctx context.Context, arg ListUserTagsParams) ([]string, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListUsersBySegment(ctx context.Context, arg ListUsersBySegmentParams) ([]ListUsersBySegmentRow, error)
+	MarkScheduledStepFailed(ctx context.Context, arg MarkScheduledStepFailedParams) error
+	MarkScheduledStepSent(ctx context.Context, arg MarkScheduledStepSentParams) error
+	MuteTelegramBotAlerts(ctx context.Context, arg MuteTelegramBotAlertsParams) error
+	PublishScriptVersion(ctx context.Context, arg PublishScriptVersionParams) (int64, error)
+	RemoveUserTag(ctx context.Context, arg RemoveUserTagParams) error
+	RescheduleScheduledStep(ctx context.Context, arg RescheduleScheduledStepParams) error
+	ResealTelegramBotToken(ctx context.Context, arg ResealTelegramBotTokenParams) (int64, error)
+	ResumeScriptProgress(ctx context.Context, id pgtype.UUID) (int64, error)
+	RevokeGroupInvite(ctx context.Context, arg RevokeGroupInviteParams) error
+	SaveGroupJoinRequest(ctx context.Context, arg SaveGroupJoinRequestParams) error
+	SaveInboxThread(ctx context.Context, arg SaveInboxThreadParams) (pgtype.UUID, error)
+	SavePrivateGroup(ctx context.Context, arg SavePrivateGroupParams) (pgtype.UUID, error)
+	SaveScriptDeepLink(ctx context.Context, arg SaveScriptDeepLinkParams) (pgtype.UUID, error)
+	SetPrivateGroupPolicy(ctx context.Context, arg SetPrivateGroupPolicyParams) error
+	SetScriptPrivateGroup(ctx context.Context, arg SetScriptPrivateGroupParams) error
+	SetScriptPublishedVersion(ctx context.Context, arg SetScriptPublishedVersionParams) error
+	SetScriptStepFallback(ctx context.Context, arg SetScriptStepFallbackParams) error
+	SetScriptStepOrder(ctx context.Context, arg SetScriptStepOrderParams) error
+	SetScriptStepPostMessage(ctx context.Context, arg SetScriptStepPostMessageParams) error
+	SetTelegramBotCheck(ctx context.Context, arg SetTelegramBotCheckParams) error
+	SetTelegramBotSupportChat(ctx context.Context, arg SetTelegramBotSupportChatParams) error
+	SetUserAttribute(ctx context.Context, arg SetUserAttributeParams) error
+	SetUserTimezone(ctx context.Context, arg SetUserTimezoneParams) error
+	TelegramBotAlertsMuted(ctx context.Context, arg TelegramBotAlertsMutedParams) (bool, error)
+	UnmuteTelegramBotAlerts(ctx context.Context, arg UnmuteTelegramBotAlertsParams) error
+	UpdateMessage(ctx context.Context, arg UpdateMessageParams) (int64, error)
+	UpdateScriptProgress(ctx context.Context, arg UpdateScriptProgressParams) error
 	UpdateTelegramBot(ctx context.Context, arg UpdateTelegramBotParams) (UpdateTelegramBotRow, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UserExistsByTelegramID(ctx context.Context, telegramID *int64) (bool, error)
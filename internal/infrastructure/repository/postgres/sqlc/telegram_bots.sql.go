@@ -14,17 +14,24 @@ import (
 const createTelegramBot = `-- name: CreateTelegramBot :one
 INSERT INTO
     telegram_bots (
+        id,
         bot_id,
         username,
         first_name,
         last_name,
         encrypted_token,
         encryption_version,
+        encryption_algorithm,
         "role",
         last_error,
         last_checked_at,
         revoked_at,
-        disabled_at
+        disabled_at,
+        token_bound,
+        token_algorithm_bound,
+        wrapped_data_key,
+        kek_id,
+        timezone
     )
 VALUES
     (
@@ -38,7 +45,14 @@ VALUES
         $8,
         $9,
         $10,
-        $11
+        $11,
+        $12,
+        $13,
+        $14,
+        $15,
+        $16,
+        $17,
+        $18
     ) RETURNING id,
     bot_id,
     username,
@@ -46,59 +60,85 @@ VALUES
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at
 `
 
 type CreateTelegramBotParams struct {
-	BotID             *int64           `json:"bot_id"`
-	Username          string           `json:"username"`
-	FirstName         *string          `json:"first_name"`
-	LastName          *string          `json:"last_name"`
-	EncryptedToken    []byte           `json:"encrypted_token"`
-	EncryptionVersion int32            `json:"encryption_version"`
-	Role              string           `json:"role"`
-	LastError         *string          `json:"last_error"`
-	LastCheckedAt     pgtype.Timestamp `json:"last_checked_at"`
-	RevokedAt         pgtype.Timestamp `json:"revoked_at"`
-	DisabledAt        pgtype.Timestamp `json:"disabled_at"`
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
 }
 
 type CreateTelegramBotRow struct {
-	ID                pgtype.UUID      `json:"id"`
-	BotID             *int64           `json:"bot_id"`
-	Username          string           `json:"username"`
-	FirstName         *string          `json:"first_name"`
-	LastName          *string          `json:"last_name"`
-	EncryptedToken    []byte           `json:"encrypted_token"`
-	EncryptionVersion int32            `json:"encryption_version"`
-	Role              string           `json:"role"`
-	LastError         *string          `json:"last_error"`
-	LastCheckedAt     pgtype.Timestamp `json:"last_checked_at"`
-	DisabledAt        pgtype.Timestamp `json:"disabled_at"`
-	RevokedAt         pgtype.Timestamp `json:"revoked_at"`
-	CreatedAt         pgtype.Timestamp `json:"created_at"`
-	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
 }
 
 func (q *Queries) CreateTelegramBot(ctx context.Context, arg CreateTelegramBotParams) (CreateTelegramBotRow, error) {
 	row := q.db.QueryRow(ctx, createTelegramBot,
+		arg.ID,
 		arg.BotID,
 		arg.Username,
 		arg.FirstName,
 		arg.LastName,
 		arg.EncryptedToken,
 		arg.EncryptionVersion,
+		arg.EncryptionAlgorithm,
 		arg.Role,
 		arg.LastError,
 		arg.LastCheckedAt,
 		arg.RevokedAt,
 		arg.DisabledAt,
+		arg.TokenBound,
+		arg.TokenAlgorithmBound,
+		arg.WrappedDataKey,
+		arg.KekID,
+		arg.Timezone,
 	)
 	var i CreateTelegramBotRow
 	err := row.Scan(
@@ -109,11 +149,17 @@ func (q *Queries) CreateTelegramBot(ctx context.Context, arg CreateTelegramBotPa
 		&i.LastName,
 		&i.EncryptedToken,
 		&i.EncryptionVersion,
+		&i.EncryptionAlgorithm,
 		&i.Role,
 		&i.LastError,
 		&i.LastCheckedAt,
 		&i.DisabledAt,
 		&i.RevokedAt,
+		&i.TokenBound,
+		&i.TokenAlgorithmBound,
+		&i.WrappedDataKey,
+		&i.KekID,
+		&i.Timezone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -132,6 +178,29 @@ func (q *Queries) DeleteTelegramBot(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const getAdminTelegramBotID = `-- name: GetAdminTelegramBotID :one
+SELECT
+    bot_id
+FROM
+    telegram_bots
+WHERE
+    "role" = 'admin'
+    AND bot_id IS NOT NULL
+    AND disabled_at IS NULL
+    AND revoked_at IS NULL
+ORDER BY
+    created_at
+LIMIT
+    1
+`
+
+func (q *Queries) GetAdminTelegramBotID(ctx context.Context) (*int64, error) {
+	row := q.db.QueryRow(ctx, getAdminTelegramBotID)
+	var botID *int64
+	err := row.Scan(&botID)
+	return botID, err
+}
+
 const getTelegramBotByBotID = `-- name: GetTelegramBotByBotID :one
 SELECT
     id,
@@ -141,11 +210,17 @@ SELECT
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at
 FROM
@@ -155,20 +230,26 @@ WHERE
 `
 
 type GetTelegramBotByBotIDRow struct {
-	ID                pgtype.UUID      `json:"id"`
-	BotID             *int64           `json:"bot_id"`
-	Username          string           `json:"username"`
-	FirstName         *string          `json:"first_name"`
-	LastName          *string          `json:"last_name"`
-	EncryptedToken    []byte           `json:"encrypted_token"`
-	EncryptionVersion int32            `json:"encryption_version"`
-	Role              string           `json:"role"`
-	LastError         *string          `json:"last_error"`
-	LastCheckedAt     pgtype.Timestamp `json:"last_checked_at"`
-	DisabledAt        pgtype.Timestamp `json:"disabled_at"`
-	RevokedAt         pgtype.Timestamp `json:"revoked_at"`
-	CreatedAt         pgtype.Timestamp `json:"created_at"`
-	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
 }
 
 func (q *Queries) GetTelegramBotByBotID(ctx context.Context, botID *int64) (GetTelegramBotByBotIDRow, error) {
@@ -182,11 +263,17 @@ func (q *Queries) GetTelegramBotByBotID(ctx context.Context, botID *int64) (GetT
 		&i.LastName,
 		&i.EncryptedToken,
 		&i.EncryptionVersion,
+		&i.EncryptionAlgorithm,
 		&i.Role,
 		&i.LastError,
 		&i.LastCheckedAt,
 		&i.DisabledAt,
 		&i.RevokedAt,
+		&i.TokenBound,
+		&i.TokenAlgorithmBound,
+		&i.WrappedDataKey,
+		&i.KekID,
+		&i.Timezone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -202,11 +289,17 @@ SELECT
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at
 FROM
@@ -216,20 +309,26 @@ WHERE
 `
 
 type GetTelegramBotByIDRow struct {
-	ID                pgtype.UUID      `json:"id"`
-	BotID             *int64           `json:"bot_id"`
-	Username          string           `json:"username"`
-	FirstName         *string          `json:"first_name"`
-	LastName          *string          `json:"last_name"`
-	EncryptedToken    []byte           `json:"encrypted_token"`
-	EncryptionVersion int32            `json:"encryption_version"`
-	Role              string           `json:"role"`
-	LastError         *string          `json:"last_error"`
-	LastCheckedAt     pgtype.Timestamp `json:"last_checked_at"`
-	DisabledAt        pgtype.Timestamp `json:"disabled_at"`
-	RevokedAt         pgtype.Timestamp `json:"revoked_at"`
-	CreatedAt         pgtype.Timestamp `json:"created_at"`
-	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
 }
 
 func (q *Queries) GetTelegramBotByID(ctx context.Context, id pgtype.UUID) (GetTelegramBotByIDRow, error) {
@@ -243,17 +342,190 @@ func (q *Queries) GetTelegramBotByID(ctx context.Context, id pgtype.UUID) (GetTe
 		&i.LastName,
 		&i.EncryptedToken,
 		&i.EncryptionVersion,
+		&i.EncryptionAlgorithm,
 		&i.Role,
 		&i.LastError,
 		&i.LastCheckedAt,
 		&i.DisabledAt,
 		&i.RevokedAt,
+		&i.TokenBound,
+		&i.TokenAlgorithmBound,
+		&i.WrappedDataKey,
+		&i.KekID,
+		&i.Timezone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getTelegramBotByUsername = `-- name: GetTelegramBotByUsername :one
+SELECT
+    id,
+    bot_id,
+    username,
+    first_name,
+    last_name,
+    encrypted_token,
+    encryption_version,
+    encryption_algorithm,
+    "role",
+    last_error,
+    last_checked_at,
+    disabled_at,
+    revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
+    created_at,
+    updated_at
+FROM
+    telegram_bots
+WHERE
+    username = $1
+`
+
+type GetTelegramBotByUsernameRow struct {
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
+}
+
+func (q *Queries) GetTelegramBotByUsername(ctx context.Context, username string) (GetTelegramBotByUsernameRow, error) {
+	row := q.db.QueryRow(ctx, getTelegramBotByUsername, username)
+	var i GetTelegramBotByUsernameRow
+	err := row.Scan(
+		&i.ID,
+		&i.BotID,
+		&i.Username,
+		&i.FirstName,
+		&i.LastName,
+		&i.EncryptedToken,
+		&i.EncryptionVersion,
+		&i.EncryptionAlgorithm,
+		&i.Role,
+		&i.LastError,
+		&i.LastCheckedAt,
+		&i.DisabledAt,
+		&i.RevokedAt,
+		&i.TokenBound,
+		&i.TokenAlgorithmBound,
+		&i.WrappedDataKey,
+		&i.KekID,
+		&i.Timezone,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTelegramBotMemberRole = `-- name: GetTelegramBotMemberRole :one
+SELECT
+    "role"
+FROM
+    user_telegram_bots
+WHERE
+    telegram_bot_id = $1
+    AND user_id = $2
+`
+
+type GetTelegramBotMemberRoleParams struct {
+	TelegramBotID pgtype.UUID `json:"telegram_bot_id"`
+	UserID        pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetTelegramBotMemberRole(ctx context.Context, arg GetTelegramBotMemberRoleParams) (string, error) {
+	row := q.db.QueryRow(ctx, getTelegramBotMemberRole, arg.TelegramBotID, arg.UserID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const listTelegramBotTokensForReseal = `-- name: ListTelegramBotTokensForReseal :many
+SELECT
+    id,
+    encrypted_token,
+    encryption_version,
+    encryption_algorithm,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id
+FROM
+    telegram_bots
+WHERE
+    token_bound = FALSE
+    OR token_algorithm_bound = FALSE
+    OR encryption_version <> $1
+    OR encryption_algorithm <> $2
+    OR kek_id IS DISTINCT FROM $3
+`
+
+type ListTelegramBotTokensForResealParams struct {
+	CurrentVersion   int32   `json:"current_version"`
+	CurrentAlgorithm string  `json:"current_algorithm"`
+	KekID            *string `json:"kek_id"`
+}
+
+type ListTelegramBotTokensForResealRow struct {
+	ID                  pgtype.UUID `json:"id"`
+	EncryptedToken      []byte      `json:"encrypted_token"`
+	EncryptionVersion   int32       `json:"encryption_version"`
+	EncryptionAlgorithm string      `json:"encryption_algorithm"`
+	TokenBound          bool        `json:"token_bound"`
+	TokenAlgorithmBound bool        `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte      `json:"wrapped_data_key"`
+	KekID               *string     `json:"kek_id"`
+}
+
+func (q *Queries) ListTelegramBotTokensForReseal(ctx context.Context, arg ListTelegramBotTokensForResealParams) ([]ListTelegramBotTokensForResealRow, error) {
+	rows, err := q.db.Query(ctx, listTelegramBotTokensForReseal, arg.CurrentVersion, arg.CurrentAlgorithm, arg.KekID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTelegramBotTokensForResealRow{}
+	for rows.Next() {
+		var i ListTelegramBotTokensForResealRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EncryptedToken,
+			&i.EncryptionVersion,
+			&i.EncryptionAlgorithm,
+			&i.TokenBound,
+			&i.TokenAlgorithmBound,
+			&i.WrappedDataKey,
+			&i.KekID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTelegramBots = `-- name: ListTelegramBots :many
 SELECT
     id,
@@ -263,11 +535,17 @@ SELECT
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at
 FROM
@@ -277,20 +555,26 @@ ORDER BY
 `
 
 type ListTelegramBotsRow struct {
-	ID                pgtype.UUID      `json:"id"`
-	BotID             *int64           `json:"bot_id"`
-	Username          string           `json:"username"`
-	FirstName         *string          `json:"first_name"`
-	LastName          *string          `json:"last_name"`
-	EncryptedToken    []byte           `json:"encrypted_token"`
-	EncryptionVersion int32            `json:"encryption_version"`
-	Role              string           `json:"role"`
-	LastError         *string          `json:"last_error"`
-	LastCheckedAt     pgtype.Timestamp `json:"last_checked_at"`
-	DisabledAt        pgtype.Timestamp `json:"disabled_at"`
-	RevokedAt         pgtype.Timestamp `json:"revoked_at"`
-	CreatedAt         pgtype.Timestamp `json:"created_at"`
-	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
 }
 
 func (q *Queries) ListTelegramBots(ctx context.Context) ([]ListTelegramBotsRow, error) {
@@ -310,11 +594,17 @@ func (q *Queries) ListTelegramBots(ctx context.Context) ([]ListTelegramBotsRow,
 			&i.LastName,
 			&i.EncryptedToken,
 			&i.EncryptionVersion,
+			&i.EncryptionAlgorithm,
 			&i.Role,
 			&i.LastError,
 			&i.LastCheckedAt,
 			&i.DisabledAt,
 			&i.RevokedAt,
+			&i.TokenBound,
+			&i.TokenAlgorithmBound,
+			&i.WrappedDataKey,
+			&i.KekID,
+			&i.Timezone,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -328,6 +618,165 @@ func (q *Queries) ListTelegramBots(ctx context.Context) ([]ListTelegramBotsRow,
 	return items, nil
 }
 
+const listTelegramBotsByOwner = `-- name: ListTelegramBotsByOwner :many
+SELECT
+    tb.id,
+    tb.bot_id,
+    tb.username,
+    tb.first_name,
+    tb.last_name,
+    tb.encrypted_token,
+    tb.encryption_version,
+    tb.encryption_algorithm,
+    tb."role",
+    tb.last_error,
+    tb.last_checked_at,
+    tb.disabled_at,
+    tb.revoked_at,
+    tb.token_bound,
+    tb.token_algorithm_bound,
+    tb.wrapped_data_key,
+    tb.kek_id,
+    tb.timezone,
+    tb.created_at,
+    tb.updated_at
+FROM
+    telegram_bots tb
+    JOIN user_telegram_bots ub ON ub.telegram_bot_id = tb.id
+WHERE
+    ub.user_id = $1
+    AND ub."role" = 'owner'
+ORDER BY
+    tb.username
+`
+
+type ListTelegramBotsByOwnerRow struct {
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
+}
+
+func (q *Queries) ListTelegramBotsByOwner(ctx context.Context, userID pgtype.UUID) ([]ListTelegramBotsByOwnerRow, error) {
+	rows, err := q.db.Query(ctx, listTelegramBotsByOwner, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTelegramBotsByOwnerRow{}
+	for rows.Next() {
+		var i ListTelegramBotsByOwnerRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BotID,
+			&i.Username,
+			&i.FirstName,
+			&i.LastName,
+			&i.EncryptedToken,
+			&i.EncryptionVersion,
+			&i.EncryptionAlgorithm,
+			&i.Role,
+			&i.LastError,
+			&i.LastCheckedAt,
+			&i.DisabledAt,
+			&i.RevokedAt,
+			&i.TokenBound,
+			&i.TokenAlgorithmBound,
+			&i.WrappedDataKey,
+			&i.KekID,
+			&i.Timezone,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resealTelegramBotToken = `-- name: ResealTelegramBotToken :execrows
+UPDATE
+    telegram_bots
+SET
+    encrypted_token = $1,
+    encryption_version = $2,
+    encryption_algorithm = $3,
+    token_bound = TRUE,
+    token_algorithm_bound = TRUE,
+    wrapped_data_key = $4,
+    kek_id = $5,
+    updated_at = NOW()
+WHERE
+    id = $6
+    AND encrypted_token = $7
+`
+
+type ResealTelegramBotTokenParams struct {
+	EncryptedToken      []byte      `json:"encrypted_token"`
+	EncryptionVersion   int32       `json:"encryption_version"`
+	EncryptionAlgorithm string      `json:"encryption_algorithm"`
+	WrappedDataKey      []byte      `json:"wrapped_data_key"`
+	KekID               *string     `json:"kek_id"`
+	ID                  pgtype.UUID `json:"id"`
+	OldEncryptedToken   []byte      `json:"old_encrypted_token"`
+}
+
+func (q *Queries) ResealTelegramBotToken(ctx context.Context, arg ResealTelegramBotTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, resealTelegramBotToken,
+		arg.EncryptedToken,
+		arg.EncryptionVersion,
+		arg.EncryptionAlgorithm,
+		arg.WrappedDataKey,
+		arg.KekID,
+		arg.ID,
+		arg.OldEncryptedToken,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setTelegramBotCheck = `-- name: SetTelegramBotCheck :exec
+UPDATE
+    telegram_bots
+SET
+    last_error = $1,
+    last_checked_at = NOW()
+WHERE
+    id = $2
+`
+
+type SetTelegramBotCheckParams struct {
+	LastError *string     `json:"last_error"`
+	ID        pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) SetTelegramBotCheck(ctx context.Context, arg SetTelegramBotCheckParams) error {
+	_, err := q.db.Exec(ctx, setTelegramBotCheck, arg.LastError, arg.ID)
+	return err
+}
+
 const updateTelegramBot = `-- name: UpdateTelegramBot :one
 UPDATE
     telegram_bots
@@ -338,58 +787,83 @@ SET
     last_name = $4,
     encrypted_token = $5,
     encryption_version = $6,
-    "role" = $7,
-    last_error = $8,
-    last_checked_at = $9,
-    revoked_at = $10,
-    disabled_at = $11,
+    encryption_algorithm = $7,
+    "role" = $8,
+    last_error = $9,
+    last_checked_at = $10,
+    revoked_at = $11,
+    disabled_at = $12,
+    token_bound = $13,
+    token_algorithm_bound = $14,
+    wrapped_data_key = $15,
+    kek_id = $16,
+    timezone = $17,
     updated_at = NOW()
 WHERE
-    bot_id = $1 RETURNING id,
+    id = $18 RETURNING id,
     bot_id,
     username,
     first_name,
     last_name,
     encrypted_token,
     encryption_version,
+    encryption_algorithm,
     "role",
     last_error,
     last_checked_at,
     disabled_at,
     revoked_at,
+    token_bound,
+    token_algorithm_bound,
+    wrapped_data_key,
+    kek_id,
+    timezone,
     created_at,
     updated_at
 `
 
 type UpdateTelegramBotParams struct {
-	BotID             *int64           `json:"bot_id"`
-	Username          string           `json:"username"`
-	FirstName         *string          `json:"first_name"`
-	LastName          *string          `json:"last_name"`
-	EncryptedToken    []byte           `json:"encrypted_token"`
-	EncryptionVersion int32            `json:"encryption_version"`
-	Role              string           `json:"role"`
-	LastError         *string          `json:"last_error"`
-	LastCheckedAt     pgtype.Timestamp `json:"last_checked_at"`
-	RevokedAt         pgtype.Timestamp `json:"revoked_at"`
-	DisabledAt        pgtype.Timestamp `json:"disabled_at"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
+	ID                  pgtype.UUID      `json:"id"`
 }
 
 type UpdateTelegramBotRow struct {
-	ID                pgtype.UUID      `json:"id"`
-	BotID             *int64           `json:"bot_id"`
-	Username          string           `json:"username"`
-	FirstName         *string          `json:"first_name"`
-	LastName          *string          `json:"last_name"`
-	EncryptedToken    []byte           `json:"encrypted_token"`
-	EncryptionVersion int32            `json:"encryption_version"`
-	Role              string           `json:"role"`
-	LastError         *string          `json:"last_error"`
-	LastCheckedAt     pgtype.Timestamp `json:"last_checked_at"`
-	DisabledAt        pgtype.Timestamp `json:"disabled_at"`
-	RevokedAt         pgtype.Timestamp `json:"revoked_at"`
-	CreatedAt         pgtype.Timestamp `json:"created_at"`
-	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+	ID                  pgtype.UUID      `json:"id"`
+	BotID               *int64           `json:"bot_id"`
+	Username            string           `json:"username"`
+	FirstName           *string          `json:"first_name"`
+	LastName            *string          `json:"last_name"`
+	EncryptedToken      []byte           `json:"encrypted_token"`
+	EncryptionVersion   int32            `json:"encryption_version"`
+	EncryptionAlgorithm string           `json:"encryption_algorithm"`
+	Role                string           `json:"role"`
+	LastError           *string          `json:"last_error"`
+	LastCheckedAt       pgtype.Timestamp `json:"last_checked_at"`
+	DisabledAt          pgtype.Timestamp `json:"disabled_at"`
+	RevokedAt           pgtype.Timestamp `json:"revoked_at"`
+	TokenBound          bool             `json:"token_bound"`
+	TokenAlgorithmBound bool             `json:"token_algorithm_bound"`
+	WrappedDataKey      []byte           `json:"wrapped_data_key"`
+	KekID               *string          `json:"kek_id"`
+	Timezone            string           `json:"timezone"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
 }
 
 func (q *Queries) UpdateTelegramBot(ctx context.Context, arg UpdateTelegramBotParams) (UpdateTelegramBotRow, error) {
@@ -400,11 +874,18 @@ func (q *Queries) UpdateTelegramBot(ctx context.Context, arg UpdateTelegramBotPa
 		arg.LastName,
 		arg.EncryptedToken,
 		arg.EncryptionVersion,
+		arg.EncryptionAlgorithm,
 		arg.Role,
 		arg.LastError,
 		arg.LastCheckedAt,
 		arg.RevokedAt,
 		arg.DisabledAt,
+		arg.TokenBound,
+		arg.TokenAlgorithmBound,
+		arg.WrappedDataKey,
+		arg.KekID,
+		arg.Timezone,
+		arg.ID,
 	)
 	var i UpdateTelegramBotRow
 	err := row.Scan(
@@ -415,11 +896,17 @@ func (q *Queries) UpdateTelegramBot(ctx context.Context, arg UpdateTelegramBotPa
 		&i.LastName,
 		&i.EncryptedToken,
 		&i.EncryptionVersion,
+		&i.EncryptionAlgorithm,
 		&i.Role,
 		&i.LastError,
 		&i.LastCheckedAt,
 		&i.DisabledAt,
 		&i.RevokedAt,
+		&i.TokenBound,
+		&i.TokenAlgorithmBound,
+		&i.WrappedDataKey,
+		&i.KekID,
+		&i.Timezone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
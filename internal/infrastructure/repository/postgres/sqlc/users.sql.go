@@ -27,20 +27,111 @@ func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countUsersBySegment = `-- name: CountUsersBySegment :one
+SELECT
+    COUNT(*)
+FROM
+    users u
+WHERE
+    u.is_active = TRUE
+    AND EXISTS (
+        SELECT
+            1
+        FROM
+            script_progress p
+            JOIN scripts s ON s.id = p.script_id
+        WHERE
+            p.user_id = u.id
+            AND s.telegram_bot_id = $1
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unnest($2::text[]) AS want(tag)
+        WHERE
+            NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    user_tags t
+                WHERE
+                    t.user_id = u.id
+                    AND t.telegram_bot_id = $1
+                    AND t.tag = want.tag
+            )
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            user_tags t
+        WHERE
+            t.user_id = u.id
+            AND t.telegram_bot_id = $1
+            AND t.tag = ANY($3::text[])
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unnest($4::text[], $5::text[]) AS want("key", "value")
+        WHERE
+            NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    user_attributes a
+                WHERE
+                    a.user_id = u.id
+                    AND a.telegram_bot_id = $1
+                    AND a."key" = want."key"
+                    AND a."value" = want."value"
+            )
+    )
+`
+
+type CountUsersBySegmentParams struct {
+	TelegramBotID   pgtype.UUID `json:"telegram_bot_id"`
+	Tags            []string    `json:"tags"`
+	ExcludeTags     []string    `json:"exclude_tags"`
+	AttributeKeys   []string    `json:"attribute_keys"`
+	AttributeValues []string    `json:"attribute_values"`
+}
+
+func (q *Queries) CountUsersBySegment(ctx context.Context, arg CountUsersBySegmentParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsersBySegment,
+		arg.TelegramBotID,
+		arg.Tags,
+		arg.ExcludeTags,
+		arg.AttributeKeys,
+		arg.AttributeValues,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO
     users (
         telegram_id,
         username,
         first_name,
-        last_name
+        last_name,
+        language_code,
+        timezone,
+        timezone_source
     )
 VALUES
     (
         $1,
         $2,
         $3,
-        $4
+        $4,
+        $5,
+        $6,
+        $7
     ) RETURNING id,
     telegram_id,
     username,
@@ -48,14 +139,20 @@ VALUES
     last_name,
     created_at,
     is_active,
-    blocked_at
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
 `
 
 type CreateUserParams struct {
-	TelegramID *int64  `json:"telegram_id"`
-	Username   *string `json:"username"`
-	FirstName  *string `json:"first_name"`
-	LastName   *string `json:"last_name"`
+	TelegramID     *int64  `json:"telegram_id"`
+	Username       *string `json:"username"`
+	FirstName      *string `json:"first_name"`
+	LastName       *string `json:"last_name"`
+	LanguageCode   *string `json:"language_code"`
+	Timezone       *string `json:"timezone"`
+	TimezoneSource *string `json:"timezone_source"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -64,6 +161,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Username,
 		arg.FirstName,
 		arg.LastName,
+		arg.LanguageCode,
+		arg.Timezone,
+		arg.TimezoneSource,
 	)
 	var i User
 	err := row.Scan(
@@ -75,6 +175,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.IsActive,
 		&i.BlockedAt,
+		&i.LanguageCode,
+		&i.Timezone,
+		&i.TimezoneSource,
 	)
 	return i, err
 }
@@ -117,7 +220,10 @@ SELECT
     last_name,
     created_at,
     is_active,
-    blocked_at
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
 FROM
     users
 WHERE
@@ -137,6 +243,9 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
 		&i.CreatedAt,
 		&i.IsActive,
 		&i.BlockedAt,
+		&i.LanguageCode,
+		&i.Timezone,
+		&i.TimezoneSource,
 	)
 	return i, err
 }
@@ -150,7 +259,10 @@ SELECT
     last_name,
     created_at,
     is_active,
-    blocked_at
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
 FROM
     users
 WHERE
@@ -170,6 +282,52 @@ func (q *Queries) GetUserByTelegramID(ctx context.Context, telegramID *int64) (U
 		&i.CreatedAt,
 		&i.IsActive,
 		&i.BlockedAt,
+		&i.LanguageCode,
+		&i.Timezone,
+		&i.TimezoneSource,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT
+    id,
+    telegram_id,
+    username,
+    first_name,
+    last_name,
+    created_at,
+    is_active,
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
+FROM
+    users
+WHERE
+    LOWER(username) = LOWER($1::text)
+    AND is_active = TRUE
+ORDER BY
+    created_at DESC
+LIMIT
+    1
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramID,
+		&i.Username,
+		&i.FirstName,
+		&i.LastName,
+		&i.CreatedAt,
+		&i.IsActive,
+		&i.BlockedAt,
+		&i.LanguageCode,
+		&i.Timezone,
+		&i.TimezoneSource,
 	)
 	return i, err
 }
@@ -183,7 +341,10 @@ SELECT
     last_name,
     created_at,
     is_active,
-    blocked_at
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
 FROM
     users
 WHERE
@@ -217,6 +378,9 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.CreatedAt,
 			&i.IsActive,
 			&i.BlockedAt,
+			&i.LanguageCode,
+			&i.Timezone,
+			&i.TimezoneSource,
 		); err != nil {
 			return nil, err
 		}
@@ -228,6 +392,169 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const listUsersBySegment = `-- name: ListUsersBySegment :many
+SELECT
+    u.id,
+    u.telegram_id,
+    u.username,
+    u.first_name,
+    u.last_name,
+    u.created_at,
+    u.is_active,
+    u.blocked_at,
+    u.language_code,
+    u.timezone,
+    u.timezone_source
+FROM
+    users u
+WHERE
+    u.is_active = TRUE
+    AND EXISTS (
+        SELECT
+            1
+        FROM
+            script_progress p
+            JOIN scripts s ON s.id = p.script_id
+        WHERE
+            p.user_id = u.id
+            AND s.telegram_bot_id = $1
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unnest($2::text[]) AS want(tag)
+        WHERE
+            NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    user_tags t
+                WHERE
+                    t.user_id = u.id
+                    AND t.telegram_bot_id = $1
+                    AND t.tag = want.tag
+            )
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            user_tags t
+        WHERE
+            t.user_id = u.id
+            AND t.telegram_bot_id = $1
+            AND t.tag = ANY($3::text[])
+    )
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unnest($4::text[], $5::text[]) AS want("key", "value")
+        WHERE
+            NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    user_attributes a
+                WHERE
+                    a.user_id = u.id
+                    AND a.telegram_bot_id = $1
+                    AND a."key" = want."key"
+                    AND a."value" = want."value"
+            )
+    )
+ORDER BY
+    u.created_at
+LIMIT
+    $7 OFFSET $6
+`
+
+type ListUsersBySegmentParams struct {
+	TelegramBotID   pgtype.UUID `json:"telegram_bot_id"`
+	Tags            []string    `json:"tags"`
+	ExcludeTags     []string    `json:"exclude_tags"`
+	AttributeKeys   []string    `json:"attribute_keys"`
+	AttributeValues []string    `json:"attribute_values"`
+	OffsetVal       int32       `json:"offset_val"`
+	LimitVal        int32       `json:"limit_val"`
+}
+
+type ListUsersBySegmentRow struct {
+	ID             pgtype.UUID      `json:"id"`
+	TelegramID     *int64           `json:"telegram_id"`
+	Username       *string          `json:"username"`
+	FirstName      *string          `json:"first_name"`
+	LastName       *string          `json:"last_name"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	IsActive       bool             `json:"is_active"`
+	BlockedAt      pgtype.Timestamp `json:"blocked_at"`
+	LanguageCode   *string          `json:"language_code"`
+	Timezone       *string          `json:"timezone"`
+	TimezoneSource *string          `json:"timezone_source"`
+}
+
+func (q *Queries) ListUsersBySegment(ctx context.Context, arg ListUsersBySegmentParams) ([]ListUsersBySegmentRow, error) {
+	rows, err := q.db.Query(ctx, listUsersBySegment,
+		arg.TelegramBotID,
+		arg.Tags,
+		arg.ExcludeTags,
+		arg.AttributeKeys,
+		arg.AttributeValues,
+		arg.OffsetVal,
+		arg.LimitVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersBySegmentRow{}
+	for rows.Next() {
+		var i ListUsersBySegmentRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramID,
+			&i.Username,
+			&i.FirstName,
+			&i.LastName,
+			&i.CreatedAt,
+			&i.IsActive,
+			&i.BlockedAt,
+			&i.LanguageCode,
+			&i.Timezone,
+			&i.TimezoneSource,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserTimezone = `-- name: SetUserTimezone :exec
+UPDATE
+    users
+SET
+    timezone = $1,
+    timezone_source = $2
+WHERE
+    id = $3
+`
+
+type SetUserTimezoneParams struct {
+	Timezone       *string     `json:"timezone"`
+	TimezoneSource *string     `json:"timezone_source"`
+	ID             pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) SetUserTimezone(ctx context.Context, arg SetUserTimezoneParams) error {
+	_, err := q.db.Exec(ctx, setUserTimezone, arg.Timezone, arg.TimezoneSource, arg.ID)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE
     users
@@ -235,9 +562,10 @@ SET
     telegram_id = $1,
     username = $2,
     first_name = $3,
-    last_name = $4
+    last_name = $4,
+    language_code = $5
 WHERE
-    id = $5
+    id = $6
     AND is_active = TRUE RETURNING id,
     telegram_id,
     username,
@@ -245,15 +573,19 @@ WHERE
     last_name,
     created_at,
     is_active,
-    blocked_at
+    blocked_at,
+    language_code,
+    timezone,
+    timezone_source
 `
 
 type UpdateUserParams struct {
-	TelegramID *int64      `json:"telegram_id"`
-	Username   *string     `json:"username"`
-	FirstName  *string     `json:"first_name"`
-	LastName   *string     `json:"last_name"`
-	ID         pgtype.UUID `json:"id"`
+	TelegramID   *int64      `json:"telegram_id"`
+	Username     *string     `json:"username"`
+	FirstName    *string     `json:"first_name"`
+	LastName     *string     `json:"last_name"`
+	LanguageCode *string     `json:"language_code"`
+	ID           pgtype.UUID `json:"id"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -262,6 +594,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Username,
 		arg.FirstName,
 		arg.LastName,
+		arg.LanguageCode,
 		arg.ID,
 	)
 	var i User
@@ -274,6 +607,9 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.CreatedAt,
 		&i.IsActive,
 		&i.BlockedAt,
+		&i.LanguageCode,
+		&i.Timezone,
+		&i.TimezoneSource,
 	)
 	return i, err
 }
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/alert"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresTelegramBotAlertRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewPostgresTelegramBotAlertRepository(db *pgxpool.Pool) alert.Repository {
+	return &PostgresTelegramBotAlertRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresTelegramBotAlertRepository) Record(ctx context.Context, a *alert.Alert, createdAt time.Time) error {
+	err := r.queries.CreateTelegramBotAlert(ctx, sqlc.CreateTelegramBotAlertParams{
+		TelegramBotID: uuidToPgtype(a.BotID),
+		Kind:          a.Kind,
+		Text:          a.Text,
+		CreatedAt:     timeToPgtype(createdAt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record telegram bot alert: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresTelegramBotAlertRepository) CountSince(ctx context.Context, botID uuid.UUID, kind string, since time.Time) (int, error) {
+	var (
+		n   int64
+		err error
+	)
+	if kind == "" {
+		n, err = r.queries.CountTelegramBotAlertsSince(ctx, sqlc.CountTelegramBotAlertsSinceParams{
+			TelegramBotID: uuidToPgtype(botID),
+			Since:         timeToPgtype(since),
+		})
+	} else {
+		n, err = r.queries.CountTelegramBotAlertsOfKindSince(ctx, sqlc.CountTelegramBotAlertsOfKindSinceParams{
+			TelegramBotID: uuidToPgtype(botID),
+			Kind:          kind,
+			Since:         timeToPgtype(since),
+		})
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count telegram bot alerts: %w", err)
+	}
+	return int(n), nil
+}
+
+func (r *PostgresTelegramBotAlertRepository) ListRecipients(ctx context.Context, botID uuid.UUID) ([]int64, error) {
+	rows, err := r.queries.ListTelegramBotAlertRecipients(ctx, uuidToPgtype(botID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list telegram bot alert recipients: %w", err)
+	}
+	ids := make([]int64, 0, len(rows))
+	for _, id := range rows {
+		ids = append(ids, pgtypeToInt64(id))
+	}
+	return ids, nil
+}
+
+func (r *PostgresTelegramBotAlertRepository) AdminBotID(ctx context.Context) (int64, error) {
+	id, err := r.queries.GetAdminTelegramBotID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get admin telegram bot: %w", notFound(err))
+	}
+	return pgtypeToInt64(id), nil
+}
+
+func (r *PostgresTelegramBotAlertRepository) SetMuted(ctx context.Context, botID, userID uuid.UUID, muted bool) error {
+	var err error
+	if muted {
+		err = r.queries.MuteTelegramBotAlerts(ctx, sqlc.MuteTelegramBotAlertsParams{
+			UserID:        uuidToPgtype(userID),
+			TelegramBotID: uuidToPgtype(botID),
+		})
+	} else {
+		err = r.queries.UnmuteTelegramBotAlerts(ctx, sqlc.UnmuteTelegramBotAlertsParams{
+			UserID:        uuidToPgtype(userID),
+			TelegramBotID: uuidToPgtype(botID),
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set telegram bot alert mute: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresTelegramBotAlertRepository) IsMuted(ctx context.Context, botID, userID uuid.UUID) (bool, error) {
+	muted, err := r.queries.TelegramBotAlertsMuted(ctx, sqlc.TelegramBotAlertsMutedParams{
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(botID),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get telegram bot alert mute: %w", err)
+	}
+	return muted, nil
+}
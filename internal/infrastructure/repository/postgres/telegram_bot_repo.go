@@ -2,29 +2,56 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
 	"github.com/VladKovDev/promo-bot/internal/infrastructure/crypto"
 	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
 )
 
 type PostgresTelegramBotRepository struct {
-	queries  *sqlc.Queries
-	keyStore *crypto.KeyStore
+	db           *pgxpool.Pool
+	queries      *sqlc.Queries
+	keyStore     *crypto.KeyStore
+	envelope     *crypto.Envelope
+	allowUnbound bool
+	logger       logger.Logger
 }
 
-func NewPostgresTelegramBotRepository(db *pgxpool.Pool, keyStore *crypto.KeyStore) telegram_bot.Repository {
+// NewPostgresTelegramBotRepository creates the repository. When envelope is
+// not nil, tokens are written with envelope encryption; keyStore is still used
+// to read rows encrypted with versioned keys. Tokens not bound to their rows
+// are rejected unless allowUnbound is set; ResealTokens always reads them.
+// Listings skip bots whose token cannot be read and report them to logger.
+func NewPostgresTelegramBotRepository(db *pgxpool.Pool, keyStore *crypto.KeyStore, envelope *crypto.Envelope, allowUnbound bool, logger logger.Logger) telegram_bot.Repository {
 	return &PostgresTelegramBotRepository{
-		queries:  sqlc.New(db),
-		keyStore: keyStore,
+		db:           db,
+		queries:      sqlc.New(db),
+		keyStore:     keyStore,
+		envelope:     envelope,
+		allowUnbound: allowUnbound,
+		logger:       logger,
 	}
 }
 
+// sealedToken is an encrypted token as stored in telegram_bots.
+type sealedToken struct {
+	encryptedToken      []byte
+	encryptionVersion   int32
+	encryptionAlgorithm string
+	tokenBound          bool
+	algorithmBound      bool
+	wrappedDataKey      []byte
+	kekID               *string
+}
+
 func (r *PostgresTelegramBotRepository) Create(ctx context.Context, bot *telegram_bot.TelegramBot) error {
 	var botID *int64
 	if bot.BotID != 0 {
@@ -41,24 +68,35 @@ func (r *PostgresTelegramBotRepository) Create(ctx context.Context, bot *telegra
 		lastName = &bot.LastName
 	}
 
-	enc := r.keyStore.Encryptors[r.keyStore.Current]
-	encryptedToken, err := enc.Encrypt([]byte(bot.Token))
+	id := bot.ID
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	sealed, err := r.encryptToken(ctx, id, bot.Token)
 	if err != nil {
-		return fmt.Errorf("failed to encrypt token: %w", err)
+		return err
 	}
 
 	params := sqlc.CreateTelegramBotParams{
-		BotID:             botID,
-		Username:          bot.Username,
-		FirstName:         firstName,
-		LastName:          lastName,
-		EncryptedToken:    encryptedToken,
-		EncryptionVersion: int32(r.keyStore.Current),
-		Role:              bot.Role,
-		LastError:         nil,
-		LastCheckedAt:     timeToPgtype(time.Time{}),
-		RevokedAt:         timePtrToPgtype(bot.RevokedAt),
-		DisabledAt:        timePtrToPgtype(bot.DisabledAt),
+		ID:                  uuidToPgtype(id),
+		BotID:               botID,
+		Username:            bot.Username,
+		FirstName:           firstName,
+		LastName:            lastName,
+		EncryptedToken:      sealed.encryptedToken,
+		EncryptionVersion:   sealed.encryptionVersion,
+		EncryptionAlgorithm: sealed.encryptionAlgorithm,
+		Role:                bot.Role,
+		LastError:           stringToPgtype(bot.LastError),
+		LastCheckedAt:       timePtrToPgtype(bot.LastCheckedAt),
+		RevokedAt:           timePtrToPgtype(bot.RevokedAt),
+		DisabledAt:          timePtrToPgtype(bot.DisabledAt),
+		TokenBound:          sealed.tokenBound,
+		TokenAlgorithmBound: sealed.algorithmBound,
+		WrappedDataKey:      sealed.wrappedDataKey,
+		KekID:               sealed.kekID,
+		Timezone:            botTimezone(bot.Timezone),
 	}
 
 	created, err := r.queries.CreateTelegramBot(ctx, params)
@@ -66,7 +104,7 @@ func (r *PostgresTelegramBotRepository) Create(ctx context.Context, bot *telegra
 		return fmt.Errorf("failed to create telegram bot: %w", err)
 	}
 
-	createdBot, err := telegramBotFromRow(r, created)
+	createdBot, err := telegramBotFromRow(ctx, r, created)
 	if err != nil {
 		return fmt.Errorf("failed to map created telegram bot: %w", err)
 	}
@@ -77,17 +115,25 @@ func (r *PostgresTelegramBotRepository) Create(ctx context.Context, bot *telegra
 func (r *PostgresTelegramBotRepository) GetByID(ctx context.Context, id uuid.UUID) (*telegram_bot.TelegramBot, error) {
 	tb, err := r.queries.GetTelegramBotByID(ctx, uuidToPgtype(id))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get telegram bot by id: %w", err)
+		return nil, fmt.Errorf("failed to get telegram bot by id: %w", notFound(err))
 	}
-	return telegramBotFromRow(r, tb)
+	return telegramBotFromRow(ctx, r, tb)
 }
 
 func (r *PostgresTelegramBotRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*telegram_bot.TelegramBot, error) {
 	tb, err := r.queries.GetTelegramBotByBotID(ctx, &telegramID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get telegram bot by telegram id: %w", err)
+		return nil, fmt.Errorf("failed to get telegram bot by telegram id: %w", notFound(err))
+	}
+	return telegramBotFromRow(ctx, r, tb)
+}
+
+func (r *PostgresTelegramBotRepository) GetByUsername(ctx context.Context, username string) (*telegram_bot.TelegramBot, error) {
+	tb, err := r.queries.GetTelegramBotByUsername(ctx, strings.TrimPrefix(username, "@"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telegram bot by username: %w", notFound(err))
 	}
-	return telegramBotFromRow(r, tb)
+	return telegramBotFromRow(ctx, r, tb)
 }
 
 func (r *PostgresTelegramBotRepository) Update(ctx context.Context, bot *telegram_bot.TelegramBot) error {
@@ -106,24 +152,30 @@ func (r *PostgresTelegramBotRepository) Update(ctx context.Context, bot *telegra
 		lastName = &bot.LastName
 	}
 
-	enc := r.keyStore.Encryptors[r.keyStore.Current]
-	encryptedToken, err := enc.Encrypt([]byte(bot.Token))
+	sealed, err := r.encryptToken(ctx, bot.ID, bot.Token)
 	if err != nil {
-		return fmt.Errorf("failed to encrypt token: %w", err)
+		return err
 	}
 
 	params := sqlc.UpdateTelegramBotParams{
-		BotID:             botID,
-		Username:          bot.Username,
-		FirstName:         firstName,
-		LastName:          lastName,
-		EncryptedToken:    encryptedToken,
-		EncryptionVersion: int32(r.keyStore.Current),
-		Role:              bot.Role,
-		LastError:         nil,
-		LastCheckedAt:     timeToPgtype(time.Time{}),
-		RevokedAt:         timePtrToPgtype(bot.RevokedAt),
-		DisabledAt:        timePtrToPgtype(bot.DisabledAt),
+		ID:                  uuidToPgtype(bot.ID),
+		BotID:               botID,
+		Username:            bot.Username,
+		FirstName:           firstName,
+		LastName:            lastName,
+		EncryptedToken:      sealed.encryptedToken,
+		EncryptionVersion:   sealed.encryptionVersion,
+		EncryptionAlgorithm: sealed.encryptionAlgorithm,
+		Role:                bot.Role,
+		LastError:           stringToPgtype(bot.LastError),
+		LastCheckedAt:       timePtrToPgtype(bot.LastCheckedAt),
+		RevokedAt:           timePtrToPgtype(bot.RevokedAt),
+		DisabledAt:          timePtrToPgtype(bot.DisabledAt),
+		TokenBound:          sealed.tokenBound,
+		TokenAlgorithmBound: sealed.algorithmBound,
+		WrappedDataKey:      sealed.wrappedDataKey,
+		KekID:               sealed.kekID,
+		Timezone:            botTimezone(bot.Timezone),
 	}
 
 	updated, err := r.queries.UpdateTelegramBot(ctx, params)
@@ -131,7 +183,7 @@ func (r *PostgresTelegramBotRepository) Update(ctx context.Context, bot *telegra
 		return fmt.Errorf("failed to update telegram bot: %w", err)
 	}
 
-	updatedBot, err := telegramBotFromRow(r, updated)
+	updatedBot, err := telegramBotFromRow(ctx, r, updated)
 	if err != nil {
 		return fmt.Errorf("failed to map updated telegram bot: %w", err)
 	}
@@ -154,8 +206,31 @@ func (r *PostgresTelegramBotRepository) ListAll(ctx context.Context) ([]*telegra
 	}
 	var bots []*telegram_bot.TelegramBot
 	for _, it := range items {
-		b, err := telegramBotFromRow(r, it)
+		b, err := telegramBotFromRow(ctx, r, it)
+		if err != nil {
+			if r.skipUnreadable(ctx, err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to convert telegram bot: %w", err)
+		}
+		bots = append(bots, b)
+	}
+	return bots, nil
+}
+
+// ListOwned returns the bots userID owns, ordered by username.
+func (r *PostgresTelegramBotRepository) ListOwned(ctx context.Context, userID uuid.UUID) ([]*telegram_bot.TelegramBot, error) {
+	items, err := r.queries.ListTelegramBotsByOwner(ctx, uuidToPgtype(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list owned telegram bots: %w", err)
+	}
+	bots := make([]*telegram_bot.TelegramBot, 0, len(items))
+	for _, it := range items {
+		b, err := telegramBotFromRow(ctx, r, it)
 		if err != nil {
+			if r.skipUnreadable(ctx, err) {
+				continue
+			}
 			return nil, fmt.Errorf("failed to convert telegram bot: %w", err)
 		}
 		bots = append(bots, b)
@@ -163,21 +238,296 @@ func (r *PostgresTelegramBotRepository) ListAll(ctx context.Context) ([]*telegra
 	return bots, nil
 }
 
-func telegramBotFromRow[T sqlc.TelegramBot | sqlc.CreateTelegramBotRow | sqlc.UpdateTelegramBotRow | sqlc.ListTelegramBotsRow | sqlc.GetTelegramBotByBotIDRow | sqlc.GetTelegramBotByIDRow](
+// unreadableTokenError reports a bot row whose token cannot be decrypted.
+type unreadableTokenError struct {
+	id  uuid.UUID
+	err error
+}
+
+func (e *unreadableTokenError) Error() string { return e.err.Error() }
+
+func (e *unreadableTokenError) Unwrap() error { return e.err }
+
+// skipUnreadable reports whether err is an unreadable token that a listing
+// should skip. The bot is logged and its last error recorded so one bad row
+// does not hide the others.
+func (r *PostgresTelegramBotRepository) skipUnreadable(ctx context.Context, err error) bool {
+	var tokenErr *unreadableTokenError
+	if !errors.As(err, &tokenErr) {
+		return false
+	}
+	if r.logger != nil {
+		r.logger.Error("skipping telegram bot with unreadable token",
+			zap.String("bot_id", tokenErr.id.String()),
+			zap.Error(tokenErr.err),
+		)
+	}
+	if err := r.RecordCheck(ctx, tokenErr.id, tokenErr.err); err != nil && r.logger != nil {
+		r.logger.Error("failed to record unreadable token",
+			zap.String("bot_id", tokenErr.id.String()),
+			zap.Error(err),
+		)
+	}
+	return true
+}
+
+// RecordCheck stores the outcome of connecting to the bot: checkErr, or a
+// cleared error when it is nil, stamped with the current time.
+func (r *PostgresTelegramBotRepository) RecordCheck(ctx context.Context, id uuid.UUID, checkErr error) error {
+	var lastError *string
+	if checkErr != nil {
+		lastError = stringToPgtype(checkErr.Error())
+	}
+	err := r.queries.SetTelegramBotCheck(ctx, sqlc.SetTelegramBotCheckParams{
+		LastError: lastError,
+		ID:        uuidToPgtype(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record telegram bot check: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresTelegramBotRepository) GetMemberRole(ctx context.Context, botID, userID uuid.UUID) (string, error) {
+	role, err := r.queries.GetTelegramBotMemberRole(ctx, sqlc.GetTelegramBotMemberRoleParams{
+		TelegramBotID: uuidToPgtype(botID),
+		UserID:        uuidToPgtype(userID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get telegram bot member role: %w", notFound(err))
+	}
+	return role, nil
+}
+
+func (r *PostgresTelegramBotRepository) GetQuietHours(ctx context.Context, botID uuid.UUID) ([]telegram_bot.QuietWindow, error) {
+	rows, err := r.queries.ListTelegramBotQuietHours(ctx, uuidToPgtype(botID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quiet hours: %w", err)
+	}
+	windows := make([]telegram_bot.QuietWindow, 0, len(rows))
+	for _, row := range rows {
+		windows = append(windows, telegram_bot.QuietWindow{
+			Start: int(row.StartMinute),
+			End:   int(row.EndMinute),
+		})
+	}
+	return windows, nil
+}
+
+func (r *PostgresTelegramBotRepository) SetQuietHours(ctx context.Context, botID uuid.UUID, windows []telegram_bot.QuietWindow) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	if err := q.DeleteTelegramBotQuietHours(ctx, uuidToPgtype(botID)); err != nil {
+		return fmt.Errorf("failed to delete quiet hours: %w", err)
+	}
+	for _, w := range windows {
+		err := q.CreateTelegramBotQuietHours(ctx, sqlc.CreateTelegramBotQuietHoursParams{
+			TelegramBotID: uuidToPgtype(botID),
+			StartMinute:   int32(w.Start),
+			EndMinute:     int32(w.End),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create quiet hours: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit quiet hours: %w", err)
+	}
+	return nil
+}
+
+// ResealTokens re-encrypts tokens that are not bound to their row and
+// algorithm yet or were written with a different key than the one currently
+// configured (key version or envelope master key). It returns the number of
+// rows updated.
+func (r *PostgresTelegramBotRepository) ResealTokens(ctx context.Context) (int, error) {
+	var params sqlc.ListTelegramBotTokensForResealParams
+	if r.envelope != nil {
+		kekID := r.envelope.KeyID()
+		params.KekID = &kekID
+		params.CurrentAlgorithm = r.envelope.Algorithm()
+	} else {
+		version, algorithm, _, err := r.keyStore.Current()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get current encryptor: %w", err)
+		}
+		params.CurrentVersion = int32(version)
+		params.CurrentAlgorithm = algorithm
+	}
+
+	items, err := r.queries.ListTelegramBotTokensForReseal(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list telegram bot tokens: %w", err)
+	}
+
+	resealed := 0
+	for _, it := range items {
+		id, err := pgtypeToUUID(it.ID)
+		if err != nil {
+			return resealed, fmt.Errorf("failed to convert telegram bot ID: %w", err)
+		}
+
+		token, err := r.decryptToken(ctx, id, true, sealedToken{
+			encryptedToken:      it.EncryptedToken,
+			encryptionVersion:   it.EncryptionVersion,
+			encryptionAlgorithm: it.EncryptionAlgorithm,
+			tokenBound:          it.TokenBound,
+			algorithmBound:      it.TokenAlgorithmBound,
+			wrappedDataKey:      it.WrappedDataKey,
+			kekID:               it.KekID,
+		})
+		if err != nil {
+			return resealed, fmt.Errorf("telegram bot %s: %w", id, err)
+		}
+
+		sealed, err := r.encryptToken(ctx, id, token)
+		if err != nil {
+			return resealed, fmt.Errorf("telegram bot %s: %w", id, err)
+		}
+
+		n, err := r.queries.ResealTelegramBotToken(ctx, sqlc.ResealTelegramBotTokenParams{
+			EncryptedToken:      sealed.encryptedToken,
+			EncryptionVersion:   sealed.encryptionVersion,
+			EncryptionAlgorithm: sealed.encryptionAlgorithm,
+			WrappedDataKey:      sealed.wrappedDataKey,
+			KekID:               sealed.kekID,
+			ID:                  it.ID,
+			OldEncryptedToken:   it.EncryptedToken,
+		})
+		if err != nil {
+			return resealed, fmt.Errorf("failed to reseal telegram bot %s token: %w", id, err)
+		}
+		resealed += int(n)
+	}
+	return resealed, nil
+}
+
+// botTimezone defaults an unset bot timezone to UTC to satisfy the column
+// constraint.
+func botTimezone(tz string) string {
+	if tz == "" {
+		return "UTC"
+	}
+	return tz
+}
+
+// tokenAssociatedData binds a token ciphertext to its row, the algorithm and
+// the key that sealed it (key version or master key ID), so ciphertexts cannot
+// be swapped between rows. An empty algorithm gives the associated data of
+// rows sealed before the algorithm was bound.
+func tokenAssociatedData(id uuid.UUID, algorithm, keyRef string) []byte {
+	if algorithm == "" {
+		return []byte(fmt.Sprintf("telegram_bots:%s:%s", id, keyRef))
+	}
+	return []byte(fmt.Sprintf("telegram_bots:%s:%s:%s", id, algorithm, keyRef))
+}
+
+// boundAlgorithm returns the algorithm part of the associated data of a
+// sealed token.
+func (s sealedToken) boundAlgorithm() string {
+	if !s.algorithmBound {
+		return ""
+	}
+	return s.encryptionAlgorithm
+}
+
+func (r *PostgresTelegramBotRepository) encryptToken(ctx context.Context, id uuid.UUID, token string) (*sealedToken, error) {
+	if r.envelope != nil {
+		kekID := r.envelope.KeyID()
+		sealed, err := r.envelope.Seal(ctx, []byte(token), tokenAssociatedData(id, r.envelope.Algorithm(), kekID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt token: %w", err)
+		}
+		return &sealedToken{
+			encryptedToken:      sealed.CipherText,
+			encryptionAlgorithm: sealed.Algorithm,
+			tokenBound:          true,
+			algorithmBound:      true,
+			wrappedDataKey:      sealed.WrappedKey,
+			kekID:               &sealed.KeyID,
+		}, nil
+	}
+
+	version, algorithm, enc, err := r.keyStore.Current()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current encryptor: %w", err)
+	}
+	encryptedToken, err := enc.Encrypt([]byte(token), tokenAssociatedData(id, algorithm, fmt.Sprintf("v%d", version)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt token: %w", err)
+	}
+	return &sealedToken{
+		encryptedToken:      encryptedToken,
+		encryptionVersion:   int32(version),
+		encryptionAlgorithm: algorithm,
+		tokenBound:          true,
+		algorithmBound:      true,
+	}, nil
+}
+
+// decryptToken opens a token ciphertext. Rows written before tokens were
+// bound (tokenBound is false) are sealed without associated data and are only
+// opened when allowUnbound is set.
+func (r *PostgresTelegramBotRepository) decryptToken(ctx context.Context, id uuid.UUID, allowUnbound bool, sealed sealedToken) (string, error) {
+	if sealed.kekID != nil {
+		if r.envelope == nil {
+			return "", fmt.Errorf("token uses envelope encryption but no key provider is configured")
+		}
+		token, err := r.envelope.Open(ctx, &crypto.Sealed{
+			CipherText: sealed.encryptedToken,
+			WrappedKey: sealed.wrappedDataKey,
+			KeyID:      *sealed.kekID,
+			Algorithm:  sealed.encryptionAlgorithm,
+		}, tokenAssociatedData(id, sealed.boundAlgorithm(), *sealed.kekID))
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt telegram bot token: %w", err)
+		}
+		return string(token), nil
+	}
+
+	enc, err := r.keyStore.Get(sealed.encryptionAlgorithm, int(sealed.encryptionVersion))
+	if err != nil {
+		return "", fmt.Errorf("unknown encryption key %s v%d: %w", sealed.encryptionAlgorithm, sealed.encryptionVersion, err)
+	}
+
+	var associatedData []byte
+	switch {
+	case sealed.tokenBound:
+		associatedData = tokenAssociatedData(id, sealed.boundAlgorithm(), fmt.Sprintf("v%d", sealed.encryptionVersion))
+	case !allowUnbound:
+		return "", fmt.Errorf("telegram bot token is not bound to its row, run -reseal-tokens: %w", crypto.ErrDecryptionFail)
+	}
+
+	token, err := enc.Decrypt(sealed.encryptedToken, associatedData)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt telegram bot token: %w", err)
+	}
+	return string(token), nil
+}
+
+func telegramBotFromRow[T sqlc.TelegramBot | sqlc.CreateTelegramBotRow | sqlc.UpdateTelegramBotRow | sqlc.ListTelegramBotsRow | sqlc.GetTelegramBotByBotIDRow | sqlc.GetTelegramBotByIDRow | sqlc.GetTelegramBotByUsernameRow | sqlc.ListTelegramBotsByOwnerRow](
+	ctx context.Context,
 	r *PostgresTelegramBotRepository,
-	 row T,
-	 ) (*telegram_bot.TelegramBot, error) {
+	row T,
+) (*telegram_bot.TelegramBot, error) {
 	var (
-		id pgtype.UUID
-		botID *int64
-		username string
-		firstName *string
-		lastName *string
-		encryptedToken []byte
-		encryptionVersion int32
-		role string
-		revokedAt pgtype.Timestamp
-		disabledAt pgtype.Timestamp
+		id          pgtype.UUID
+		botID       *int64
+		username    string
+		firstName   *string
+		lastName    *string
+		sealed      sealedToken
+		role        string
+		revokedAt   pgtype.Timestamp
+		disabledAt  pgtype.Timestamp
+		timezone    string
+		lastError   *string
+		lastChecked pgtype.Timestamp
 	)
 	switch v := any(row).(type) {
 	case sqlc.TelegramBot:
@@ -186,66 +536,152 @@ func telegramBotFromRow[T sqlc.TelegramBot | sqlc.CreateTelegramBotRow | sqlc.Up
 		username = v.Username
 		firstName = v.FirstName
 		lastName = v.LastName
-		encryptedToken = v.EncryptedToken
-		encryptionVersion = v.EncryptionVersion
+		sealed.encryptedToken = v.EncryptedToken
+		sealed.encryptionVersion = v.EncryptionVersion
+		sealed.encryptionAlgorithm = v.EncryptionAlgorithm
 		role = v.Role
 		revokedAt = v.RevokedAt
 		disabledAt = v.DisabledAt
+		lastError = v.LastError
+		lastChecked = v.LastCheckedAt
+		sealed.tokenBound = v.TokenBound
+		sealed.algorithmBound = v.TokenAlgorithmBound
+		sealed.wrappedDataKey = v.WrappedDataKey
+		sealed.kekID = v.KekID
+		timezone = v.Timezone
 	case sqlc.CreateTelegramBotRow:
 		id = v.ID
 		botID = v.BotID
 		username = v.Username
 		firstName = v.FirstName
 		lastName = v.LastName
-		encryptedToken = v.EncryptedToken
-		encryptionVersion = v.EncryptionVersion
+		sealed.encryptedToken = v.EncryptedToken
+		sealed.encryptionVersion = v.EncryptionVersion
+		sealed.encryptionAlgorithm = v.EncryptionAlgorithm
 		role = v.Role
 		revokedAt = v.RevokedAt
 		disabledAt = v.DisabledAt
+		lastError = v.LastError
+		lastChecked = v.LastCheckedAt
+		sealed.tokenBound = v.TokenBound
+		sealed.algorithmBound = v.TokenAlgorithmBound
+		sealed.wrappedDataKey = v.WrappedDataKey
+		sealed.kekID = v.KekID
+		timezone = v.Timezone
 	case sqlc.UpdateTelegramBotRow:
 		id = v.ID
 		botID = v.BotID
 		username = v.Username
 		firstName = v.FirstName
 		lastName = v.LastName
-		encryptedToken = v.EncryptedToken
-		encryptionVersion = v.EncryptionVersion
+		sealed.encryptedToken = v.EncryptedToken
+		sealed.encryptionVersion = v.EncryptionVersion
+		sealed.encryptionAlgorithm = v.EncryptionAlgorithm
 		role = v.Role
 		revokedAt = v.RevokedAt
 		disabledAt = v.DisabledAt
+		lastError = v.LastError
+		lastChecked = v.LastCheckedAt
+		sealed.tokenBound = v.TokenBound
+		sealed.algorithmBound = v.TokenAlgorithmBound
+		sealed.wrappedDataKey = v.WrappedDataKey
+		sealed.kekID = v.KekID
+		timezone = v.Timezone
 	case sqlc.ListTelegramBotsRow:
 		id = v.ID
 		botID = v.BotID
 		username = v.Username
 		firstName = v.FirstName
 		lastName = v.LastName
-		encryptedToken = v.EncryptedToken
-		encryptionVersion = v.EncryptionVersion
+		sealed.encryptedToken = v.EncryptedToken
+		sealed.encryptionVersion = v.EncryptionVersion
+		sealed.encryptionAlgorithm = v.EncryptionAlgorithm
 		role = v.Role
 		revokedAt = v.RevokedAt
 		disabledAt = v.DisabledAt
+		lastError = v.LastError
+		lastChecked = v.LastCheckedAt
+		sealed.tokenBound = v.TokenBound
+		sealed.algorithmBound = v.TokenAlgorithmBound
+		sealed.wrappedDataKey = v.WrappedDataKey
+		sealed.kekID = v.KekID
+		timezone = v.Timezone
 	case sqlc.GetTelegramBotByBotIDRow:
 		id = v.ID
 		botID = v.BotID
 		username = v.Username
 		firstName = v.FirstName
 		lastName = v.LastName
-		encryptedToken = v.EncryptedToken
-		encryptionVersion = v.EncryptionVersion
+		sealed.encryptedToken = v.EncryptedToken
+		sealed.encryptionVersion = v.EncryptionVersion
+		sealed.encryptionAlgorithm = v.EncryptionAlgorithm
 		role = v.Role
 		revokedAt = v.RevokedAt
 		disabledAt = v.DisabledAt
+		lastError = v.LastError
+		lastChecked = v.LastCheckedAt
+		sealed.tokenBound = v.TokenBound
+		sealed.algorithmBound = v.TokenAlgorithmBound
+		sealed.wrappedDataKey = v.WrappedDataKey
+		sealed.kekID = v.KekID
+		timezone = v.Timezone
 	case sqlc.GetTelegramBotByIDRow:
 		id = v.ID
 		botID = v.BotID
 		username = v.Username
 		firstName = v.FirstName
 		lastName = v.LastName
-		encryptedToken = v.EncryptedToken
-		encryptionVersion = v.EncryptionVersion
+		sealed.encryptedToken = v.EncryptedToken
+		sealed.encryptionVersion = v.EncryptionVersion
+		sealed.encryptionAlgorithm = v.EncryptionAlgorithm
 		role = v.Role
 		revokedAt = v.RevokedAt
 		disabledAt = v.DisabledAt
+		lastError = v.LastError
+		lastChecked = v.LastCheckedAt
+		sealed.tokenBound = v.TokenBound
+		sealed.algorithmBound = v.TokenAlgorithmBound
+		sealed.wrappedDataKey = v.WrappedDataKey
+		sealed.kekID = v.KekID
+		timezone = v.Timezone
+	case sqlc.GetTelegramBotByUsernameRow:
+		id = v.ID
+		botID = v.BotID
+		username = v.Username
+		firstName = v.FirstName
+		lastName = v.LastName
+		sealed.encryptedToken = v.EncryptedToken
+		sealed.encryptionVersion = v.EncryptionVersion
+		sealed.encryptionAlgorithm = v.EncryptionAlgorithm
+		role = v.Role
+		revokedAt = v.RevokedAt
+		disabledAt = v.DisabledAt
+		lastError = v.LastError
+		lastChecked = v.LastCheckedAt
+		sealed.tokenBound = v.TokenBound
+		sealed.algorithmBound = v.TokenAlgorithmBound
+		sealed.wrappedDataKey = v.WrappedDataKey
+		sealed.kekID = v.KekID
+		timezone = v.Timezone
+	case sqlc.ListTelegramBotsByOwnerRow:
+		id = v.ID
+		botID = v.BotID
+		username = v.Username
+		firstName = v.FirstName
+		lastName = v.LastName
+		sealed.encryptedToken = v.EncryptedToken
+		sealed.encryptionVersion = v.EncryptionVersion
+		sealed.encryptionAlgorithm = v.EncryptionAlgorithm
+		role = v.Role
+		revokedAt = v.RevokedAt
+		disabledAt = v.DisabledAt
+		lastError = v.LastError
+		lastChecked = v.LastCheckedAt
+		sealed.tokenBound = v.TokenBound
+		sealed.algorithmBound = v.TokenAlgorithmBound
+		sealed.wrappedDataKey = v.WrappedDataKey
+		sealed.kekID = v.KekID
+		timezone = v.Timezone
 	default:
 		return nil, fmt.Errorf("unsupported row type")
 	}
@@ -256,31 +692,26 @@ func telegramBotFromRow[T sqlc.TelegramBot | sqlc.CreateTelegramBotRow | sqlc.Up
 	}
 
 	var tokenStr string
-	if len(encryptedToken) > 0 {
-		enc, ok := r.keyStore.Encryptors[int(encryptionVersion)]
-		if !ok {
-			return nil, fmt.Errorf("unknown encryption version: %d", encryptionVersion)
-		}
-		token, err := enc.Decrypt(encryptedToken)
+	if len(sealed.encryptedToken) > 0 {
+		tokenStr, err = r.decryptToken(ctx, domainId, r.allowUnbound, sealed)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt telegram bot token: %w", err)
+			return nil, &unreadableTokenError{id: domainId, err: err}
 		}
-		tokenStr = string(token)
 	}
 
-	domainRevokedAt := pgtypeToTime(revokedAt)
-	domainDisabledAt := pgtypeToTime(disabledAt)
-
 	bot := &telegram_bot.TelegramBot{
-		ID:        domainId,
-		BotID:      pgtypeToInt64(botID),
-		Token:      tokenStr,
-		Username:   username,
-		FirstName:  pgtypeToString(firstName),
-		LastName:   pgtypeToString(lastName),
-		Role:       role,
-		RevokedAt:  &domainRevokedAt,
-		DisabledAt: &domainDisabledAt,
+		ID:            domainId,
+		BotID:         pgtypeToInt64(botID),
+		Token:         tokenStr,
+		Username:      username,
+		FirstName:     pgtypeToString(firstName),
+		LastName:      pgtypeToString(lastName),
+		Role:          role,
+		Timezone:      timezone,
+		RevokedAt:     pgtypeToTimePtr(revokedAt),
+		DisabledAt:    pgtypeToTimePtr(disabledAt),
+		LastError:     pgtypeToString(lastError),
+		LastCheckedAt: pgtypeToTimePtr(lastChecked),
 	}
 	return bot, nil
 }
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VladKovDev/promo-bot/internal/domain/user"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresUserAttributeRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewPostgresUserAttributeRepository(db *pgxpool.Pool) user.AttributeRepository {
+	return &PostgresUserAttributeRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *PostgresUserAttributeRepository) Get(ctx context.Context, userID, telegramBotID uuid.UUID) (*user.Attributes, error) {
+	values, err := r.queries.ListUserAttributes(ctx, sqlc.ListUserAttributesParams{
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(telegramBotID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user attributes: %w", err)
+	}
+	tags, err := r.queries.ListUserTags(ctx, sqlc.ListUserTagsParams{
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(telegramBotID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user tags: %w", err)
+	}
+
+	attrs := user.NewAttributes()
+	for _, v := range values {
+		attrs.Values[v.Key] = v.Value
+	}
+	for _, tag := range tags {
+		attrs.Tags[tag] = true
+	}
+	return attrs, nil
+}
+
+func (r *PostgresUserAttributeRepository) Set(ctx context.Context, userID, telegramBotID uuid.UUID, key, value string) error {
+	err := r.queries.SetUserAttribute(ctx, sqlc.SetUserAttributeParams{
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Key:           key,
+		Value:         value,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set user attribute: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresUserAttributeRepository) Delete(ctx context.Context, userID, telegramBotID uuid.UUID, key string) error {
+	err := r.queries.DeleteUserAttribute(ctx, sqlc.DeleteUserAttributeParams{
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Key:           key,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user attribute: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresUserAttributeRepository) AddTag(ctx context.Context, userID, telegramBotID uuid.UUID, tag string) error {
+	err := r.queries.AddUserTag(ctx, sqlc.AddUserTagParams{
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Tag:           tag,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add user tag: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresUserAttributeRepository) RemoveTag(ctx context.Context, userID, telegramBotID uuid.UUID, tag string) error {
+	err := r.queries.RemoveUserTag(ctx, sqlc.RemoveUserTagParams{
+		UserID:        uuidToPgtype(userID),
+		TelegramBotID: uuidToPgtype(telegramBotID),
+		Tag:           tag,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove user tag: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/VladKovDev/promo-bot/internal/domain/user"
 	"github.com/VladKovDev/promo-bot/internal/infrastructure/repository/postgres/sqlc"
@@ -30,6 +31,10 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *user.User) er
 		Username:   &user.Username,
 		FirstName:  &user.FirstName,
 		LastName:   &user.LastName,
+
+		LanguageCode:   stringToPgtype(user.LanguageCode),
+		Timezone:       stringToPgtype(user.Timezone),
+		TimezoneSource: stringToPgtype(user.TimezoneSource),
 	}
 	sqlcUser, err := r.queries.CreateUser(ctx, params)
 	if err != nil {
@@ -49,7 +54,7 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*us
 	sqlcID := uuidToPgtype(id)
 	sqlcUser, err := r.queries.GetUserByID(ctx, sqlcID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
+		return nil, fmt.Errorf("failed to get user by id: %w", notFound(err))
 	}
 	user, err := r.toDomain(sqlcUser)
 	if err != nil {
@@ -61,7 +66,19 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*us
 func (r *PostgresUserRepository) GetByTelegramID(ctx context.Context, telegramID *int64) (*user.User, error) {
 	sqlcUser, err := r.queries.GetUserByTelegramID(ctx, telegramID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by telegram id: %w", err)
+		return nil, fmt.Errorf("failed to get user by telegram id: %w", notFound(err))
+	}
+	user, err := r.toDomain(sqlcUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert sqlcUser to user entity: %w", err)
+	}
+	return user, nil
+}
+
+func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (*user.User, error) {
+	sqlcUser, err := r.queries.GetUserByUsername(ctx, strings.TrimPrefix(username, "@"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by username: %w", notFound(err))
 	}
 	user, err := r.toDomain(sqlcUser)
 	if err != nil {
@@ -76,7 +93,10 @@ func (r *PostgresUserRepository) Update(ctx context.Context, user *user.User) er
 		TelegramID: &user.TelegramID,
 		Username:   &user.Username,
 		FirstName:  &user.FirstName,
-		LastName:   &user.LastName,}
+		LastName:   &user.LastName,
+
+		LanguageCode: stringToPgtype(user.LanguageCode),
+	}
 
 	_, err := r.queries.UpdateUser(ctx, arg)
 	if err != nil {
@@ -107,6 +127,18 @@ func (r *PostgresUserRepository) Deactivate(ctx context.Context, id uuid.UUID) (
 	return userID, nil
 }
 
+func (r *PostgresUserRepository) SetTimezone(ctx context.Context, id uuid.UUID, timezone, source string) error {
+	err := r.queries.SetUserTimezone(ctx, sqlc.SetUserTimezoneParams{
+		ID:             uuidToPgtype(id),
+		Timezone:       stringToPgtype(timezone),
+		TimezoneSource: stringToPgtype(source),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set user timezone: %w", err)
+	}
+	return nil
+}
+
 func (r *PostgresUserRepository) Count(ctx context.Context) (int64, error) {
 	count, err := r.queries.CountUsers(ctx)
 	if err != nil {
@@ -135,6 +167,48 @@ func (r *PostgresUserRepository) ListAll(ctx context.Context, limit, offset int)
 	return users, nil
 }
 
+func (r *PostgresUserRepository) ListBySegment(ctx context.Context, telegramBotID uuid.UUID, segment user.Segment, limit, offset int) ([]*user.User, int64, error) {
+	params := sqlc.CountUsersBySegmentParams{
+		TelegramBotID:   uuidToPgtype(telegramBotID),
+		Tags:            append([]string{}, segment.Tags...),
+		ExcludeTags:     append([]string{}, segment.ExcludeTags...),
+		AttributeKeys:   make([]string, 0, len(segment.Attributes)),
+		AttributeValues: make([]string, 0, len(segment.Attributes)),
+	}
+	for key, value := range segment.Attributes {
+		params.AttributeKeys = append(params.AttributeKeys, key)
+		params.AttributeValues = append(params.AttributeValues, value)
+	}
+
+	total, err := r.queries.CountUsersBySegment(ctx, params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users by segment: %w", err)
+	}
+
+	rows, err := r.queries.ListUsersBySegment(ctx, sqlc.ListUsersBySegmentParams{
+		TelegramBotID:   params.TelegramBotID,
+		Tags:            params.Tags,
+		ExcludeTags:     params.ExcludeTags,
+		AttributeKeys:   params.AttributeKeys,
+		AttributeValues: params.AttributeValues,
+		LimitVal:        int32(limit),
+		OffsetVal:       int32(offset),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users by segment: %w", err)
+	}
+
+	users := make([]*user.User, 0, len(rows))
+	for _, row := range rows {
+		user, err := r.toDomain(sqlc.User(row))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to convert sqlcUser to user entity: %w", err)
+		}
+		users = append(users, user)
+	}
+	return users, total, nil
+}
+
 func (r *PostgresUserRepository) toDomain(sqlcUser sqlc.User) (*user.User, error) {
 	id, err := pgtypeToUUID(sqlcUser.ID)
 	if err != nil {
@@ -143,11 +217,15 @@ func (r *PostgresUserRepository) toDomain(sqlcUser sqlc.User) (*user.User, error
 
 	user := &user.User{
 		ID:         id,
-		TelegramID: *sqlcUser.TelegramID,
-		Username:   *sqlcUser.Username,
-		FirstName:  *sqlcUser.FirstName,
-		LastName:   *sqlcUser.LastName,
+		TelegramID: pgtypeToInt64(sqlcUser.TelegramID),
+		Username:   pgtypeToString(sqlcUser.Username),
+		FirstName:  pgtypeToString(sqlcUser.FirstName),
+		LastName:   pgtypeToString(sqlcUser.LastName),
 		IsActive:   sqlcUser.IsActive,
+
+		LanguageCode:   pgtypeToString(sqlcUser.LanguageCode),
+		Timezone:       pgtypeToString(sqlcUser.Timezone),
+		TimezoneSource: pgtypeToString(sqlcUser.TimezoneSource),
 	}
 	return user, nil
 }
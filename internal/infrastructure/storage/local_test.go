@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VladKovDev/promo-bot/pkg/app_errors"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	l := NewLocal(t.TempDir())
+
+	if err := l.Put(ctx, "media/a.jpg", []byte("jpeg")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	data, err := l.Get(ctx, "media/a.jpg")
+	if err != nil || string(data) != "jpeg" {
+		t.Fatalf("Get() = %q, %v", data, err)
+	}
+	if _, err := l.Get(ctx, "media/b.jpg"); !errors.Is(err, app_errors.ErrNotFound) {
+		t.Fatalf("Get() of missing key error = %v, want not found", err)
+	}
+	if ok, err := l.Exists(ctx, "media/a.jpg"); !ok || err != nil {
+		t.Fatalf("Exists() = %v, %v, want true", ok, err)
+	}
+	if ok, err := l.Exists(ctx, "media/b.jpg"); ok || err != nil {
+		t.Fatalf("Exists() of missing key = %v, %v, want false", ok, err)
+	}
+
+	for _, key := range []string{"", "..", "../a.jpg", "media/../../a.jpg", "/etc/passwd"} {
+		if err := l.Put(ctx, key, nil); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Chats manages groups and channels the bots administer.
+type Chats struct {
+	botProvider BotProvider
+}
+
+func NewChats(botProvider BotProvider) *Chats {
+	return &Chats{botProvider: botProvider}
+}
+
+// CreateInviteLink creates an invite link to chatID that admits a single
+// member and stops working at expireAt.
+func (c *Chats) CreateInviteLink(ctx context.Context, botID, chatID int64, name string, expireAt time.Time) (string, error) {
+	bot, err := c.botProvider.Get(botID)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := bot.Request(tgbotapi.CreateChatInviteLinkConfig{
+		ChatConfig:  tgbotapi.ChatConfig{ChatID: chatID},
+		Name:        name,
+		ExpireDate:  int(expireAt.Unix()),
+		MemberLimit: 1,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var link tgbotapi.ChatInviteLink
+	if err := json.Unmarshal(resp.Result, &link); err != nil {
+		return "", fmt.Errorf("failed to decode invite link: %w", err)
+	}
+	return link.InviteLink, nil
+}
+
+func (c *Chats) RevokeInviteLink(ctx context.Context, botID, chatID int64, link string) error {
+	bot, err := c.botProvider.Get(botID)
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.Request(tgbotapi.RevokeChatInviteLinkConfig{
+		ChatConfig: tgbotapi.ChatConfig{ChatID: chatID},
+		InviteLink: link,
+	})
+	return err
+}
+
+func (c *Chats) ChatTitle(ctx context.Context, botID, chatID int64) (string, error) {
+	bot, err := c.botProvider.Get(botID)
+	if err != nil {
+		return "", err
+	}
+
+	chat, err := bot.GetChat(tgbotapi.ChatInfoConfig{
+		ChatConfig: tgbotapi.ChatConfig{ChatID: chatID},
+	})
+	if err != nil {
+		return "", err
+	}
+	return chat.Title, nil
+}
+
+func (c *Chats) ApproveJoinRequest(ctx context.Context, botID, chatID, userID int64) error {
+	bot, err := c.botProvider.Get(botID)
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.Request(tgbotapi.ApproveChatJoinRequestConfig{
+		ChatConfig: tgbotapi.ChatConfig{ChatID: chatID},
+		UserID:     userID,
+	})
+	return err
+}
+
+func (c *Chats) DeclineJoinRequest(ctx context.Context, botID, chatID, userID int64) error {
+	bot, err := c.botProvider.Get(botID)
+	if err != nil {
+		return err
+	}
+
+	_, err = bot.Request(tgbotapi.DeclineChatJoinRequest{
+		ChatConfig: tgbotapi.ChatConfig{ChatID: chatID},
+		UserID:     userID,
+	})
+	return err
+}
+
+// IsMember reports whether the user is in the chat, e.g. subscribed to the
+// channel. The bot has to be an administrator of channels it checks.
+func (c *Chats) IsMember(ctx context.Context, botID, chatID, userID int64) (bool, error) {
+	bot, err := c.botProvider.Get(botID)
+	if err != nil {
+		return false, err
+	}
+
+	member, err := bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil {
+		return false, err
+	}
+	return IsMember(member.Status, member.IsMember), nil
+}
+
+// IsMember reports whether a chat member with the Telegram status is in the
+// chat. Restricted members are in it only while isMember is set.
+func IsMember(status string, isMember bool) bool {
+	switch status {
+	case "creator", "administrator", "member":
+		return true
+	case "restricted":
+		return isMember
+	default:
+		return false
+	}
+}
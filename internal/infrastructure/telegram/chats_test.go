@@ -0,0 +1,24 @@
+package telegram
+
+import "testing"
+
+func TestIsMember(t *testing.T) {
+	tests := []struct {
+		status   string
+		isMember bool
+		want     bool
+	}{
+		{"creator", false, true},
+		{"administrator", false, true},
+		{"member", false, true},
+		{"restricted", true, true},
+		{"restricted", false, false},
+		{"left", false, false},
+		{"kicked", false, false},
+	}
+	for _, tt := range tests {
+		if got := IsMember(tt.status, tt.isMember); got != tt.want {
+			t.Errorf("IsMember(%q, %v) = %v, want %v", tt.status, tt.isMember, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package telegram
+
+import (
+	"errors"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// IsUnauthorized reports whether Telegram rejected the bot token, which
+// happens once the token is revoked.
+func IsUnauthorized(err error) bool {
+	return errorCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether Telegram refused to reach a chat the bot has
+// no access to, such as that of a user who blocked the bot.
+func IsForbidden(err error) bool {
+	return errorCode(err) == http.StatusForbidden
+}
+
+func errorCode(err error) int {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		return tgErr.Code
+	}
+	return 0
+}
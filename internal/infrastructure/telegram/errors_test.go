@@ -0,0 +1,22 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestErrorCodes(t *testing.T) {
+	unauthorized := fmt.Errorf("failed to send: %w", &tgbotapi.Error{Code: 401, Message: "Unauthorized"})
+	forbidden := &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+	other := errors.New("connection reset")
+
+	if !IsUnauthorized(unauthorized) || IsUnauthorized(forbidden) || IsUnauthorized(other) {
+		t.Error("IsUnauthorized() must match wrapped 401 errors only")
+	}
+	if !IsForbidden(forbidden) || IsForbidden(unauthorized) || IsForbidden(other) {
+		t.Error("IsForbidden() must match 403 errors only")
+	}
+}
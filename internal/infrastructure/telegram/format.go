@@ -0,0 +1,130 @@
+package telegram
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// FormatHTML renders text with its formatting entities as Telegram HTML, so
+// a message sent to a bot can be sent again with the same formatting.
+// Entities Telegram detects by itself, such as mentions and plain URLs, are
+// left as text. Overlapping entities are split where one of them ends, since
+// HTML tags must nest.
+func FormatHTML(text string, entities []tgbotapi.MessageEntity) string {
+	type tag struct {
+		pos, end int
+		open     string
+		close    string
+	}
+	var tags []tag
+	for _, e := range entities {
+		open, close := entityTags(e)
+		if open == "" {
+			continue
+		}
+		tags = append(tags, tag{pos: e.Offset, end: e.Offset + e.Length, open: open, close: close})
+	}
+	// outer entities open first and close last
+	sort.SliceStable(tags, func(i, j int) bool {
+		if tags[i].pos != tags[j].pos {
+			return tags[i].pos < tags[j].pos
+		}
+		return tags[i].end > tags[j].end
+	})
+
+	units := utf16.Encode([]rune(text))
+	var (
+		b     strings.Builder
+		open  []tag
+		next  int
+		write = func(from, to int) {
+			b.WriteString(html.EscapeString(string(utf16.Decode(units[from:to]))))
+		}
+	)
+	pos := 0
+	for pos <= len(units) {
+		// close the entities ending here together with the ones opened after
+		// them, then reopen those that go on
+		ended := -1
+		for i, t := range open {
+			if t.end <= pos {
+				ended = i
+				break
+			}
+		}
+		if ended >= 0 {
+			for i := len(open) - 1; i >= ended; i-- {
+				b.WriteString(open[i].close)
+			}
+			rest := open[ended:]
+			open = open[:ended]
+			for _, t := range rest {
+				if t.end > pos {
+					b.WriteString(t.open)
+					open = append(open, t)
+				}
+			}
+		}
+		for next < len(tags) && tags[next].pos <= pos {
+			b.WriteString(tags[next].open)
+			open = append(open, tags[next])
+			next++
+		}
+		if pos == len(units) {
+			break
+		}
+		// write up to the next tag boundary
+		stop := len(units)
+		if next < len(tags) && tags[next].pos < stop {
+			stop = tags[next].pos
+		}
+		for _, t := range open {
+			if t.end < stop {
+				stop = t.end
+			}
+		}
+		write(pos, stop)
+		pos = stop
+	}
+	return b.String()
+}
+
+// entityTags returns the HTML tags of a formatting entity, or empty strings
+// for entities that need none.
+func entityTags(e tgbotapi.MessageEntity) (string, string) {
+	switch e.Type {
+	case "bold":
+		return "<b>", "</b>"
+	case "italic":
+		return "<i>", "</i>"
+	case "underline":
+		return "<u>", "</u>"
+	case "strikethrough":
+		return "<s>", "</s>"
+	case "spoiler":
+		return "<tg-spoiler>", "</tg-spoiler>"
+	case "blockquote":
+		return "<blockquote>", "</blockquote>"
+	case "code":
+		return "<code>", "</code>"
+	case "pre":
+		if e.Language != "" {
+			return `<pre><code class="language-` + html.EscapeString(e.Language) + `">`, "</code></pre>"
+		}
+		return "<pre>", "</pre>"
+	case "text_link":
+		return `<a href="` + html.EscapeString(e.URL) + `">`, "</a>"
+	case "text_mention":
+		if e.User == nil {
+			return "", ""
+		}
+		return `<a href="tg://user?id=` + strconv.FormatInt(e.User.ID, 10) + `">`, "</a>"
+	default:
+		return "", ""
+	}
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestFormatHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []tgbotapi.MessageEntity
+		want     string
+	}{
+		{"plain", "a < b & c", nil, "a &lt; b &amp; c"},
+		{
+			"nested",
+			"Hello big world",
+			[]tgbotapi.MessageEntity{
+				{Type: "bold", Offset: 0, Length: 15},
+				{Type: "italic", Offset: 6, Length: 3},
+			},
+			"<b>Hello <i>big</i> world</b>",
+		},
+		{
+			"link after emoji",
+			"👋 Sign up here",
+			[]tgbotapi.MessageEntity{{Type: "text_link", Offset: 11, Length: 4, URL: "https://example.com/?a=1&b=2"}},
+			`👋 Sign up <a href="https://example.com/?a=1&amp;b=2">here</a>`,
+		},
+		{
+			"pre with language",
+			"x := 1",
+			[]tgbotapi.MessageEntity{{Type: "pre", Offset: 0, Length: 6, Language: "go"}},
+			`<pre><code class="language-go">x := 1</code></pre>`,
+		},
+		{
+			"detected entities",
+			"Hi @someone {{first_name}}",
+			[]tgbotapi.MessageEntity{{Type: "mention", Offset: 3, Length: 8}},
+			"Hi @someone {{first_name}}",
+		},
+		{
+			"adjacent",
+			"ab",
+			[]tgbotapi.MessageEntity{
+				{Type: "bold", Offset: 0, Length: 1},
+				{Type: "italic", Offset: 1, Length: 1},
+			},
+			"<b>a</b><i>b</i>",
+		},
+		{
+			"overlapping",
+			"abcdef",
+			[]tgbotapi.MessageEntity{
+				{Type: "bold", Offset: 0, Length: 4},
+				{Type: "italic", Offset: 2, Length: 4},
+			},
+			"<b>ab<i>cd</i></b><i>ef</i>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatHTML(tt.text, tt.entities); got != tt.want {
+				t.Errorf("FormatHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
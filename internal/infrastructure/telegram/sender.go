@@ -2,6 +2,8 @@ package telegram
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -10,6 +12,45 @@ type BotProvider interface {
 	Get(botID int64) (*tgbotapi.BotAPI, error)
 }
 
+// Button is an inline keyboard button. URL takes precedence over Data.
+type Button struct {
+	Text string
+	URL  string
+	Data string
+}
+
+// Media is a file sent with a message. Kind is one of the message.Media*
+// kinds and selects the send method.
+type Media struct {
+	Kind     string
+	FileName string
+	Data     []byte
+}
+
+// mediaMethods maps media kinds to the method sending them and the name of
+// the file field.
+var mediaMethods = map[string][2]string{
+	"photo":     {"sendPhoto", "photo"},
+	"video":     {"sendVideo", "video"},
+	"animation": {"sendAnimation", "animation"},
+	"audio":     {"sendAudio", "audio"},
+	"voice":     {"sendVoice", "voice"},
+	"document":  {"sendDocument", "document"},
+}
+
+// OutgoingMessage is a text message with an optional inline keyboard, one
+// button per row. When RequestContact is set the message carries a one-time
+// reply keyboard with a contact request button labelled with it instead.
+// When Media is set the file is sent with Text as its caption.
+type OutgoingMessage struct {
+	ChatID         int64
+	Text           string
+	ParseMode      string
+	Buttons        []Button
+	RequestContact string
+	Media          *Media
+}
+
 type Sender struct {
 	botProvider BotProvider
 }
@@ -28,3 +69,90 @@ func (s *Sender) SendMessage(ctx context.Context, botID, chatID int64, text stri
 	_, err = bot.Send(msg)
 	return err
 }
+
+// Send delivers msg through the bot and returns the Telegram message ID.
+func (s *Sender) Send(ctx context.Context, botID int64, msg OutgoingMessage) (int, error) {
+	return s.send(botID, msg, 0)
+}
+
+// SendToGroup delivers msg to a group, into the forum topic threadID when it
+// is not zero, and returns the Telegram message ID. Contact requests only
+// work in private chats and are dropped.
+func (s *Sender) SendToGroup(ctx context.Context, botID int64, msg OutgoingMessage, threadID int) (int, error) {
+	msg.RequestContact = ""
+	return s.send(botID, msg, threadID)
+}
+
+// send builds the request by hand: the library predates forum topics and
+// has no single config for the media kinds.
+func (s *Sender) send(botID int64, msg OutgoingMessage, threadID int) (int, error) {
+	bot, err := s.botProvider.Get(botID)
+	if err != nil {
+		return 0, err
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", msg.ChatID)
+	params.AddNonEmpty("parse_mode", msg.ParseMode)
+	params.AddNonZero("message_thread_id", threadID)
+	switch {
+	case msg.RequestContact != "":
+		keyboard := tgbotapi.NewReplyKeyboard(tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButtonContact(msg.RequestContact),
+		))
+		keyboard.OneTimeKeyboard = true
+		keyboard.ResizeKeyboard = true
+		if err := params.AddInterface("reply_markup", keyboard); err != nil {
+			return 0, err
+		}
+	case len(msg.Buttons) > 0:
+		if err := params.AddInterface("reply_markup", inlineKeyboard(msg.Buttons)); err != nil {
+			return 0, err
+		}
+	}
+
+	var resp *tgbotapi.APIResponse
+	if msg.Media != nil {
+		method, ok := mediaMethods[msg.Media.Kind]
+		if !ok {
+			return 0, fmt.Errorf("unknown media kind %q", msg.Media.Kind)
+		}
+		params.AddNonEmpty("caption", msg.Text)
+		resp, err = bot.UploadFiles(method[0], params, []tgbotapi.RequestFile{{
+			Name: method[1],
+			Data: tgbotapi.FileBytes{Name: msg.Media.FileName, Bytes: msg.Media.Data},
+		}})
+	} else {
+		params["text"] = msg.Text
+		resp, err = bot.MakeRequest("sendMessage", params)
+	}
+	if err != nil {
+		return 0, err
+	}
+	var sent tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		return 0, fmt.Errorf("failed to decode sent message: %w", err)
+	}
+	return sent.MessageID, nil
+}
+
+// Post publishes msg in a channel and returns the Telegram message ID.
+// Channels have no reply keyboards, so contact requests are dropped.
+func (s *Sender) Post(ctx context.Context, botID int64, msg OutgoingMessage) (int, error) {
+	msg.RequestContact = ""
+	return s.Send(ctx, botID, msg)
+}
+
+func inlineKeyboard(buttons []Button) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(buttons))
+	for _, b := range buttons {
+		var button tgbotapi.InlineKeyboardButton
+		if b.URL != "" {
+			button = tgbotapi.NewInlineKeyboardButtonURL(b.Text, b.URL)
+		} else {
+			button = tgbotapi.NewInlineKeyboardButtonData(b.Text, b.Data)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -40,3 +40,11 @@ func (r *TelegramBotRegistry) Get(botID int64) (*tgbotapi.BotAPI, error){
 	}
 	return bot, nil
 }
+
+// Remove forgets the bot, so nothing is sent through it anymore. It is a
+// no-op for unknown bots.
+func (r *TelegramBotRegistry) Remove(botID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.bots, botID)
+}
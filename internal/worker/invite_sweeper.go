@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/config"
+	"github.com/VladKovDev/promo-bot/internal/domain/group"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const inviteSweepBatchSize = 100
+
+// InviteSweeper revokes private group invite links that expired unused and
+// reviews join requests waiting for their users to qualify.
+type InviteSweeper struct {
+	service *group.Service
+	cfg     config.GroupsConfig
+	logger  logger.Logger
+}
+
+func NewInviteSweeper(service *group.Service, cfg config.GroupsConfig, logger logger.Logger) *InviteSweeper {
+	return &InviteSweeper{
+		service: service,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+// Run sweeps invites and join requests until ctx is cancelled.
+func (s *InviteSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *InviteSweeper) tick(ctx context.Context) {
+	s.revokeExpired(ctx)
+
+	if err := s.service.ReviewJoinRequests(ctx, time.Now().UTC(), inviteSweepBatchSize); err != nil && ctx.Err() == nil {
+		s.logger.Error("failed to review group join requests", zap.Error(err))
+	}
+}
+
+func (s *InviteSweeper) revokeExpired(ctx context.Context) {
+	for {
+		n, err := s.service.RevokeExpired(ctx, time.Now().UTC(), inviteSweepBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("failed to revoke expired group invites", zap.Error(err))
+			}
+			return
+		}
+		if n < inviteSweepBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/config"
+	"github.com/VladKovDev/promo-bot/internal/domain/alert"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/internal/domain/telegram_bot"
+	"github.com/VladKovDev/promo-bot/internal/infrastructure/telegram"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BotChecker connects to bots and stops those that cannot run.
+type BotChecker interface {
+	CheckBot(ctx context.Context, bot *telegram_bot.TelegramBot) error
+	StopBot(bot *telegram_bot.TelegramBot)
+}
+
+// Monitor checks active bots for revoked tokens, failing deliveries and
+// overdue scheduled steps and alerts their owners.
+type Monitor struct {
+	bots       *telegram_bot.Service
+	checker    BotChecker
+	scripts    *script.Service
+	deliveries *alert.Deliveries
+	alerts     *alert.Service
+	cfg        config.AlertsConfig
+	logger     logger.Logger
+}
+
+func NewMonitor(bots *telegram_bot.Service, checker BotChecker, scripts *script.Service, deliveries *alert.Deliveries, alerts *alert.Service, cfg config.AlertsConfig, logger logger.Logger) *Monitor {
+	return &Monitor{
+		bots:       bots,
+		checker:    checker,
+		scripts:    scripts,
+		deliveries: deliveries,
+		alerts:     alerts,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Run checks bots until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// bots were just started, so the first check waits for a tick
+		m.tick(ctx)
+	}
+}
+
+func (m *Monitor) tick(ctx context.Context) {
+	bots, err := m.bots.ListAll(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			m.logger.Error("failed to list telegram bots", zap.Error(err))
+		}
+		return
+	}
+
+	now := time.Now()
+	active := make(map[uuid.UUID]*telegram_bot.TelegramBot, len(bots))
+	for _, bot := range bots {
+		if !bot.IsActive() {
+			continue
+		}
+		if m.checkToken(ctx, bot) {
+			m.checkDeliveries(ctx, bot, now)
+			active[bot.ID] = bot
+		}
+	}
+	m.checkSchedule(ctx, active, now)
+}
+
+// checkToken reports whether the bot's token is still accepted. A revoked
+// bot is stopped and its owners are alerted.
+func (m *Monitor) checkToken(ctx context.Context, bot *telegram_bot.TelegramBot) bool {
+	err := m.checker.CheckBot(ctx, bot)
+	if err == nil {
+		return true
+	}
+	if !telegram.IsUnauthorized(err) {
+		// other errors are recorded on the bot and shown by /bots
+		m.logger.Warn("telegram bot check failed", zap.String("bot_id", bot.ID.String()), zap.Error(err))
+		return true
+	}
+
+	if err := m.bots.MarkRevoked(ctx, bot); err != nil {
+		m.logger.Error("failed to mark telegram bot revoked", zap.String("bot_id", bot.ID.String()), zap.Error(err))
+		return false
+	}
+	m.checker.StopBot(bot)
+	m.notify(ctx, alert.Alert{
+		BotID: bot.ID,
+		Kind:  alert.KindTokenRevoked,
+		Text:  fmt.Sprintf("Telegram rejects the token of @%s, it was probably revoked. The bot is stopped.", bot.Username),
+	})
+	return false
+}
+
+func (m *Monitor) checkDeliveries(ctx context.Context, bot *telegram_bot.TelegramBot, now time.Time) {
+	attempted, failed := m.deliveries.Count(bot.BotID, now)
+	if attempted < m.cfg.MinDeliveries || float64(failed) < m.cfg.FailureRate*float64(attempted) {
+		return
+	}
+	m.notify(ctx, alert.Alert{
+		BotID: bot.ID,
+		Kind:  alert.KindDeliveryFailures,
+		Text:  fmt.Sprintf("%d of %d messages sent by @%s in the last %s failed.", failed, attempted, bot.Username, m.cfg.FailureWindow),
+	})
+}
+
+func (m *Monitor) checkSchedule(ctx context.Context, bots map[uuid.UUID]*telegram_bot.TelegramBot, now time.Time) {
+	overdue, err := m.scripts.OverdueSteps(ctx, now.Add(-m.cfg.SchedulerLag))
+	if err != nil {
+		if ctx.Err() == nil {
+			m.logger.Error("failed to list overdue scheduled steps", zap.Error(err))
+		}
+		return
+	}
+	for _, o := range overdue {
+		bot, ok := bots[o.TelegramBotID]
+		if !ok {
+			continue
+		}
+		m.notify(ctx, alert.Alert{
+			BotID: bot.ID,
+			Kind:  alert.KindSchedulerLag,
+			Text: fmt.Sprintf("%d scheduled steps of @%s are more than %s late, the oldest was due at %s UTC.",
+				o.Steps, bot.Username, m.cfg.SchedulerLag, o.OldestDueAt.Format(time.DateTime)),
+		})
+	}
+}
+
+func (m *Monitor) notify(ctx context.Context, a alert.Alert) {
+	if err := m.alerts.Notify(ctx, a); err != nil && ctx.Err() == nil {
+		m.logger.Error("failed to send alert",
+			zap.String("bot_id", a.BotID.String()),
+			zap.String("kind", a.Kind),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/VladKovDev/promo-bot/internal/config"
+	"github.com/VladKovDev/promo-bot/internal/domain/script"
+	"github.com/VladKovDev/promo-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Scheduler polls due scheduled steps and executes them.
+type Scheduler struct {
+	repo    script.ScheduleRepository
+	service *script.Service
+	cfg     config.SchedulerConfig
+	logger  logger.Logger
+}
+
+func NewScheduler(repo script.ScheduleRepository, service *script.Service, cfg config.SchedulerConfig, logger logger.Logger) *Scheduler {
+	return &Scheduler{
+		repo:    repo,
+		service: service,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+// Run processes due steps until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context) {
+	for {
+		now := time.Now().UTC()
+		steps, err := s.repo.ClaimDue(ctx, now, now.Add(-s.cfg.ProcessingTimeout), s.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("failed to claim scheduled steps", zap.Error(err))
+			}
+			return
+		}
+
+		for _, st := range steps {
+			s.process(ctx, st)
+		}
+
+		// a full batch means more steps may be due
+		if len(steps) < s.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) process(ctx context.Context, st *script.ScheduledStep) {
+	err := s.service.Execute(ctx, st)
+	if err == nil {
+		return
+	}
+
+	log := s.logger.With(
+		zap.String("scheduled_step_id", st.ID.String()),
+		zap.Int("attempts", st.Attempts),
+		zap.Error(err))
+
+	if st.Attempts >= s.cfg.MaxAttempts {
+		log.Error("scheduled step failed, giving up")
+		if err := s.service.Fail(ctx, st, err); err != nil {
+			log.Error("failed to mark scheduled step failed", zap.NamedError("mark_error", err))
+		}
+		return
+	}
+
+	retryAt := time.Now().Add(s.cfg.RetryDelay * time.Duration(st.Attempts))
+	log.Warn("scheduled step failed, retrying", zap.Time("retry_at", retryAt))
+	if err := s.service.Retry(ctx, st, retryAt, err); err != nil {
+		log.Error("failed to reschedule scheduled step", zap.NamedError("reschedule_error", err))
+	}
+}
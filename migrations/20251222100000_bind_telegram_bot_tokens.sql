@@ -0,0 +1,9 @@
+-- +goose Up
+-- Токен привязан к строке через associated data (id строки + версия ключа).
+-- Старые строки зашифрованы без associated data, пока не будут перешифрованы.
+ALTER TABLE telegram_bots
+ADD COLUMN token_bound BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE telegram_bots
+DROP COLUMN IF EXISTS token_bound;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Конвертное шифрование: токен шифруется собственным ключом данных,
+-- который хранится обёрнутым мастер-ключом провайдера (kek_id).
+ALTER TABLE telegram_bots
+ADD COLUMN wrapped_data_key BYTEA,
+ADD COLUMN kek_id TEXT;
+
+-- +goose Down
+ALTER TABLE telegram_bots
+DROP COLUMN IF EXISTS kek_id,
+DROP COLUMN IF EXISTS wrapped_data_key;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Алгоритм, которым зашифрован токен, хранится рядом с шифртекстом,
+-- чтобы смена crypto_algorithm не ломала расшифровку старых строк.
+ALTER TABLE telegram_bots
+ADD COLUMN encryption_algorithm TEXT NOT NULL DEFAULT 'aes_gcm';
+
+-- +goose Down
+ALTER TABLE telegram_bots
+DROP COLUMN IF EXISTS encryption_algorithm;
@@ -0,0 +1,47 @@
+-- +goose Up
+-- Часовой пояс пользователя: выводится из language_code или задаётся им явно
+ALTER TABLE users
+ADD COLUMN language_code TEXT,
+ADD COLUMN timezone TEXT,
+ADD COLUMN timezone_source TEXT CHECK (timezone_source IN ('inferred', 'explicit'));
+
+-- Часовой пояс бота используется, когда часовой пояс пользователя неизвестен
+ALTER TABLE telegram_bots
+ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+
+-- Тихие часы бота в минутах от локальной полуночи пользователя.
+-- Окно с start_minute > end_minute переходит через полночь.
+CREATE TABLE telegram_bot_quiet_hours (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    telegram_bot_id UUID NOT NULL REFERENCES telegram_bots(id) ON DELETE CASCADE,
+    start_minute INT NOT NULL CHECK (start_minute BETWEEN 0 AND 1439),
+    end_minute INT NOT NULL CHECK (end_minute BETWEEN 0 AND 1439),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (start_minute <> end_minute)
+);
+
+CREATE INDEX telegram_bot_quiet_hours_bot_idx ON telegram_bot_quiet_hours (telegram_bot_id);
+
+-- Шаг, который нужно выполнить
+ALTER TABLE scheduled_steps
+ADD COLUMN step_id UUID REFERENCES script_steps(id) ON DELETE CASCADE;
+
+CREATE INDEX scheduled_steps_due_idx ON scheduled_steps (execute_at)
+WHERE
+    "status" = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS scheduled_steps_due_idx;
+
+ALTER TABLE scheduled_steps
+DROP COLUMN IF EXISTS step_id;
+
+DROP TABLE IF EXISTS telegram_bot_quiet_hours;
+
+ALTER TABLE telegram_bots
+DROP COLUMN IF EXISTS timezone;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone_source,
+DROP COLUMN IF EXISTS timezone,
+DROP COLUMN IF EXISTS language_code;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Шаг может ждать действия пользователя вместо таймера
+ALTER TABLE script_steps
+ADD COLUMN wait_for TEXT CHECK (wait_for IN ('button', 'text', 'keyword', 'contact')),
+ADD COLUMN wait_keywords TEXT[],
+ADD COLUMN wait_timeout INT,
+ADD COLUMN fallback_step_id UUID REFERENCES script_steps(id) ON DELETE SET NULL;
+
+-- Условие, которого сейчас ждёт пользователь
+ALTER TABLE script_progress
+ADD COLUMN waiting_for TEXT;
+
+-- deliver - отправка шага, timeout - истечение ожидания на шаге
+ALTER TABLE scheduled_steps
+ADD COLUMN kind TEXT NOT NULL DEFAULT 'deliver' CHECK (kind IN ('deliver', 'timeout'));
+
+-- Ответы пользователя, которые выполнили условие ожидания
+CREATE TABLE script_progress_inputs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    script_progress_id UUID NOT NULL REFERENCES script_progress(id) ON DELETE CASCADE,
+    step_id UUID REFERENCES script_steps(id) ON DELETE SET NULL,
+    "type" TEXT NOT NULL,
+    "value" TEXT,
+    button_id UUID REFERENCES message_buttons(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX script_progress_inputs_progress_idx ON script_progress_inputs (script_progress_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS script_progress_inputs;
+
+ALTER TABLE scheduled_steps
+DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE script_progress
+DROP COLUMN IF EXISTS waiting_for;
+
+ALTER TABLE script_steps
+DROP COLUMN IF EXISTS fallback_step_id,
+DROP COLUMN IF EXISTS wait_timeout,
+DROP COLUMN IF EXISTS wait_keywords,
+DROP COLUMN IF EXISTS wait_for;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Переходы между шагами скрипта. Шаг без переходов продолжается следующим
+-- по "order"; из шага с переходами выбирается первый подходящий по priority.
+CREATE TABLE script_transitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    script_id UUID NOT NULL REFERENCES scripts(id) ON DELETE CASCADE,
+    from_step_id UUID NOT NULL REFERENCES script_steps(id) ON DELETE CASCADE,
+    to_step_id UUID NOT NULL REFERENCES script_steps(id) ON DELETE CASCADE,
+    "condition" TEXT NOT NULL CHECK (
+        "condition" IN (
+            'always',
+            'answer',
+            'button',
+            'delivered',
+            'not_delivered'
+        )
+    ),
+    "value" TEXT,
+    priority INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX script_transitions_script_idx ON script_transitions (script_id);
+
+-- Путь пользователя по скрипту: шаги в порядке прохождения
+CREATE TABLE script_progress_steps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    script_progress_id UUID NOT NULL REFERENCES script_progress(id) ON DELETE CASCADE,
+    step_id UUID REFERENCES script_steps(id) ON DELETE SET NULL,
+    transition_id UUID REFERENCES script_transitions(id) ON DELETE SET NULL,
+    entered_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX script_progress_steps_progress_idx ON script_progress_steps (script_progress_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS script_progress_steps;
+
+DROP TABLE IF EXISTS script_transitions;
@@ -0,0 +1,78 @@
+-- +goose Up
+-- Произвольные атрибуты пользователя в рамках бота, например interested_in=course_b
+CREATE TABLE user_attributes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    telegram_bot_id UUID NOT NULL REFERENCES telegram_bots(id) ON DELETE CASCADE,
+    "key" TEXT NOT NULL,
+    "value" TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, telegram_bot_id, "key")
+);
+
+CREATE INDEX user_attributes_bot_key_idx ON user_attributes (telegram_bot_id, "key", "value");
+
+-- Теги пользователя в рамках бота
+CREATE TABLE user_tags (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    telegram_bot_id UUID NOT NULL REFERENCES telegram_bots(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, telegram_bot_id, tag)
+);
+
+CREATE INDEX user_tags_bot_tag_idx ON user_tags (telegram_bot_id, tag);
+
+-- Ответ пользователя на шаге сохраняется в атрибут с этим ключом
+ALTER TABLE script_steps
+ADD COLUMN save_as TEXT;
+
+-- Нажатие кнопки задаёт атрибут и/или добавляет тег
+ALTER TABLE message_buttons
+ADD COLUMN set_attribute TEXT,
+ADD COLUMN set_value TEXT,
+ADD COLUMN add_tag TEXT;
+
+-- Переходы по тегам и атрибутам пользователя
+ALTER TABLE script_transitions
+DROP CONSTRAINT script_transitions_condition_check,
+ADD CONSTRAINT script_transitions_condition_check CHECK (
+    "condition" IN (
+        'always',
+        'answer',
+        'button',
+        'delivered',
+        'not_delivered',
+        'tag',
+        'attribute'
+    )
+);
+
+-- +goose Down
+DELETE FROM
+    script_transitions
+WHERE
+    "condition" IN ('tag', 'attribute');
+
+ALTER TABLE script_transitions
+DROP CONSTRAINT script_transitions_condition_check,
+ADD CONSTRAINT script_transitions_condition_check CHECK (
+    "condition" IN (
+        'always',
+        'answer',
+        'button',
+        'delivered',
+        'not_delivered'
+    )
+);
+
+ALTER TABLE message_buttons
+DROP COLUMN IF EXISTS add_tag,
+DROP COLUMN IF EXISTS set_value,
+DROP COLUMN IF EXISTS set_attribute;
+
+ALTER TABLE script_steps
+DROP COLUMN IF EXISTS save_as;
+
+DROP TABLE IF EXISTS user_tags;
+
+DROP TABLE IF EXISTS user_attributes;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Разметка текста сообщения: HTML, MarkdownV2 или NULL для обычного текста
+ALTER TABLE messages
+ADD COLUMN parse_mode TEXT CHECK (parse_mode IN ('HTML', 'MarkdownV2'));
+
+-- +goose Down
+ALTER TABLE messages
+DROP COLUMN IF EXISTS parse_mode;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Диплинки t.me/<bot>?start=<payload>: какой скрипт запускать и источник трафика
+CREATE TABLE script_deep_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    telegram_bot_id UUID NOT NULL REFERENCES telegram_bots(id) ON DELETE CASCADE,
+    payload TEXT NOT NULL,
+    script_id UUID NOT NULL REFERENCES scripts(id) ON DELETE CASCADE,
+    "source" TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (telegram_bot_id, payload)
+);
+
+-- Источник, из которого пользователь пришёл в скрипт
+ALTER TABLE script_progress
+ADD COLUMN "source" TEXT;
+
+-- +goose Down
+ALTER TABLE script_progress
+DROP COLUMN IF EXISTS "source";
+
+DROP TABLE IF EXISTS script_deep_links;
@@ -0,0 +1,57 @@
+-- +goose Up
+-- Закрытые группы, доступ в которые бот выдаёт по персональным ссылкам.
+-- Бот должен быть администратором группы.
+CREATE TABLE private_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    telegram_bot_id UUID NOT NULL REFERENCES telegram_bots(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    title TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (telegram_bot_id, chat_id)
+);
+
+-- Раньше таблицы групп не было, поэтому старые значения ни на что не ссылаются
+UPDATE
+    scripts
+SET
+    private_group_id = NULL
+WHERE
+    private_group_id IS NOT NULL;
+
+ALTER TABLE scripts
+ADD CONSTRAINT scripts_private_group_id_fkey FOREIGN KEY (private_group_id) REFERENCES private_groups(id) ON DELETE SET NULL;
+
+-- Шаг, на котором пользователь получает ссылку в закрытую группу скрипта
+ALTER TABLE script_steps
+ADD COLUMN grants_group_access BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Одноразовые персональные ссылки-приглашения
+CREATE TABLE group_invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    private_group_id UUID NOT NULL REFERENCES private_groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    script_progress_id UUID REFERENCES script_progress(id) ON DELETE SET NULL,
+    invite_link TEXT NOT NULL,
+    "status" TEXT NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'joined', 'revoked')),
+    expires_at TIMESTAMP NOT NULL,
+    joined_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX group_invites_pending_idx ON group_invites (expires_at)
+WHERE
+    "status" = 'pending';
+
+CREATE UNIQUE INDEX group_invites_link_idx ON group_invites (invite_link);
+
+-- +goose Down
+DROP TABLE IF EXISTS group_invites;
+
+ALTER TABLE script_steps
+DROP COLUMN IF EXISTS grants_group_access;
+
+ALTER TABLE scripts
+DROP CONSTRAINT IF EXISTS scripts_private_group_id_fkey;
+
+DROP TABLE IF EXISTS private_groups;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Кого пускать по заявкам на вступление: пользователей, дошедших до шага
+-- с доступом в группу, или с тегом access_tag (например, paid).
+-- Остальные заявки отклоняются или ждут, пока пользователь не выполнит условие.
+ALTER TABLE private_groups
+ADD COLUMN access_tag TEXT,
+ADD COLUMN decline_unqualified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE group_join_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    private_group_id UUID NOT NULL REFERENCES private_groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "status" TEXT NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'approved', 'declined')),
+    requested_at TIMESTAMP NOT NULL,
+    decided_at TIMESTAMP,
+    UNIQUE (private_group_id, user_id)
+);
+
+CREATE INDEX group_join_requests_pending_idx ON group_join_requests (requested_at)
+WHERE
+    "status" = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS group_join_requests;
+
+ALTER TABLE private_groups
+DROP COLUMN IF EXISTS decline_unqualified,
+DROP COLUMN IF EXISTS access_tag;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- Шаг может ждать подписки пользователя на канал wait_channel_id.
+-- Бот должен быть администратором канала, чтобы проверять подписчиков.
+ALTER TABLE script_steps
+ADD COLUMN wait_channel_id BIGINT;
+
+ALTER TABLE script_steps
+DROP CONSTRAINT IF EXISTS script_steps_wait_for_check;
+
+ALTER TABLE script_steps
+ADD CONSTRAINT script_steps_wait_for_check CHECK (
+    wait_for IN ('button', 'text', 'keyword', 'contact', 'subscription')
+);
+
+ALTER TABLE script_steps
+ADD CONSTRAINT script_steps_wait_channel_check CHECK (
+    wait_for IS DISTINCT FROM 'subscription'
+    OR wait_channel_id IS NOT NULL
+);
+
+-- +goose Down
+ALTER TABLE script_steps
+DROP CONSTRAINT IF EXISTS script_steps_wait_channel_check;
+
+ALTER TABLE script_steps
+DROP CONSTRAINT IF EXISTS script_steps_wait_for_check;
+
+UPDATE
+    script_steps
+SET
+    wait_for = NULL
+WHERE
+    wait_for = 'subscription';
+
+ALTER TABLE script_steps
+ADD CONSTRAINT script_steps_wait_for_check CHECK (
+    wait_for IN ('button', 'text', 'keyword', 'contact')
+);
+
+ALTER TABLE script_steps
+DROP COLUMN IF EXISTS wait_channel_id;
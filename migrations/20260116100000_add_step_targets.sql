@@ -0,0 +1,40 @@
+-- +goose Up
+-- Раньше канал шага нигде не читался и все шаги уходили в личный чат
+UPDATE
+    script_steps
+SET
+    channel = 'private'
+WHERE
+    channel NOT IN ('private', 'group', 'channel');
+
+ALTER TABLE script_steps
+ALTER COLUMN channel
+SET DEFAULT 'private';
+
+-- private - личный чат пользователя с ботом, group - группа или тема форума,
+-- channel - пост в канале. Для group и channel нужен чат назначения.
+ALTER TABLE script_steps
+ADD COLUMN target_chat_id BIGINT,
+ADD COLUMN target_thread_id INT;
+
+ALTER TABLE script_steps
+ADD CONSTRAINT script_steps_channel_check CHECK (channel IN ('private', 'group', 'channel')),
+ADD CONSTRAINT script_steps_target_check CHECK ((channel = 'private') = (target_chat_id IS NULL)),
+ADD CONSTRAINT script_steps_thread_check CHECK (
+    target_thread_id IS NULL
+    OR channel = 'group'
+);
+
+-- +goose Down
+ALTER TABLE script_steps
+DROP CONSTRAINT IF EXISTS script_steps_thread_check,
+DROP CONSTRAINT IF EXISTS script_steps_target_check,
+DROP CONSTRAINT IF EXISTS script_steps_channel_check;
+
+ALTER TABLE script_steps
+DROP COLUMN IF EXISTS target_thread_id,
+DROP COLUMN IF EXISTS target_chat_id;
+
+ALTER TABLE script_steps
+ALTER COLUMN channel
+DROP DEFAULT;
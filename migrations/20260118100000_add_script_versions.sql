@@ -0,0 +1,115 @@
+-- +goose Up
+-- Версии скрипта. Черновик (published_at IS NULL) можно менять, опубликованные
+-- версии неизменяемы. Пользователь проходит ту версию, на которой начал.
+CREATE TABLE script_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    script_id UUID NOT NULL REFERENCES scripts(id) ON DELETE CASCADE,
+    "number" INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP,
+    UNIQUE (script_id, "number")
+);
+
+-- У скрипта не больше одного черновика
+CREATE UNIQUE INDEX script_versions_draft_idx ON script_versions (script_id)
+WHERE
+    published_at IS NULL;
+
+-- Версия, которую получают новые пользователи
+ALTER TABLE scripts
+ADD COLUMN published_version_id UUID REFERENCES script_versions(id) ON DELETE SET NULL;
+
+ALTER TABLE script_steps
+ADD COLUMN script_version_id UUID REFERENCES script_versions(id) ON DELETE CASCADE;
+
+ALTER TABLE script_transitions
+ADD COLUMN script_version_id UUID REFERENCES script_versions(id) ON DELETE CASCADE;
+
+ALTER TABLE script_progress
+ADD COLUMN script_version_id UUID REFERENCES script_versions(id) ON DELETE SET NULL;
+
+-- Текущее содержимое скриптов становится опубликованной версией 1
+INSERT INTO
+    script_versions (script_id, "number", published_at)
+SELECT
+    id,
+    1,
+    NOW()
+FROM
+    scripts;
+
+UPDATE
+    scripts s
+SET
+    published_version_id = v.id
+FROM
+    script_versions v
+WHERE
+    v.script_id = s.id;
+
+UPDATE
+    script_steps st
+SET
+    script_version_id = s.published_version_id
+FROM
+    scripts s
+WHERE
+    s.id = st.script_id;
+
+UPDATE
+    script_transitions t
+SET
+    script_version_id = s.published_version_id
+FROM
+    scripts s
+WHERE
+    s.id = t.script_id;
+
+UPDATE
+    script_progress p
+SET
+    script_version_id = s.published_version_id
+FROM
+    scripts s
+WHERE
+    s.id = p.script_id;
+
+ALTER TABLE script_steps
+ALTER COLUMN script_version_id
+SET NOT NULL;
+
+ALTER TABLE script_transitions
+ALTER COLUMN script_version_id
+SET NOT NULL;
+
+CREATE INDEX script_steps_version_idx ON script_steps (script_version_id);
+
+CREATE INDEX script_transitions_version_idx ON script_transitions (script_version_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS script_transitions_version_idx;
+
+DROP INDEX IF EXISTS script_steps_version_idx;
+
+ALTER TABLE script_progress
+DROP COLUMN IF EXISTS script_version_id;
+
+-- Без версий у скрипта остаются только шаги опубликованной версии
+DELETE FROM
+    script_steps st
+USING
+    scripts s
+WHERE
+    s.id = st.script_id
+    AND st.script_version_id IS DISTINCT FROM s.published_version_id;
+
+ALTER TABLE script_transitions
+DROP COLUMN IF EXISTS script_version_id;
+
+ALTER TABLE script_steps
+DROP COLUMN IF EXISTS script_version_id;
+
+ALTER TABLE scripts
+DROP COLUMN IF EXISTS published_version_id;
+
+DROP TABLE IF EXISTS script_versions;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Имя скрипта уникально в пределах бота, чтобы один и тот же сценарий можно
+-- было импортировать в разные боты
+ALTER TABLE scripts
+DROP CONSTRAINT IF EXISTS scripts_name_key;
+
+CREATE UNIQUE INDEX scripts_bot_name_idx ON scripts (telegram_bot_id, "name")
+WHERE
+    deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS scripts_bot_name_idx;
+
+ALTER TABLE scripts
+ADD CONSTRAINT scripts_name_key UNIQUE ("name");
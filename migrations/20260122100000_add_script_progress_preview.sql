@@ -0,0 +1,16 @@
+-- +goose Up
+-- Предпросмотр сценария: прогон со сжатыми задержками, который не учитывается
+-- в аналитике и не мешает настоящему прохождению
+ALTER TABLE script_progress
+ADD COLUMN preview BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX script_progress_preview_idx ON script_progress (user_id, script_id)
+WHERE
+    preview = TRUE
+    AND "status" IN ('active', 'waiting');
+
+-- +goose Down
+DROP INDEX IF EXISTS script_progress_preview_idx;
+
+ALTER TABLE script_progress
+DROP COLUMN IF EXISTS preview;
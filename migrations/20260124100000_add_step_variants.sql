@@ -0,0 +1,40 @@
+-- +goose Up
+-- Варианты сообщения шага для A/B тестов. Если у шага есть варианты,
+-- пользователь получает один из них, выбранный по весу
+CREATE TABLE script_step_variants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    step_id UUID NOT NULL REFERENCES script_steps(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE RESTRICT,
+    "name" TEXT NOT NULL,
+    weight INT NOT NULL CHECK (weight > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (step_id, "name")
+);
+
+-- Вариант, который получил пользователь
+ALTER TABLE script_progress_delivery
+ADD COLUMN variant_id UUID REFERENCES script_step_variants(id) ON DELETE SET NULL;
+
+CREATE INDEX script_progress_delivery_variant_idx ON script_progress_delivery (variant_id)
+WHERE
+    variant_id IS NOT NULL;
+
+-- Нажатия на кнопки сообщений
+CREATE TABLE message_button_clicks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    button_id UUID NOT NULL REFERENCES message_buttons(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    clicked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX message_button_clicks_button_idx ON message_button_clicks (button_id, user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS message_button_clicks;
+
+DROP INDEX IF EXISTS script_progress_delivery_variant_idx;
+
+ALTER TABLE script_progress_delivery
+DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS script_step_variants;
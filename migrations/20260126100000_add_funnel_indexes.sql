@@ -0,0 +1,12 @@
+-- +goose Up
+-- Индексы для воронок по шагам сценария
+CREATE INDEX script_progress_version_started_idx ON script_progress (script_version_id, started_at)
+WHERE
+    NOT preview;
+
+CREATE INDEX script_progress_delivery_progress_step_idx ON script_progress_delivery (script_progress_id, step_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS script_progress_delivery_progress_step_idx;
+
+DROP INDEX IF EXISTS script_progress_version_started_idx;
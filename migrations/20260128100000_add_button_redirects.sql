@@ -0,0 +1,25 @@
+-- +goose Up
+-- Ссылка кнопки заменяется подписанной ссылкой на редирект, который
+-- записывает нажатие
+ALTER TABLE message_buttons
+ADD COLUMN track_clicks BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Шаг и доставка, из которых нажата кнопка, известны для переходов по
+-- редиректу
+ALTER TABLE message_button_clicks
+ADD COLUMN step_id UUID REFERENCES script_steps(id) ON DELETE SET NULL,
+ADD COLUMN delivery_id UUID REFERENCES script_progress_delivery(id) ON DELETE SET NULL;
+
+CREATE INDEX message_button_clicks_delivery_idx ON message_button_clicks (delivery_id)
+WHERE
+    delivery_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS message_button_clicks_delivery_idx;
+
+ALTER TABLE message_button_clicks
+DROP COLUMN IF EXISTS delivery_id,
+DROP COLUMN IF EXISTS step_id;
+
+ALTER TABLE message_buttons
+DROP COLUMN IF EXISTS track_clicks;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Оповещения владельцам ботов, отправленные через админ-бота. По журналу
+-- повторы одного оповещения подавляются и число оповещений ограничивается
+CREATE TABLE telegram_bot_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    telegram_bot_id UUID NOT NULL REFERENCES telegram_bots(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    "text" TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX telegram_bot_alerts_bot_idx ON telegram_bot_alerts (telegram_bot_id, created_at);
+
+-- Владельцы, отключившие оповещения о боте
+CREATE TABLE telegram_bot_alert_mutes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    telegram_bot_id UUID NOT NULL REFERENCES telegram_bots(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, telegram_bot_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS telegram_bot_alert_mutes;
+
+DROP TABLE IF EXISTS telegram_bot_alerts;
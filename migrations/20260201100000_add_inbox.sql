@@ -0,0 +1,48 @@
+-- +goose Up
+-- Группа поддержки, в которую бот пересылает сообщения пользователей. Если
+-- она не задана, сообщения получают владельцы и админы бота в админ-боте
+ALTER TABLE telegram_bots
+ADD COLUMN support_chat_id BIGINT;
+
+-- Переписка пользователя с админами бота
+CREATE TABLE inbox_threads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    telegram_bot_id UUID NOT NULL REFERENCES telegram_bots(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (telegram_bot_id, user_id)
+);
+
+-- Сообщения переписки: входящие от пользователя и ответы админов
+CREATE TABLE inbox_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    thread_id UUID NOT NULL REFERENCES inbox_threads(id) ON DELETE CASCADE,
+    direction TEXT NOT NULL CHECK (direction IN ('in', 'out')),
+    "text" TEXT NOT NULL,
+    admin_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX inbox_messages_thread_idx ON inbox_messages (thread_id, created_at);
+
+-- Пересланные админам копии сообщений. По ответу на копию находится
+-- переписка, в которую уходит ответ
+CREATE TABLE inbox_relays (
+    relay_bot_id BIGINT NOT NULL,
+    chat_id BIGINT NOT NULL,
+    message_id BIGINT NOT NULL,
+    thread_id UUID NOT NULL REFERENCES inbox_threads(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (relay_bot_id, chat_id, message_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS inbox_relays;
+
+DROP TABLE IF EXISTS inbox_messages;
+
+DROP TABLE IF EXISTS inbox_threads;
+
+ALTER TABLE telegram_bots
+DROP COLUMN IF EXISTS support_chat_id;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Associated data токена включает алгоритм ключа. Строки, зашифрованные
+-- раньше, остаются со старой associated data, пока не будут перешифрованы.
+ALTER TABLE telegram_bots
+ADD COLUMN token_algorithm_bound BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE telegram_bots
+DROP COLUMN IF EXISTS token_algorithm_bound;
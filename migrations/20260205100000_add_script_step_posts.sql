@@ -0,0 +1,11 @@
+-- +goose Up
+-- Шаги в группу или канал публикуются один раз на шаг версии, а не для
+-- каждого пользователя, дошедшего до шага
+CREATE TABLE script_step_posts (
+    step_id UUID PRIMARY KEY REFERENCES script_steps(id) ON DELETE CASCADE,
+    telegram_message_id TEXT NOT NULL DEFAULT '',
+    posted_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS script_step_posts;
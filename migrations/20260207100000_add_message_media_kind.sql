@@ -0,0 +1,9 @@
+-- +goose Up
+-- Тип медиа определяет метод отправки (sendPhoto, sendVideo, ...). Файлы,
+-- загруженные раньше, отправляются документами.
+ALTER TABLE message_media
+ADD COLUMN kind TEXT NOT NULL DEFAULT 'document';
+
+-- +goose Down
+ALTER TABLE message_media
+DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,7 @@
+package app_errors
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("not found")
+)